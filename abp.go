@@ -0,0 +1,86 @@
+package sutrie
+
+import (
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ABPFilter is the domain-anchored subset of an Adblock Plus filter list, as used by EasyList and the like:
+// blocking rules "||example.com^" matching a domain and its subdomains, and exceptions "@@||example.com^".
+// As in Adblock Plus, an exception matching a host overrides any blocking rule.
+type ABPFilter struct {
+	// Block and Allow are the domains of the blocking rules and of the exceptions, built WithReversedKeys
+	Block, Allow *SuccinctTrie
+
+	// Skipped is the number of rules outside the supported subset, like rules with options "$third-party",
+	// wildcards, paths or element hiding, which are ignored rather than approximated
+	Skipped int
+}
+
+// ParseABP compiles the domain-anchored rules of an Adblock Plus filter list into an ABPFilter, the tries
+// being built with opts. Comments "!", the header "[Adblock Plus 2.0]" and blank lines are skipped.
+func ParseABP(r io.Reader, opts ...Option) (*ABPFilter, error) {
+	var block, allow []string
+	skipped := 0
+	err := scanLines(r, func(n int, line string) error {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '!' || line[0] == '[' {
+			return nil
+		}
+
+		exception := strings.HasPrefix(line, "@@")
+		domain, ok := abpDomain(strings.TrimPrefix(line, "@@"))
+		switch {
+		case !ok:
+			skipped++
+		case exception:
+			allow = append(allow, domain)
+		default:
+			block = append(block, domain)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ABPFilter{Skipped: skipped}
+	if f.Block, err = buildDomains(block, opts); err != nil {
+		return nil, err
+	}
+	if f.Allow, err = buildDomains(allow, opts); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// abpDomain returns the domain of a rule "||domain^", or "||domain^|", ok is false for any other rule.
+func abpDomain(rule string) (domain string, ok bool) {
+	rule, ok = strings.CutPrefix(rule, "||")
+	if !ok {
+		return "", false
+	}
+	rule, ok = strings.CutSuffix(strings.TrimSuffix(rule, "|"), "^")
+	if !ok || strings.ContainsAny(rule, "*^|$/#@") {
+		return "", false
+	}
+	return cleanDomain(rule)
+}
+
+// MatchHost reports whether host, in lower case, is blocked, that is it is a domain of a blocking rule
+// or a subdomain of one, and no exception matches it.
+func (f *ABPFilter) MatchHost(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	return f.Block.MatchDomainSuffix(host) && !f.Allow.MatchDomainSuffix(host)
+}
+
+// MatchURL reports whether the host of the absolute URL u is blocked, see MatchHost.
+// It is false if u cannot be parsed or has no host.
+func (f *ABPFilter) MatchURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return f.MatchHost(strings.ToLower(parsed.Hostname()))
+}
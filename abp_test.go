@@ -0,0 +1,53 @@
+package sutrie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestABPFilter(t *testing.T) {
+	list := `[Adblock Plus 2.0]
+! Title: test list
+||ads.example.com^
+||Tracker.Example.NET^|
+||example.org^
+@@||good.example.org^
+||10.0.0.1^
+
+||third.example.com^$third-party
+||ad*.example.com^
+/banner/*
+example.com##.ad
+@@||example.com/path^
+`
+	f, err := ParseABP(strings.NewReader(list))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, f.Block.Size())
+	assert.Equal(t, 1, f.Allow.Size())
+	assert.Equal(t, 5, f.Skipped)
+
+	for u, blocked := range map[string]bool{
+		"https://ads.example.com/x.js":         true,
+		"http://cdn.ads.example.com:8080/":     true,
+		"https://ADS.EXAMPLE.COM./":            true,
+		"https://tracker.example.net/pixel":    true,
+		"https://www.example.org/":             true,
+		"https://good.example.org/":            false,
+		"https://a.good.example.org/":          false,
+		"http://10.0.0.1/":                     true,
+		"https://badads.example.com/":          false,
+		"https://example.com/":                 false,
+		"https://third.example.com/":           false,
+		"ads.example.com/no-scheme":            false,
+		"://ads.example.com":                   false,
+		"https://user@ads.example.com/?q=a.b/": true,
+	} {
+		assert.Equal(t, blocked, f.MatchURL(u), u)
+	}
+
+	empty, err := ParseABP(strings.NewReader("! nothing\n"))
+	assert.NoError(t, err)
+	assert.False(t, empty.MatchURL("https://example.com/"))
+}
@@ -0,0 +1,136 @@
+package sutrie
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// WithAlphabetRemap maps the bytes the labels use, like the 38 of domain names, to consecutive codes.
+// Dense nodes then have a bitmap of the codes rather than of the 256 bytes, so more levels are dense
+// for the same space, and the labels are serialized bit-packed, 6 bits each for domain names.
+// It has no effect on a trie whose labels use all 256 bytes.
+func WithAlphabetRemap() Option {
+	return func(o *buildOptions) error {
+		o.remap = true
+		return nil
+	}
+}
+
+var errInvalidLabels = errors.New("sutrie: invalid packed labels")
+
+// alphabet returns the sorted distinct bytes of the labels, the dummy label of the root left out,
+// nil if there are none or all 256 are used.
+func alphabet(nodes string) []byte {
+	var seen [256]bool
+	for i := 1; i < len(nodes); i++ {
+		seen[nodes[i]] = true
+	}
+
+	var alphabet []byte
+	for b, ok := range seen {
+		if ok {
+			alphabet = append(alphabet, byte(b))
+		}
+	}
+	if len(alphabet) == 256 {
+		return nil
+	}
+	return alphabet
+}
+
+// setAlphabet sets the alphabet of the labels and the codes of its bytes, see WithAlphabetRemap.
+func (t *SuccinctTrie) setAlphabet(alphabet []byte) {
+	t.alphabet, t.codes = alphabet, nil
+	if len(alphabet) == 0 {
+		t.alphabet = nil
+		return
+	}
+
+	t.codes = new([256]uint8)
+	for c, b := range alphabet {
+		t.codes[b] = uint8(c + 1)
+	}
+}
+
+// denseWords returns the number of words of the label bitmap of a dense node.
+func (t *SuccinctTrie) denseWords() int {
+	if t.codes == nil {
+		return 4
+	}
+	return (len(t.alphabet) + 63) >> 6
+}
+
+// denseCode returns the bit of b in the label bitmaps of dense nodes, false if no label is b.
+func (t *SuccinctTrie) denseCode(b byte) (int, bool) {
+	if t.codes == nil {
+		return int(b), true
+	}
+	c := t.codes[b]
+	return int(c) - 1, c > 0
+}
+
+// codeWidth returns the number of bits of a code of alphabet.
+func codeWidth(alphabet []byte) int {
+	return max(bits.Len(uint(len(alphabet)-1)), 1)
+}
+
+// packLabels returns the number of labels as a uvarint followed by their codes, codeWidth bits each,
+// least significant bits first. The dummy label of the root is packed as code 0.
+func packLabels(nodes string, alphabet []byte) []byte {
+	var codes [256]uint8
+	for c, b := range alphabet {
+		codes[b] = uint8(c)
+	}
+
+	width := codeWidth(alphabet)
+	packed := binary.AppendUvarint(nil, uint64(len(nodes)))
+	var acc uint64
+	var n int
+	for i := 0; i < len(nodes); i++ {
+		if i > 0 {
+			acc |= uint64(codes[nodes[i]]) << n
+		}
+		for n += width; n >= 8; n -= 8 {
+			packed = append(packed, byte(acc))
+			acc >>= 8
+		}
+	}
+	if n > 0 {
+		packed = append(packed, byte(acc))
+	}
+	return packed
+}
+
+// unpackLabels decodes the labels packed by packLabels.
+func unpackLabels(packed []byte, alphabet []byte) (string, error) {
+	count, k := binary.Uvarint(packed)
+	if k <= 0 || len(alphabet) == 0 {
+		return "", errInvalidLabels
+	}
+	packed = packed[k:]
+	width := codeWidth(alphabet)
+	if count > uint64(len(packed))*8/uint64(width) || uint64(len(packed)) != (count*uint64(width)+7)/8 {
+		return "", errInvalidLabels
+	}
+
+	nodes := make([]byte, count)
+	var acc uint64
+	var n int
+	for i := range nodes {
+		for ; n < width; n += 8 {
+			acc |= uint64(packed[0]) << n
+			packed = packed[1:]
+		}
+		c := int(acc & (1<<width - 1))
+		acc >>= width
+		n -= width
+		if c >= len(alphabet) {
+			return "", errInvalidLabels
+		}
+		if i > 0 {
+			nodes[i] = alphabet[c]
+		}
+	}
+	return string(nodes), nil
+}
@@ -0,0 +1,92 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlphabetRemap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789-."
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		key := make([]byte, 3+rnd.Intn(10))
+		for j := range key {
+			key[j] = chars[rnd.Intn(len(chars))]
+		}
+		dict = append(dict, string(key))
+	}
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithAlphabetRemap())
+
+	assert.Equal(t, []byte("-.0123456789abcdefghijklmnopqrstuvwxyz"), trie.alphabet)
+	assert.True(t, plain.Equal(trie))
+	assert.Less(t, trie.MemStats().Dense, plain.MemStats().Dense)
+	assert.GreaterOrEqual(t, trie.denseLimit, plain.denseLimit)
+
+	check := func(trie *SuccinctTrie) {
+		for _, key := range dict {
+			assert.True(t, trie.Root().Search(key).Leaf(), key)
+			assert.Equal(t, len(key), trie.Root().SearchPrefix(key+"_"))
+			assert.False(t, trie.Root().Search(key[:1]+"_").Exists())
+			assert.False(t, trie.Root().Search(key[:1]+"A").Exists())
+		}
+		assert.Equal(t, plain.Keys(), trie.Keys())
+	}
+	check(trie)
+
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf, plainBuf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		assert.NoError(t, marshal(plain, &plainBuf))
+		// 6 bits per label instead of 8
+		assert.Less(t, buf.Len(), plainBuf.Len()-len(plain.nodes)/5)
+
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		assert.Equal(t, trie.nodes, loaded.nodes)
+		assert.Equal(t, trie.alphabet, loaded.alphabet)
+		check(&loaded)
+	}
+	check(trie.Clone())
+
+	// a folded trie remaps its folded labels
+	folded := BuildSuccinctTrie([]string{"Hat", "IS", "it"}, WithCaseFolding(), WithAlphabetRemap())
+	assert.Equal(t, []byte("ahist"), folded.alphabet)
+	assert.True(t, folded.Root().Search("HAT").Leaf())
+	assert.True(t, folded.Root().Search("iT").Leaf())
+
+	assert.Nil(t, BuildSuccinctTrie(nil, WithAlphabetRemap()).alphabet)
+	assert.Nil(t, plain.alphabet)
+}
+
+func TestPackLabels(t *testing.T) {
+	for _, alphabet := range []string{"a", "ab", "abc", "0123456789", "abcdefghijklmnopqrstuvwxyz0123456789-."} {
+		for n := 1; n < 20; n++ {
+			nodes := []byte{0}
+			for i := 1; i < n; i++ {
+				nodes = append(nodes, alphabet[(i*7)%len(alphabet)])
+			}
+			packed := packLabels(string(nodes), []byte(alphabet))
+			unpacked, err := unpackLabels(packed, []byte(alphabet))
+			assert.NoError(t, err)
+			assert.Equal(t, string(nodes), unpacked, fmt.Sprint(alphabet, n))
+
+			_, err = unpackLabels(packed[:len(packed)-1], []byte(alphabet))
+			assert.Error(t, err)
+		}
+	}
+
+	_, err := unpackLabels([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, []byte("ab"))
+	assert.Error(t, err)
+	// code 3 of an alphabet of 3
+	_, err = unpackLabels([]byte{2, 3 << 2}, []byte("abc"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,289 @@
+// Package autocomplete serves completions of a succinct trie over HTTP.
+package autocomplete
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nobekanai/sutrie"
+)
+
+// Options configures a Handler.
+type Options struct {
+	// Weights is the weight of each key, indexed by Node.LeafIndex. It is optional,
+	// without it ranking by weight is not available and every completion has weight 0.
+	Weights []float64
+
+	// DefaultLimit is the number of completions returned when the request has no limit, 10 if zero.
+	DefaultLimit int
+
+	// MaxLimit caps the limit of a request, 100 if zero.
+	MaxLimit int
+
+	// MaxFuzzy caps the number of edits allowed on the prefix, 2 if zero.
+	MaxFuzzy int
+}
+
+// Completion is a single completion in a response.
+type Completion struct {
+	Key    string  `json:"key"`
+	Weight float64 `json:"weight"`
+}
+
+// Response is the body of a successful response.
+type Response struct {
+	Completions []Completion `json:"completions"`
+}
+
+// Handler serves completions of the keys of a trie. It accepts GET requests with the query parameters:
+//
+//	prefix  the prefix to complete
+//	limit   the maximum number of completions
+//	fuzzy   the number of edits (insertions, deletions, substitutions) allowed on the prefix, 0 by default
+//	rank    "lex" (default) for lexicographic order, "weight" for descending weight
+//
+// Responses carry an ETag derived from the content of the trie, so clients and caches can revalidate them.
+type Handler struct {
+	trie     *sutrie.SuccinctTrie
+	weighted *sutrie.WeightedTrie // nil without weights
+	opts     Options
+	etag     string
+}
+
+// NewHandler returns a handler serving the completions of trie.
+func NewHandler(trie *sutrie.SuccinctTrie, opts Options) *Handler {
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = 10
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 100
+	}
+	if opts.MaxFuzzy <= 0 {
+		opts.MaxFuzzy = 2
+	}
+	if opts.Weights != nil && len(opts.Weights) != trie.Size() {
+		panic("autocomplete: number of weights does not match number of keys")
+	}
+
+	h := fnv.New64a()
+	_ = trie.Marshal(h)
+	for _, w := range opts.Weights {
+		fmt.Fprint(h, w)
+	}
+
+	handler := &Handler{
+		trie: trie,
+		opts: opts,
+		etag: fmt.Sprintf(`"%016x"`, h.Sum64()),
+	}
+	if opts.Weights != nil {
+		handler.weighted = sutrie.NewWeighted(trie, opts.Weights)
+	}
+	return handler
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("ETag", h.etag)
+	if noneMatch(r.Header.Get("If-None-Match"), h.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, err := intParam(q.Get("limit"), h.opts.DefaultLimit)
+	if err != nil || limit < 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, h.opts.MaxLimit)
+
+	fuzzy, err := intParam(q.Get("fuzzy"), 0)
+	if err != nil || fuzzy < 0 || fuzzy > h.opts.MaxFuzzy {
+		http.Error(w, "invalid fuzzy", http.StatusBadRequest)
+		return
+	}
+
+	var byWeight bool
+	switch q.Get("rank") {
+	case "", "lex":
+	case "weight":
+		if h.opts.Weights == nil {
+			http.Error(w, "ranking by weight is not available", http.StatusBadRequest)
+			return
+		}
+		byWeight = true
+	default:
+		http.Error(w, "invalid rank", http.StatusBadRequest)
+		return
+	}
+
+	completions := h.Complete(q.Get("prefix"), limit, fuzzy, byWeight)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Response{Completions: completions})
+}
+
+// noneMatch reports whether the If-None-Match header lists etag, or is "*". Entity tags are compared weakly,
+// as RFC 9110 requires for If-None-Match, so W/ is ignored.
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// Complete returns up to limit keys starting with a string within fuzzy edits of prefix,
+// in lexicographic order or by descending weight. Ranked by weight, the keys of highest weight under every
+// matching node are found with WeightedTrie.TopK and the best ones kept in a heap of limit completions,
+// without walking the subtrees.
+func (h *Handler) Complete(prefix string, limit, fuzzy int, byWeight bool) []Completion {
+	completions := []Completion{}
+	if limit == 0 {
+		return completions
+	}
+	byWeight = byWeight && h.weighted != nil
+
+	var top rankHeap
+	collect := func(key string, node sutrie.Node) {
+		if byWeight {
+			for _, wk := range h.weighted.TopK(key, limit) {
+				top.add(Completion{Key: wk.Key, Weight: wk.Weight}, limit)
+			}
+			return
+		}
+		_ = node.Walk(func(rest string, node sutrie.Node) error {
+			if !node.Leaf() {
+				return nil
+			}
+			c := Completion{Key: key + rest}
+			if h.opts.Weights != nil {
+				c.Weight = h.opts.Weights[node.LeafIndex()]
+			}
+			completions = append(completions, c)
+			if len(completions) == limit {
+				return sutrie.SkipAll
+			}
+			return nil
+		})
+	}
+
+	if fuzzy == 0 {
+		if node := h.trie.Root().Search(prefix); node.Exists() {
+			collect(prefix, node)
+		}
+	} else {
+		// the rows of the Levenshtein distance between the walked path and the prefixes of prefix
+		rows := [][]int{make([]int, len(prefix)+1)}
+		for i := range rows[0] {
+			rows[0][i] = i
+		}
+
+		_ = h.trie.Walk(func(key string, node sutrie.Node) error {
+			if len(key) > 0 {
+				prev := rows[len(key)-1]
+				row := make([]int, len(prefix)+1)
+				row[0] = len(key)
+				for i := 1; i <= len(prefix); i++ {
+					cost := 1
+					if prefix[i-1] == key[len(key)-1] {
+						cost = 0
+					}
+					row[i] = min(prev[i]+1, row[i-1]+1, prev[i-1]+cost)
+				}
+				rows = append(rows[:len(key)], row)
+			}
+
+			row := rows[len(key)]
+			if row[len(prefix)] <= fuzzy {
+				// every key under node completes a close enough prefix
+				collect(key, node)
+				if !byWeight && len(completions) == limit {
+					return sutrie.SkipAll
+				}
+				return sutrie.SkipSubtree
+			}
+			for _, d := range row {
+				if d <= fuzzy {
+					return nil
+				}
+			}
+			return sutrie.SkipSubtree
+		})
+	}
+
+	if byWeight {
+		return top.sorted()
+	}
+	return completions
+}
+
+// rankHeap keeps the completions of highest weight, the first found among equal weights, its root being the worst.
+type rankHeap struct {
+	entries []rankEntry
+	seq     int
+}
+
+type rankEntry struct {
+	c   Completion
+	seq int
+}
+
+func (h *rankHeap) Len() int { return len(h.entries) }
+func (h *rankHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	if a.c.Weight != b.c.Weight {
+		return a.c.Weight < b.c.Weight
+	}
+	return a.seq > b.seq
+}
+func (h *rankHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *rankHeap) Push(x any)    { h.entries = append(h.entries, x.(rankEntry)) }
+func (h *rankHeap) Pop() any {
+	x := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return x
+}
+
+// add adds c if it is among the limit best completions added so far.
+func (h *rankHeap) add(c Completion, limit int) {
+	e := rankEntry{c, h.seq}
+	h.seq++
+	if len(h.entries) < limit {
+		heap.Push(h, e)
+	} else if c.Weight > h.entries[0].c.Weight {
+		h.entries[0] = e
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted returns the completions by descending weight, then in the order they were added.
+func (h *rankHeap) sorted() []Completion {
+	sort.Slice(h.entries, func(i, j int) bool { return h.Less(j, i) })
+	completions := make([]Completion, len(h.entries))
+	for i, e := range h.entries {
+		completions[i] = e.c
+	}
+	return completions
+}
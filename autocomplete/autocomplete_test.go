@@ -0,0 +1,144 @@
+package autocomplete
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/nobekanai/sutrie"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, h http.Handler, target string, header ...string) (*httptest.ResponseRecorder, []string) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp Response
+	if rec.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	var keys []string
+	for _, c := range resp.Completions {
+		keys = append(keys, c.Key)
+	}
+	return rec, keys
+}
+
+func TestHandler(t *testing.T) {
+	weight := map[string]float64{"apple": 5, "application": 9, "apply": 1, "banana": 3, "band": 7, "ample": 2}
+	var dict []string
+	for key := range weight {
+		dict = append(dict, key)
+	}
+	trie := sutrie.BuildSuccinctTrie(dict)
+	weights := make([]float64, trie.Size())
+	for key, w := range weight {
+		weights[trie.Root().Search(key).LeafIndex()] = w
+	}
+
+	h := NewHandler(trie, Options{Weights: weights})
+
+	rec, keys := get(t, h, "/?prefix=app")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"apple", "application", "apply"}, keys)
+
+	_, keys = get(t, h, "/?prefix=app&limit=2")
+	assert.Equal(t, []string{"apple", "application"}, keys)
+
+	_, keys = get(t, h, "/?prefix=app&rank=weight&limit=2")
+	assert.Equal(t, []string{"application", "apple"}, keys)
+
+	_, keys = get(t, h, "/?prefix=bnd&fuzzy=1")
+	assert.Equal(t, []string{"band"}, keys)
+
+	_, keys = get(t, h, "/?prefix=apl&fuzzy=1")
+	assert.Equal(t, []string{"ample", "apple", "application", "apply"}, keys)
+
+	_, keys = get(t, h, "/?prefix=zzz")
+	assert.Empty(t, keys)
+
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	for _, header := range []string{etag, `"x", ` + etag, "W/" + etag, "*"} {
+		rec, _ = get(t, h, "/?prefix=app", "If-None-Match", header)
+		assert.Equal(t, http.StatusNotModified, rec.Code, header)
+	}
+	rec, _ = get(t, h, "/?prefix=app", "If-None-Match", `"x", W/"y"`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	_, keys = get(t, h, "/?prefix=apl&fuzzy=1&rank=weight&limit=3")
+	assert.Equal(t, []string{"application", "apple", "ample"}, keys)
+	_, keys = get(t, h, "/?prefix=bamd&fuzzy=1&rank=weight")
+	assert.Equal(t, []string{"band"}, keys)
+
+	for _, target := range []string{"/?limit=x", "/?fuzzy=9", "/?rank=random"} {
+		rec, _ = get(t, h, target)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+
+	rec, _ = get(t, NewHandler(trie, Options{}), "/?rank=weight")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestCompleteByWeight(t *testing.T) {
+	var dict []string
+	for i := 0; i < 2000; i++ {
+		dict = append(dict, fmt.Sprintf("k%04d", i*7%2000))
+	}
+	trie := sutrie.BuildSuccinctTrie(dict)
+	weights := make([]float64, trie.Size())
+	for i := range weights {
+		weights[i] = float64(i * 37 % 101)
+	}
+	h := NewHandler(trie, Options{Weights: weights})
+
+	for _, q := range []struct {
+		prefix       string
+		limit, fuzzy int
+	}{{"k1", 10, 0}, {"k12", 5, 1}, {"x003", 7, 1}, {"", 20, 0}, {"k0", 3, 2}} {
+		// every key having a prefix within fuzzy edits, ranked by a stable sort
+		var all []Completion
+		_ = trie.Walk(func(key string, node sutrie.Node) error {
+			if !node.Leaf() {
+				return nil
+			}
+			for l := 0; l <= len(key); l++ {
+				if distance(key[:l], q.prefix) <= q.fuzzy {
+					all = append(all, Completion{key, weights[node.LeafIndex()]})
+					break
+				}
+			}
+			return nil
+		})
+		sort.SliceStable(all, func(i, j int) bool { return all[i].Weight > all[j].Weight })
+		assert.Equal(t, all[:min(q.limit, len(all))], h.Complete(q.prefix, q.limit, q.fuzzy, true), q.prefix)
+	}
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			prev, row[j] = row[j], min(row[j]+1, row[j-1]+1, prev+cost)
+		}
+	}
+	return row[len(b)]
+}
@@ -0,0 +1,185 @@
+package sutrie
+
+import "sync"
+
+// minShard is the minimum number of keys handed to a goroutine by a parallel batch,
+// below which the synchronization overhead is not worth it.
+const minShard = 1024
+
+// BatchOption configures a batch query.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	workers int
+	lanes   int
+}
+
+// Parallel shards a batch across up to n goroutines.
+func Parallel(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.workers = n
+	}
+}
+
+// Interleave descends n keys at a time, one step of each in turn, so that the cache misses of a step
+// overlap with the steps of the other keys instead of stalling the whole batch. Each step also loads the first
+// label the next step of its key will search, so that it is in cache by then. On a trie of 1M random keys searched
+// in random order, 4 keys take 2.4 to 3.1µs per key against 3.6 to 4.4µs by default, and 3.2 to 3.5µs without
+// the loads, see BenchmarkSearchPrefixBatch.
+func Interleave(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.lanes = n
+	}
+}
+
+// SearchPrefixBatch stores t.SearchPrefix(keys[i]) into out[i] for every key, the keys being normalized
+// like by SearchPrefix, see WithNormalizer.
+// It panics if out is shorter than keys.
+func (t *SuccinctTrie) SearchPrefixBatch(keys []string, out []int, opts ...BatchOption) {
+	if len(out) < len(keys) {
+		panic("sutrie: out is shorter than keys")
+	}
+
+	root := t.Root()
+	if t.stats != nil {
+		t.stats.recordBatch(len(keys))
+	}
+	runBatch(len(keys), opts, func(l, r, lanes int) {
+		if t.stats != nil || t.rewritesQueries() {
+			for i := l; i < r; i++ {
+				out[i] = t.SearchPrefix(keys[i])
+			}
+			return
+		}
+		if lanes > 1 && t.fold != foldUnicode {
+			_ = descend(root, keys[l:r], lanes, func(i int, _ bool, lastUnmatch int) {
+				out[l+i] = lastUnmatch
+			})
+			return
+		}
+		for i := l; i < r; i++ {
+			out[i] = root.SearchPrefix(keys[i])
+		}
+	})
+}
+
+// ContainsBatch stores t.Contains(keys[i]) into out[i] for every key, the keys being normalized
+// like by Contains, see WithNormalizer.
+// It panics if out is shorter than keys.
+func (t *SuccinctTrie) ContainsBatch(keys []string, out []bool, opts ...BatchOption) {
+	if len(out) < len(keys) {
+		panic("sutrie: out is shorter than keys")
+	}
+
+	root := t.Root()
+	if t.stats != nil {
+		t.stats.recordBatch(len(keys))
+	}
+	runBatch(len(keys), opts, func(l, r, lanes int) {
+		if t.stats != nil || t.rewritesQueries() {
+			for i := l; i < r; i++ {
+				out[i] = t.Contains(keys[i])
+			}
+			return
+		}
+		if lanes > 1 && t.fold != foldUnicode {
+			_ = descend(root, keys[l:r], lanes, func(i int, leaf bool, _ int) {
+				out[l+i] = leaf
+			})
+			return
+		}
+		for i := l; i < r; i++ {
+			out[i] = root.Search(keys[i]).Leaf()
+		}
+	})
+}
+
+// lane is the state of the descent of a key in an interleaved batch, the node being kept in locals like by search.
+type lane struct {
+	key          int // the index of the key, -1 once the lane has no more keys
+	i            int // the next byte of the key
+	k            int32
+	first, after int32
+	lastUnmatch  int
+}
+
+// descend searches keys from root, lanes at a time, and calls done with the index of every key, whether it is
+// a key of the trie and its longest prefix match. Keys must not need Unicode folding. It returns the sum of
+// the labels loaded ahead of the next steps, so that the loads are not dropped as dead code.
+func descend(root Node, keys []string, lanes int, done func(i int, leaf bool, lastUnmatch int)) (touched byte) {
+	t := root.trie
+	ls := make([]lane, min(lanes, len(keys)))
+	next := 0
+	load := func(l *lane) {
+		*l = lane{key: -1}
+		for ; next < len(keys); next++ {
+			if keys[next] != "" {
+				*l = lane{key: next, k: root.index, first: root.firstChild, after: root.afterLastChild}
+				next++
+				return
+			}
+			done(next, root.leaf, 0)
+		}
+	}
+	for j := range ls {
+		load(&ls[j])
+	}
+
+	for active := true; active; {
+		active = false
+		for j := range ls {
+			l := &ls[j]
+			if l.key < 0 {
+				continue
+			}
+			active = true
+
+			key := keys[l.key]
+			leaf := false
+			if l.first < l.after {
+				if l.k = t.childOf(l.k, l.first, l.after, key[l.i]); l.k >= 0 {
+					l.first, l.after = t.childRange(l.k)
+					l.i++
+					leaf = t.isLeaf(l.k)
+					if leaf {
+						l.lastUnmatch = l.i
+					}
+					if l.i < len(key) {
+						if l.first < l.after {
+							touched += t.label(l.first)
+						}
+						continue
+					}
+				}
+			}
+			done(l.key, leaf, l.lastUnmatch)
+			load(l)
+		}
+	}
+	return touched
+}
+
+// runBatch calls fn over the shards of [0, n), with the number of keys to interleave.
+func runBatch(n int, opts []BatchOption, fn func(l, r, lanes int)) {
+	var c batchConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	workers := min(c.workers, n/minShard)
+	if workers <= 1 {
+		fn(0, n, c.lanes)
+		return
+	}
+
+	var wg sync.WaitGroup
+	shard := (n + workers - 1) / workers
+	for l := 0; l < n; l += shard {
+		wg.Add(1)
+		go func(l, r int) {
+			defer wg.Done()
+			fn(l, r, c.lanes)
+		}(l, min(l+shard, n))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package sutrie
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	const l = 10000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(1 + mrand.Intn(4))
+	}
+	trie := BuildSuccinctTrie(dict)
+	root := trie.Root()
+
+	keys := make([]string, 2*l)
+	for i := range keys {
+		if i%2 == 0 {
+			keys[i] = dict[i/2] + randomString(mrand.Intn(3))
+		} else {
+			keys[i] = randomString(1 + mrand.Intn(5))
+		}
+	}
+
+	keys = append(keys, "", dict[0])
+	for _, opts := range [][]BatchOption{nil, {Parallel(4)}, {Interleave(8)}, {Interleave(3), Parallel(4)}, {Interleave(64)}} {
+		prefixes := make([]int, len(keys))
+		contains := make([]bool, len(keys))
+		trie.SearchPrefixBatch(keys, prefixes, opts...)
+		trie.ContainsBatch(keys, contains, opts...)
+
+		for i, key := range keys {
+			assert.Equal(t, root.SearchPrefix(key), prefixes[i])
+			assert.Equal(t, root.Search(key).Leaf(), contains[i])
+		}
+	}
+
+	assert.Panics(t, func() { trie.ContainsBatch(keys, nil) })
+}
+
+func TestBatchFolding(t *testing.T) {
+	keys := []string{"HAT", "hatter", "Straße", "STRASSE.de", "it", "ÉTÉ", "été.fr", ""}
+	for _, opt := range []Option{WithCaseFolding(), WithUnicodeCaseFolding()} {
+		trie := BuildSuccinctTrie([]string{"hat", "straße", "été", "it"}, opt)
+		root := trie.Root()
+
+		prefixes := make([]int, len(keys))
+		contains := make([]bool, len(keys))
+		trie.SearchPrefixBatch(keys, prefixes, Interleave(4))
+		trie.ContainsBatch(keys, contains, Interleave(4))
+		for i, key := range keys {
+			assert.Equal(t, root.SearchPrefix(key), prefixes[i], key)
+			assert.Equal(t, root.Search(key).Leaf(), contains[i], key)
+		}
+	}
+}
+
+func BenchmarkSearchPrefixBatch(b *testing.B) {
+	const l = 1000000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(10 + mrand.Intn(11))
+	}
+	trie := BuildSuccinctTrie(dict)
+	out := make([]int, l)
+
+	// searching in random order, as the sorted dict would hit the cache
+	keys := append([]string(nil), dict...)
+	mrand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+	// lanes 0 is the default, without Interleave
+	for _, workers := range []int{1, 4} {
+		for _, lanes := range []int{0, 2, 4, 8, 16} {
+			opts := []BatchOption{Parallel(workers)}
+			if lanes > 0 {
+				opts = append(opts, Interleave(lanes))
+			}
+			b.Run(fmt.Sprint("workers-", workers, "/lanes-", lanes), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					trie.SearchPrefixBatch(keys, out, opts...)
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*l), "ns/key")
+			})
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sutrie
+
+import (
+	"bytes"
+	"encoding"
+	"io"
+)
+
+var (
+	_ io.WriterTo                = (*SuccinctTrie)(nil)
+	_ io.ReaderFrom              = (*SuccinctTrie)(nil)
+	_ encoding.BinaryMarshaler   = (*SuccinctTrie)(nil)
+	_ encoding.BinaryUnmarshaler = (*SuccinctTrie)(nil)
+)
+
+// WriteTo writes the trie to w like Marshal and returns the number of bytes written.
+func (t *SuccinctTrie) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := t.Marshal(cw)
+	return cw.n, err
+}
+
+// ReadFrom reads a trie written by Marshal or WriteTo from r into t like Unmarshal,
+// and returns the number of bytes read. It reads no further than the trie, so that other data may follow it in r.
+func (t *SuccinctTrie) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	err := t.Unmarshal(cr)
+	return cr.n, err
+}
+
+// MarshalBinary returns the encoding of the trie by Marshal, so that it can be a field of a gob-encoded struct.
+func (t *SuccinctTrie) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Marshal(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a trie encoded by MarshalBinary or Marshal into t.
+func (t *SuccinctTrie) UnmarshalBinary(data []byte) error {
+	return t.Unmarshal(bytes.NewReader(data))
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// countingReader is an io.ByteReader, so that gob does not buffer it and reads no further than a trie.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r, b[:])
+	return b[0], err
+}
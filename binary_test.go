@@ -0,0 +1,64 @@
+package sutrie
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterToReaderFrom(t *testing.T) {
+	a := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "中文"})
+	b := BuildSuccinctTrie([]string{"example.com"}, WithReversedKeys(), WithEliasFanoLeaves())
+
+	var buf bytes.Buffer
+	na, err := a.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), na)
+	nb, err := b.WriteTo(&buf)
+	assert.NoError(t, err)
+	buf.WriteString("trailer")
+
+	// a reader which is not an io.ByteReader, which gob would otherwise buffer past the first trie
+	r := io.MultiReader(&buf)
+	var loadedA, loadedB SuccinctTrie
+	n, err := loadedA.ReadFrom(r)
+	assert.NoError(t, err)
+	assert.Equal(t, na, n)
+	n, err = loadedB.ReadFrom(r)
+	assert.NoError(t, err)
+	assert.Equal(t, nb, n)
+	rest, _ := io.ReadAll(r)
+	assert.Equal(t, "trailer", string(rest))
+
+	assert.True(t, a.Equal(&loadedA))
+	assert.True(t, b.Equal(&loadedB))
+	assert.True(t, loadedB.MatchDomainSuffix("www.example.com"))
+
+	_, err = new(SuccinctTrie).ReadFrom(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+}
+
+func TestBinaryMarshaler(t *testing.T) {
+	type snapshot struct {
+		Name string
+		Trie *SuccinctTrie
+	}
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a"}, WithCaseFolding())
+
+	data, err := trie.MarshalBinary()
+	assert.NoError(t, err)
+	var loaded SuccinctTrie
+	assert.NoError(t, loaded.UnmarshalBinary(data))
+	assert.True(t, trie.Equal(&loaded))
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(snapshot{"words", trie}))
+	var s snapshot
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&s))
+	assert.Equal(t, "words", s.Name)
+	assert.True(t, trie.Equal(s.Trie))
+	assert.True(t, s.Trie.Root().Search("HAT").Leaf())
+}
@@ -0,0 +1,269 @@
+// Package bitvec implements a bit vector with constant time rank and fast select queries,
+// the building block of the succinct trie, for use in other succinct structures.
+//
+// On amd64 processors with a fast PDEP instruction, select within a word is done in assembly,
+// the purego build tag disables it.
+package bitvec
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidData is returned by UnmarshalBinary when the data is not a marshaled Vector.
+var ErrInvalidData = errors.New("bitvec: invalid data")
+
+// Vector is a bit vector. Rank and select queries need an index, which is built by Init
+// and invalidated by Set. Once initialized, a Vector is safe for concurrent reads.
+type Vector struct {
+	// words holds the bits until Init moves them into the blocks of the index
+	words []uint64
+	n     int
+	index
+
+	// owned is true if the words were allocated by Reset, so that Init can interleave the index into them
+	owned bool
+}
+
+// New returns a Vector of n unset bits.
+func New(n int) *Vector {
+	return &Vector{words: make([]uint64, (n+63)>>6), n: n}
+}
+
+// Reset makes v a vector of n unset bits, like New, but reuses the memory of its words or of its index,
+// and Init then builds the index in place if it fits. It is meant for rebuilding vectors of similar sizes
+// without allocating: v and the slices returned by its Words must not be used by anyone else anymore,
+// copies of v included.
+func (v *Vector) Reset(n int) {
+	words := (n + 63) >> 6
+	buf := v.words
+	if cap(v.blocks) > cap(buf) {
+		buf = v.blocks
+	}
+	if cap(buf) < words {
+		buf = make([]uint64, words, indexWords(words))
+	} else {
+		buf = buf[:words]
+		clear(buf)
+	}
+	*v = Vector{words: buf, n: n, owned: true}
+}
+
+// FromWords returns an initialized Vector of the first n bits of words, bit i being words[i/64]>>(i%64)&1,
+// the bits past n must be unset. The words are copied into the index, see Init.
+// It panics if n is negative or larger than 64*len(words).
+func FromWords(words []uint64, n int) *Vector {
+	v := WrapWords(words, n)
+	v.Init()
+	return v
+}
+
+// WrapWords is like FromWords but does not build the index, Init must be called before rank and select queries.
+// The words are not copied until then.
+func WrapWords(words []uint64, n int) *Vector {
+	if n < 0 || n > len(words)<<6 {
+		panic("bitvec: length out of range")
+	}
+	return &Vector{words: words[:(n+63)>>6], n: n}
+}
+
+// Clone returns a deep copy of the vector and its index.
+func (v *Vector) Clone() *Vector {
+	c := *v
+	c.words = clone(v.words)
+	c.blocks = clone(v.blocks)
+	c.samples1, c.samples0 = clone(v.samples1), clone(v.samples0)
+	c.spill1, c.spill0 = clone(v.spill1), clone(v.spill0)
+	return &c
+}
+
+// Compact reallocates the slices of the vector and its index having spare capacity, like after Set, to their length.
+func (v *Vector) Compact() {
+	v.words = clip(v.words)
+	v.blocks = clip(v.blocks)
+	v.samples1, v.samples0 = clip(v.samples1), clip(v.samples0)
+	v.spill1, v.spill0 = clip(v.spill1), clip(v.spill0)
+}
+
+func clone[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	copy(c, s)
+	return c
+}
+
+// clip returns s reallocated to its length if it has spare capacity.
+func clip[T any](s []T) []T {
+	if cap(s) == len(s) {
+		return s
+	}
+	return clone(s)
+}
+
+// Len returns the number of bits of the vector.
+func (v *Vector) Len() int {
+	return v.n
+}
+
+// Words returns the words of the vector, see FromWords. The words of an initialized vector are interleaved
+// with its index, so they are copied, see Word.
+func (v *Vector) Words() []uint64 {
+	if v.blocks == nil {
+		return v.words
+	}
+	words := make([]uint64, v.numWords())
+	for w := range words {
+		words[w] = v.word(w)
+	}
+	return words
+}
+
+// Word returns word i of the vector without copying them, i must be in [0, (Len()+63)/64).
+func (v *Vector) Word(i int) uint64 {
+	return v.word(i)
+}
+
+func (v *Vector) numWords() int {
+	if v.blocks == nil {
+		return len(v.words)
+	}
+	return (v.n + 63) >> 6
+}
+
+func (v *Vector) word(w int) uint64 {
+	if v.blocks == nil {
+		return v.words[w]
+	}
+	return v.blocks[w>>3*blockStride+2+w&7]
+}
+
+// Set sets bit i to value, growing the vector if needed.
+func (v *Vector) Set(i int, value bool) {
+	if v.blocks != nil {
+		v.words = v.Words()
+	}
+	for i>>6 >= len(v.words) {
+		v.words = append(v.words, 0)
+	}
+	if i >= v.n {
+		v.n = i + 1
+	}
+	if value {
+		v.words[i>>6] |= uint64(1) << (i & 63)
+	} else {
+		v.words[i>>6] &^= uint64(1) << (i & 63)
+	}
+
+	v.index = index{}
+}
+
+// Get returns bit i, bits past the end are unset.
+func (v *Vector) Get(i int) bool {
+	if i>>6 >= v.numWords() {
+		return false
+	}
+
+	return v.word(i>>6)&(uint64(1)<<(i&63)) > 0
+}
+
+// NextOne returns the position of the first set bit at or after i, or -1 if there is none.
+func (v *Vector) NextOne(i int) int {
+	w, n := i>>6, v.numWords()
+	if w >= n {
+		return -1
+	}
+
+	word := v.word(w) &^ (uint64(1)<<(i&63) - 1)
+	for word == 0 {
+		if w++; w == n {
+			return -1
+		}
+		word = v.word(w)
+	}
+	return w<<6 + bits.TrailingZeros64(word)
+}
+
+// MarshalBinary encodes the vector as its length followed by its words, all little endian uint64s.
+func (v *Vector) MarshalBinary() ([]byte, error) {
+	words := v.Words()[:(v.n+63)>>6]
+	data := make([]byte, 8+8*len(words))
+	binary.LittleEndian.PutUint64(data, uint64(v.n))
+	for i, word := range words {
+		binary.LittleEndian.PutUint64(data[8+8*i:], word)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a vector encoded by MarshalBinary and initializes it.
+func (v *Vector) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 || len(data)%8 != 0 {
+		return ErrInvalidData
+	}
+	words := make([]uint64, len(data)/8-1)
+	n := binary.LittleEndian.Uint64(data)
+	if n > uint64(len(words))<<6 || (n+63)>>6 != uint64(len(words)) {
+		return ErrInvalidData
+	}
+
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[8+8*i:])
+	}
+	if n&63 != 0 && words[len(words)-1]>>(n&63) != 0 {
+		return ErrInvalidData
+	}
+	*v = *FromWords(words, int(n))
+	return nil
+}
+
+const pop8tab = "" +
+	"\x00\x01\x01\x02\x01\x02\x02\x03\x01\x02\x02\x03\x02\x03\x03\x04" +
+	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
+	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
+	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
+	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
+	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
+	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
+	"\x04\x05\x05\x06\x05\x06\x06\x07\x05\x06\x06\x07\x06\x07\x07\x08"
+
+const precomp = "\x00\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x06\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\a\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x06\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00" +
+	"\x00\x00\x00\x01\x00\x02\x02\x01\x00\x03\x03\x01\x03\x02\x02\x01\x00\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\x06\x06\x01\x06\x02\x02\x01\x06\x03\x03\x01\x03\x02\x02\x01\x06\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x06\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\a\a\x01\a\x02\x02\x01\a\x03\x03\x01\x03\x02\x02\x01\a\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\a\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\a\x06\x06\x01\x06\x02\x02\x01\x06\x03\x03\x01\x03\x02\x02\x01\x06\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x06\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01" +
+	"\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x03\x00\x03\x03\x02\x00\x00\x00\x04\x00\x04\x04\x02\x00\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\x05\x00\x05\x05\x02\x00\x05\x05\x03\x05\x03\x03\x02\x00\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\x06\x00\x06\x06\x02\x00\x06\x06\x03\x06\x03\x03\x02\x00\x06\x06\x04\x06\x04\x04\x02\x06\x04\x04\x03\x04\x03\x03\x02\x00\x06\x06\x05\x06\x05\x05\x02\x06\x05\x05\x03\x05\x03\x03\x02\x06\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\a\x00\a\a\x02\x00\a\a\x03\a\x03\x03\x02\x00\a\a\x04\a\x04\x04\x02\a\x04\x04\x03\x04\x03\x03\x02\x00\a\a\x05\a\x05\x05\x02\a\x05\x05\x03\x05\x03\x03\x02\a\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\a\a\x06\a\x06\x06\x02\a\x06\x06\x03\x06\x03\x03\x02\a\x06\x06\x04\x06\x04\x04\x02\x06\x04\x04\x03\x04\x03\x03\x02\a\x06\x06\x05\x06\x05\x05\x02\x06\x05\x05\x03\x05\x03\x03\x02\x06\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02" +
+	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x03\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x04\x00\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x05\x00\x05\x05\x03\x00\x00\x00\x05\x00\x05\x05\x04\x00\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x03\x00\x00\x00\x06\x00\x06\x06\x04\x00\x06\x06\x04\x06\x04\x04\x03\x00\x00\x00\x06\x00\x06\x06\x05\x00\x06\x06\x05\x06\x05\x05\x03\x00\x06\x06\x05\x06\x05\x05\x04\x06\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x03\x00\x00\x00\a\x00\a\a\x04\x00\a\a\x04\a\x04\x04\x03\x00\x00\x00\a\x00\a\a\x05\x00\a\a\x05\a\x05\x05\x03\x00\a\a\x05\a\x05\x05\x04\a\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x03\x00\a\a\x06\a\x06\x06\x04\a\x06\x06\x04\x06\x04\x04\x03\x00\a\a\x06\a\x06\x06\x05\a\x06\x06\x05\x06\x05\x05\x03\a\x06\x06\x05\x06\x05\x05\x04\x06\x05\x05\x04\x05\x04\x04\x03" +
+	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x05\x00\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x04\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x05\x00\x00\x00\x06\x00\x06\x06\x05\x00\x06\x06\x05\x06\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x04\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x05\x00\x00\x00\a\x00\a\a\x05\x00\a\a\x05\a\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x04\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x05\x00\a\a\x06\a\x06\x06\x05\a\x06\x06\x05\x06\x05\x05\x04" +
+	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x05" +
+	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06" +
+	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a"
+
+func nthSet(v uint64, n uint8) uint8 {
+	shift := uint8(0)
+	p := pop8tab[v>>24&0xff] + pop8tab[v>>16&0xff] + pop8tab[v>>8&0xff] + pop8tab[v&0xff]
+	if p <= n {
+		v >>= 32
+		shift |= 32
+		n -= p
+	}
+	p = pop8tab[(v>>8)&0xff] + pop8tab[v&0xff]
+	if p <= n {
+		v >>= 16
+		shift |= 16
+		n -= p
+	}
+	p = pop8tab[v&0xff]
+	if p <= n {
+		v >>= 8
+		shift |= 8
+		n -= p
+	}
+	return precomp[uint64(n)<<8|v&0xff] + shift
+}
@@ -0,0 +1,336 @@
+package bitvec
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector(t *testing.T) {
+	v := Vector{}
+
+	v.Set(4, true)
+	v.Set(567, true)
+	assert.Equal(t, 568, v.Len())
+
+	assert.True(t, v.Get(4))
+	assert.True(t, v.Get(567))
+	assert.False(t, v.Get(8))
+	assert.False(t, v.Get(568))
+
+	v.Set(567, false)
+	assert.False(t, v.Get(567))
+	assert.True(t, v.Get(4))
+
+	v.Set(5, true)
+	v.Set(128, true)
+	v.Set(127, true)
+
+	v.Init()
+
+	// 4,5,127,128
+	assert.Equal(t, 4, v.Ones())
+	assert.Equal(t, 4, v.Select1(0))
+	assert.Equal(t, 5, v.Select1(1))
+	assert.Equal(t, 127, v.Select1(2))
+	assert.Equal(t, 128, v.Select1(3))
+	assert.Equal(t, -1, v.Select1(4))
+	assert.Equal(t, -1, v.Select1(-1))
+
+	assert.Equal(t, 0, v.Rank1(4))
+	assert.Equal(t, 2, v.Rank1(6))
+	assert.Equal(t, 3, v.Rank1(128))
+	assert.Equal(t, 4, v.Rank1(1000))
+	assert.Equal(t, 125, v.Rank0(128))
+
+	assert.Equal(t, 4, v.NextOne(0))
+	assert.Equal(t, 127, v.NextOne(6))
+	assert.Equal(t, -1, v.NextOne(129))
+}
+
+func TestSelect0(t *testing.T) {
+	v := New(131)
+	for _, i := range []int{0, 1, 3, 64, 65, 130} {
+		v.Set(i, true)
+	}
+	v.Init()
+
+	var zeros []int
+	for i := 0; i < v.Len(); i++ {
+		if !v.Get(i) {
+			zeros = append(zeros, i)
+		}
+	}
+	for k, pos := range zeros {
+		assert.Equal(t, pos, v.Select0(k))
+		assert.Equal(t, k, v.Rank0(pos))
+	}
+	assert.Equal(t, -1, v.Select0(len(zeros)))
+}
+
+func TestNthSet(t *testing.T) {
+	var n uint64 = 0b1010101011
+
+	assert.Equal(t, uint8(0), nthSet(n, 0))
+	assert.Equal(t, uint8(1), nthSet(n, 1))
+	assert.Equal(t, uint8(5), nthSet(n, 3))
+
+	n = 1<<64 - 1
+
+	for i := 0; i < 64; i++ {
+		assert.Equal(t, uint8(i), nthSet(n, uint8(i)))
+	}
+}
+
+func TestSelectWord(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		v := rnd.Uint64() & rnd.Uint64()
+		for k := 0; k < bits.OnesCount64(v); k++ {
+			if selectWord(v, uint8(k)) != nthSet(v, uint8(k)) {
+				t.Fatalf("select %d of %064b", k, v)
+			}
+		}
+	}
+	assert.Equal(t, uint8(63), selectWord(1<<63, 0))
+}
+
+func BenchmarkSelectWord(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	words := make([]uint64, 1024)
+	ks := make([]uint8, 1024)
+	for i := range words {
+		words[i] = rnd.Uint64() | 1
+		ks[i] = uint8(rnd.Intn(bits.OnesCount64(words[i])))
+	}
+
+	b.Run("table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nthSet(words[i&1023], ks[i&1023])
+		}
+	})
+	b.Run("dispatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			selectWord(words[i&1023], ks[i&1023])
+		}
+	})
+}
+
+func TestMarshalBinary(t *testing.T) {
+	v := New(0)
+	for i := 0; i < 1000; i += 7 {
+		v.Set(i, true)
+	}
+	v.Init()
+
+	data, err := v.MarshalBinary()
+	assert.NoError(t, err)
+
+	var w Vector
+	assert.NoError(t, w.UnmarshalBinary(data))
+	assert.Equal(t, v.Len(), w.Len())
+	for k := 0; k < v.Ones(); k++ {
+		assert.Equal(t, v.Select1(k), w.Select1(k))
+	}
+
+	assert.ErrorIs(t, w.UnmarshalBinary(data[:len(data)-8]), ErrInvalidData)
+	assert.ErrorIs(t, w.UnmarshalBinary(data[:5]), ErrInvalidData)
+
+	data[len(data)-1] = 0xff // bits past the length
+	assert.ErrorIs(t, w.UnmarshalBinary(data), ErrInvalidData)
+}
+
+func TestFromWords(t *testing.T) {
+	v := FromWords([]uint64{0b1011, 0}, 70)
+	assert.Equal(t, 70, v.Len())
+	assert.Equal(t, 3, v.Ones())
+	assert.Equal(t, 3, v.Select1(2))
+	assert.Equal(t, 2, v.Select0(0))
+
+	assert.Panics(t, func() { FromWords(nil, 1) })
+}
+
+func TestCloneCompact(t *testing.T) {
+	v := New(0)
+	for i := 0; i < 1100; i += 3 {
+		v.Set(i, true)
+	}
+	u := v.Clone()
+	assert.Greater(t, cap(v.Words()), len(v.Words()))
+	v.Compact()
+	assert.Equal(t, cap(v.Words()), len(v.Words()))
+	assert.Equal(t, u.Words(), v.Words())
+	v.Init()
+
+	c := v.Clone()
+	c.Set(1, true)
+	assert.False(t, v.Get(1))
+	assert.True(t, c.Get(1))
+	assert.Equal(t, 333, v.Rank1(998))
+
+	v.Compact()
+	assert.Equal(t, cap(v.blocks), len(v.blocks))
+	assert.Equal(t, u.Words(), v.Words())
+	assert.Equal(t, 333, v.Rank1(998))
+	assert.Equal(t, 999, v.Select1(333))
+}
+
+func TestReset(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var v Vector
+	// the sparse vectors spill the positions of their set bits
+	for _, c := range []struct {
+		n, every int
+		reused   bool // if the index of the previous vector fits
+	}{{1000, 3, false}, {5000, 3, false}, {4900, 3, true}, {4990, 3, true}, {0, 3, false}, {77, 3, true}, {300000, 2, false}, {290000, 500, true}} {
+		n := c.n
+		before := v.blocks
+		v.Reset(n)
+		assert.Equal(t, n, v.Len())
+		u := New(n)
+		for i := 0; i < n; i++ {
+			if rnd.Intn(c.every) == 0 {
+				v.Set(i, true)
+				u.Set(i, true)
+			}
+		}
+		v.Init()
+		u.Init()
+		assert.Equal(t, u.blocks, v.blocks)
+		assert.Equal(t, u.Words(), v.Words())
+		assert.Equal(t, u.spill1, v.spill1)
+		for i := 0; i <= n; i += 7 {
+			assert.Equal(t, u.Rank1(i), v.Rank1(i))
+		}
+		for k := 0; k < u.Ones(); k += 5 {
+			assert.Equal(t, u.Select1(k), v.Select1(k))
+		}
+		if c.reused {
+			assert.Same(t, &before[:1][0], &v.blocks[0], n)
+		}
+	}
+}
+
+func TestRankSelectRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// from dense to sparse enough to spill the select samples, of unset and set bits
+	for _, density := range []float64{1, 0.9995, 0.99, 0.9, 0.5, 0.1, 0.01, 0.0005} {
+		n := 200000 + rnd.Intn(1000)
+		v := New(n)
+		for i := 0; i < n; i++ {
+			if rnd.Float64() < density {
+				v.Set(i, true)
+			}
+		}
+		v.Init()
+
+		ones, zeros := 0, 0
+		for i := 0; i < n; i++ {
+			if v.Rank1(i) != ones || v.Rank0(i) != zeros {
+				t.Fatalf("density %v: rank of %d", density, i)
+			}
+			if v.Get(i) {
+				if v.Select1(ones) != i {
+					t.Fatalf("density %v: select1 of %d", density, ones)
+				}
+				ones++
+			} else {
+				if v.Select0(zeros) != i {
+					t.Fatalf("density %v: select0 of %d", density, zeros)
+				}
+				zeros++
+			}
+		}
+		assert.Equal(t, ones, v.Ones())
+		assert.Equal(t, ones, v.Rank1(n))
+		assert.Equal(t, -1, v.Select1(ones))
+		assert.Equal(t, -1, v.Select0(zeros))
+	}
+}
+
+func BenchmarkSelect1(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	v := New(1 << 24)
+	for i := 0; i < v.Len(); i++ {
+		v.Set(i, rnd.Intn(2) == 0)
+	}
+	v.Init()
+
+	ks := make([]int, 1024)
+	for i := range ks {
+		ks[i] = rnd.Intn(v.Ones())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Select1(ks[i&1023])
+	}
+}
+
+func BenchmarkRank1(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	v := New(1 << 24)
+	for i := 0; i < v.Len(); i++ {
+		v.Set(i, rnd.Intn(2) == 0)
+	}
+	v.Init()
+
+	is := make([]int, 1024)
+	for i := range is {
+		is[i] = rnd.Intn(v.Len())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Rank1(is[i&1023])
+	}
+}
+
+func TestEliasFano(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for _, density := range []float64{0, 0.0005, 0.01, 0.1, 0.5, 1} {
+		n := 20000 + rnd.Intn(100)
+		var positions []int
+		for i := 0; i < n; i++ {
+			if rnd.Float64() < density {
+				positions = append(positions, i)
+			}
+		}
+		e := NewEliasFano(positions, n)
+
+		data, err := e.MarshalBinary()
+		assert.NoError(t, err)
+		var d EliasFano
+		assert.NoError(t, d.UnmarshalBinary(data))
+
+		for _, e := range []*EliasFano{e, &d} {
+			assert.Equal(t, n, e.Len())
+			assert.Equal(t, len(positions), e.Ones())
+			for k, p := range positions {
+				if e.Select1(k) != p {
+					t.Fatalf("density %v: select1 of %d", density, k)
+				}
+			}
+			assert.Equal(t, -1, e.Select1(len(positions)))
+
+			k := 0
+			for i := 0; i < n; i++ {
+				set := k < len(positions) && positions[k] == i
+				if e.Rank1(i) != k || e.Get(i) != set {
+					t.Fatalf("density %v: rank of %d", density, i)
+				}
+				if set {
+					k++
+				}
+			}
+			assert.Equal(t, k, e.Rank1(n))
+			assert.False(t, e.Get(n))
+		}
+	}
+
+	assert.ErrorIs(t, new(EliasFano).UnmarshalBinary([]byte{1, 2, 3}), ErrInvalidData)
+}
@@ -0,0 +1,175 @@
+package bitvec
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// EliasFano is an immutable, compressed bit vector for sparse bits. It stores the positions of the m set bits
+// of n in about 2+log(n/m) bits each: the low bits of the positions are packed, the high bits are stored in unary
+// in a Vector, where the ith set bit is at (position >> lowBits) + i.
+type EliasFano struct {
+	n, m    int
+	lowBits uint
+	low     []uint64
+	high    Vector
+}
+
+// NewEliasFano returns the EliasFano of length n whose set bits are at positions,
+// which must be strictly increasing and less than n.
+func NewEliasFano(positions []int, n int) *EliasFano {
+	e := &EliasFano{n: n, m: len(positions)}
+	if e.m > 0 && n > e.m {
+		e.lowBits = uint(bits.Len(uint(n/e.m)) - 1)
+	}
+
+	e.low = make([]uint64, (e.m*int(e.lowBits)+63)>>6)
+	e.high = *New(e.m + n>>e.lowBits + 1)
+	for i, p := range positions {
+		if e.lowBits > 0 {
+			e.setLow(i, uint64(p)&(1<<e.lowBits-1))
+		}
+		e.high.Set(p>>e.lowBits+i, true)
+	}
+	e.high.Init()
+	return e
+}
+
+func (e *EliasFano) setLow(i int, value uint64) {
+	pos := uint(i) * e.lowBits
+	e.low[pos>>6] |= value << (pos & 63)
+	if pos&63+e.lowBits > 64 {
+		e.low[pos>>6+1] |= value >> (64 - pos&63)
+	}
+}
+
+func (e *EliasFano) getLow(i int) int {
+	if e.lowBits == 0 {
+		return 0
+	}
+
+	pos := uint(i) * e.lowBits
+	value := e.low[pos>>6] >> (pos & 63)
+	if pos&63+e.lowBits > 64 {
+		value |= e.low[pos>>6+1] << (64 - pos&63)
+	}
+	return int(value & (1<<e.lowBits - 1))
+}
+
+// Len returns the number of bits of the vector.
+func (e *EliasFano) Len() int {
+	return e.n
+}
+
+// Ones returns the number of set bits.
+func (e *EliasFano) Ones() int {
+	return e.m
+}
+
+// Clone returns a deep copy of the vector.
+func (e *EliasFano) Clone() *EliasFano {
+	c := *e
+	c.low = clone(e.low)
+	c.high = *e.high.Clone()
+	return &c
+}
+
+// Compact reallocates the slices of the vector having spare capacity to their length.
+func (e *EliasFano) Compact() {
+	e.low = clip(e.low)
+	e.high.Compact()
+}
+
+// SizeInBytes returns the size in bytes of the vector with its index.
+func (e *EliasFano) SizeInBytes() int {
+	return 8*(len(e.low)+(e.high.Len()+63)>>6) + e.high.IndexBytes()
+}
+
+// Select1 returns the position of the kth (starting from 0) set bit, or -1 if there is none.
+func (e *EliasFano) Select1(k int) int {
+	if k < 0 || e.m <= k {
+		return -1
+	}
+
+	return (e.high.Select1(k)-k)<<e.lowBits | e.getLow(k)
+}
+
+// Rank1 returns the number of set bits in [0, i).
+func (e *EliasFano) Rank1(i int) int {
+	k, _ := e.rank(i)
+	return k
+}
+
+// Get returns bit i, bits past the end are unset.
+func (e *EliasFano) Get(i int) bool {
+	_, ok := e.rank(i)
+	return ok
+}
+
+// rank returns the number of set bits before i, and whether bit i is set.
+func (e *EliasFano) rank(i int) (int, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	if i >= e.n {
+		return e.m, false
+	}
+
+	// the set bits with the high bits of i follow the unset bit ending the previous high bits
+	h := i >> e.lowBits
+	k := 0
+	if h > 0 {
+		k = e.high.Select0(h-1) - h + 1
+	}
+	low := i & (1<<e.lowBits - 1)
+	for ; k < e.m && e.high.Get(k+h); k++ {
+		if l := e.getLow(k); l >= low {
+			return k, l == low
+		}
+	}
+	return k, false
+}
+
+// MarshalBinary encodes the vector, it is only meant to be decoded by UnmarshalBinary.
+func (e *EliasFano) MarshalBinary() ([]byte, error) {
+	high, _ := e.high.MarshalBinary()
+	data := make([]byte, 24, 24+8*len(e.low)+len(high))
+	binary.LittleEndian.PutUint64(data, uint64(e.n))
+	binary.LittleEndian.PutUint64(data[8:], uint64(e.m))
+	binary.LittleEndian.PutUint64(data[16:], uint64(e.lowBits))
+	for _, word := range e.low {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+	return append(data, high...), nil
+}
+
+// UnmarshalBinary decodes a vector encoded by MarshalBinary.
+func (e *EliasFano) UnmarshalBinary(data []byte) error {
+	if len(data) < 24 {
+		return ErrInvalidData
+	}
+	n := binary.LittleEndian.Uint64(data)
+	m := binary.LittleEndian.Uint64(data[8:])
+	lowBits := binary.LittleEndian.Uint64(data[16:])
+	if lowBits >= 64 || m > n || m > 8*uint64(len(data)) {
+		return ErrInvalidData
+	}
+
+	words := int((m*lowBits + 63) >> 6)
+	if len(data) < 24+8*words {
+		return ErrInvalidData
+	}
+	x := EliasFano{n: int(n), m: int(m), lowBits: uint(lowBits), low: make([]uint64, words)}
+	for i := range x.low {
+		x.low[i] = binary.LittleEndian.Uint64(data[24+8*i:])
+	}
+	if err := x.high.UnmarshalBinary(data[24+8*words:]); err != nil {
+		return err
+	}
+	if x.high.Ones() != x.m || x.high.Len() != x.m+x.n>>x.lowBits+1 {
+		return ErrInvalidData
+	}
+
+	*e = x
+	return nil
+}
@@ -0,0 +1,206 @@
+package bitvec
+
+import "math/bits"
+
+// The index follows rank9 (Vigna, "Broadword Implementation of Rank/Select Queries"):
+// the words are grouped in blocks of 8, and every block has two counters, the number of set bits
+// before the block and, packed in 9 bits each, the number of set bits in the block before its words 1 to 7.
+// Rank is then two loads and a popcount, at 25% space overhead. The counters are stored right before
+// the words of their block, so that both are in the same or adjacent cache lines.
+//
+// Select samples the block of every 512th set (or unset) bit. The blocks after a sample are scanned,
+// unless the next 512 bits span more than maxSpan blocks, in which case their positions are stored instead,
+// so that select is O(1) in the worst case while costing little on dense vectors.
+const (
+	blockWords  = 8
+	blockStride = 2 + blockWords
+	sampleRate  = 512
+	maxSpan     = 64
+
+	ones9 = 1 | 1<<9 | 1<<18 | 1<<27 | 1<<36 | 1<<45 | 1<<54
+	msbs9 = ones9 << 8
+
+	// zeros9 packs 64j in the field of word j, the number of bits in the block before it
+	zeros9 = 64 | 128<<9 | 192<<18 | 256<<27 | 320<<36 | 384<<45 | 448<<54
+)
+
+type index struct {
+	// blocks[10b] and blocks[10b+1] are the counters of block b, followed by its words,
+	// and the last element is the number of set bits, as the first counter of a trailing block
+	blocks []uint64
+	ones   int
+
+	// samples1[i] is the block of the (512i)th set bit, or if negative, the complement of the offset in spill1
+	// of the positions of the set bits i*512 to i*512+511, samples0 and spill0 being the same for unset bits
+	samples1, samples0 []int32
+	spill1, spill0     []int
+}
+
+// Init builds the rank and select index.
+func (v *Vector) Init() {
+	words := v.Words()
+	blocks := (len(words) + blockWords - 1) / blockWords
+	var x index
+	if v.owned && v.blocks == nil && cap(words) >= indexWords(len(words)) {
+		// the words move to their block from the last one, so that none is overwritten before it moves
+		x.blocks = words[:indexWords(len(words))]
+		for b := blocks - 1; b >= 0; b-- {
+			block := x.blocks[blockStride*b+2:][:blockWords]
+			clear(block[copy(block, words[b*blockWords:min(b*blockWords+blockWords, len(words))]):])
+		}
+	} else {
+		x.blocks = make([]uint64, indexWords(len(words)))
+		for w, word := range words {
+			x.blocks[w/blockWords*blockStride+2+w%blockWords] = word
+		}
+	}
+
+	var ones uint64
+	for b := 0; b < blocks; b++ {
+		block := x.blocks[blockStride*b:][:blockStride]
+		block[0] = ones
+		var rel, sub uint64
+		for j := 0; j < blockWords; j++ {
+			if j > 0 {
+				sub |= rel << (9 * (j - 1))
+			}
+			rel += uint64(bits.OnesCount64(block[2+j]))
+		}
+		block[1] = sub
+		ones += rel
+	}
+	x.blocks[blockStride*blocks] = ones
+	x.ones = int(ones)
+
+	// the words may have moved into the blocks, so the samples are taken on the blocks
+	v.index, v.words = x, nil
+	v.samples1, v.spill1 = v.sample(x.ones, true, func(b int) int {
+		return int(x.blocks[blockStride*b])
+	})
+	v.samples0, v.spill0 = v.sample(v.n-x.ones, false, func(b int) int {
+		return b*blockWords<<6 - int(x.blocks[blockStride*b])
+	})
+}
+
+// indexWords returns the length of the blocks of a vector of n words.
+func indexWords(n int) int {
+	return (n+blockWords-1)/blockWords*blockStride + 1
+}
+
+// sample samples the block of every 512th of the total bits equal to bit, before(b) being the number of them before block b.
+func (v *Vector) sample(total int, bit bool, before func(b int) int) (samples []int32, spill []int) {
+	b := 0
+	blockOf := func(k int) int {
+		for before(b+1) <= k {
+			b++
+		}
+		return b
+	}
+
+	for k := 0; k < total; k += sampleRate {
+		first := blockOf(k)
+		end := min(k+sampleRate, total)
+		if blockOf(end-1)-first <= maxSpan {
+			samples = append(samples, int32(first))
+			continue
+		}
+
+		samples = append(samples, ^int32(len(spill)))
+		rank := before(first)
+		for p := first * blockWords << 6; rank < end; p++ {
+			if v.Get(p) == bit {
+				if rank >= k {
+					spill = append(spill, p)
+				}
+				rank++
+			}
+		}
+	}
+	return
+}
+
+// IndexBytes returns the size in bytes of the rank and select index, which comes on top of the 8 bytes per word.
+func (v *Vector) IndexBytes() int {
+	counters := 0
+	if v.blocks != nil {
+		counters = len(v.blocks) - v.numWords()
+	}
+	return 8*(counters+len(v.spill1)+len(v.spill0)) + 4*(len(v.samples1)+len(v.samples0))
+}
+
+// Ones returns the number of set bits.
+func (v *Vector) Ones() int {
+	return v.ones
+}
+
+// Rank1 returns the number of set bits in [0, i).
+func (v *Vector) Rank1(i int) int {
+	if i >= v.n {
+		return v.ones
+	}
+
+	// for the first word of a block the shift is 63, past all the fields
+	w := i >> 6
+	b := w >> 3 * blockStride
+	sub := v.blocks[b+1] >> ((uint(w) - 1) & 7 * 9) & 0x1ff
+	return int(v.blocks[b]+sub) + bits.OnesCount64(v.blocks[b+2+w&7]&(uint64(1)<<(i&63)-1))
+}
+
+// Rank0 returns the number of unset bits in [0, i).
+func (v *Vector) Rank0(i int) int {
+	return i - v.Rank1(i)
+}
+
+// Select1 returns the position of the kth (starting from 0) set bit, or -1 if there is none.
+func (v *Vector) Select1(k int) int {
+	if k < 0 || v.ones <= k {
+		return -1
+	}
+
+	l := int(v.samples1[uint(k)/sampleRate])
+	if l < 0 {
+		return v.spill1[^l+int(uint(k)%sampleRate)]
+	}
+
+	// the last block starting before the kth set bit, at most maxSpan blocks after the sample
+	for v.blocks[blockStride*(l+1)] <= uint64(k) {
+		l++
+	}
+
+	block := v.blocks[blockStride*l:][:blockStride]
+	rank := uint64(k) - block[0]
+	j := uleq9(block[1], rank*ones9) * ones9 >> 54 & 7
+	rank -= block[1] >> ((j - 1) & 7 * 9) & 0x1ff
+
+	return (l<<3+int(j))<<6 + int(selectWord(block[2+j], uint8(rank)))
+}
+
+// Select0 returns the position of the kth (starting from 0) unset bit, or -1 if there is none.
+func (v *Vector) Select0(k int) int {
+	if k < 0 || v.n-v.ones <= k {
+		return -1
+	}
+
+	l := int(v.samples0[uint(k)/sampleRate])
+	if l < 0 {
+		return v.spill0[^l+int(uint(k)%sampleRate)]
+	}
+
+	for (l+1)*blockWords<<6-int(v.blocks[blockStride*(l+1)]) <= k {
+		l++
+	}
+
+	// the unset bits in the block before its words 1 to 7
+	block := v.blocks[blockStride*l:][:blockStride]
+	sub := zeros9 - block[1]
+	rank := uint64(k - (l*blockWords<<6 - int(block[0])))
+	j := uleq9(sub, rank*ones9) * ones9 >> 54 & 7
+	rank -= sub >> ((j - 1) & 7 * 9) & 0x1ff
+
+	return (l<<3+int(j))<<6 + int(selectWord(^block[2+j], uint8(rank)))
+}
+
+// uleq9 sets the lowest bit of every 9-bit field of the result whose field in x is not greater than in y.
+func uleq9(x, y uint64) uint64 {
+	return ((((y | msbs9) - (x &^ msbs9)) | (x ^ y)) ^ (x &^ y)) & msbs9 >> 8
+}
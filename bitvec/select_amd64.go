@@ -0,0 +1,40 @@
+//go:build amd64 && !purego
+
+package bitvec
+
+// hasPDEP is true if PDEP is available and fast, in which case the kth set bit of a word
+// is found by depositing 1<<k into the word and counting the trailing zeros.
+var hasPDEP = fastPDEP()
+
+func selectPDEP(v uint64, k uint64) uint64
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func fastPDEP() bool {
+	maxID, vendor1, vendor3, vendor2 := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	if _, ebx, _, _ := cpuid(7, 0); ebx&(1<<8) == 0 {
+		return false
+	}
+
+	// AMD implements PDEP in microcode before Zen 3, which is slower than the table lookup
+	if vendor1 == 0x68747541 && vendor2 == 0x69746e65 && vendor3 == 0x444d4163 { // "AuthenticAMD"
+		eax, _, _, _ := cpuid(1, 0)
+		family := eax >> 8 & 0xf
+		if family == 0xf {
+			family += eax >> 20 & 0xff
+		}
+		return family >= 0x19
+	}
+	return true
+}
+
+// selectWord returns the position of the kth (starting from 0) set bit of v.
+func selectWord(v uint64, k uint8) uint8 {
+	if hasPDEP {
+		return uint8(selectPDEP(v, uint64(k)))
+	}
+	return nthSet(v, k)
+}
@@ -0,0 +1,25 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func selectPDEP(v uint64, k uint64) uint64
+TEXT ·selectPDEP(SB), NOSPLIT, $0-24
+	MOVQ   v+0(FP), AX
+	MOVQ   k+8(FP), CX
+	MOVQ   $1, DX
+	SHLQ   CX, DX
+	PDEPQ  AX, DX, DX
+	TZCNTQ DX, DX
+	MOVQ   DX, ret+16(FP)
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
@@ -0,0 +1,8 @@
+//go:build !amd64 || purego
+
+package bitvec
+
+// selectWord returns the position of the kth (starting from 0) set bit of v.
+func selectWord(v uint64, k uint8) uint8 {
+	return nthSet(v, k)
+}
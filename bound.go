@@ -0,0 +1,98 @@
+package sutrie
+
+import "sort"
+
+// Ceiling returns the smallest key in the trie that is greater than or equal to key in byte-lexicographic order,
+// ok is false if there is no such key. On a case-folded trie, key is folded first and compared with the folded keys.
+func (t *SuccinctTrie) Ceiling(key string) (ceiling string, ok bool) {
+	key = t.foldQuery(key)
+	path, i := t.path(key)
+	if i == len(key) && path[i].leaf {
+		return key, true
+	}
+
+	buf := []byte(key[:i])
+	for d := i; d >= 0; d-- {
+		n := path[d]
+		k := n.firstChild
+		if d < len(key) {
+			k = n.lowerBound(key[d], true)
+		}
+		if k < n.afterLastChild {
+			buf = append(buf[:d], t.label(k))
+			return string(appendMin(buf, n.next(k))), true
+		}
+	}
+	return "", false
+}
+
+// Floor returns the largest key in the trie that is less than or equal to key in byte-lexicographic order,
+// ok is false if there is no such key, see Ceiling for case-folded tries.
+func (t *SuccinctTrie) Floor(key string) (floor string, ok bool) {
+	key = t.foldQuery(key)
+	path, i := t.path(key)
+	if i == len(key) && path[i].leaf {
+		return key, true
+	}
+
+	buf := []byte(key[:i])
+	for d := min(i, len(key)-1); d >= 0; d-- {
+		n := path[d]
+		if k := n.lowerBound(key[d], false) - 1; k >= n.firstChild {
+			buf = append(buf[:d], t.label(k))
+			return string(appendMax(buf, n.next(k))), true
+		}
+		if n.leaf {
+			return key[:d], true
+		}
+	}
+	return "", false
+}
+
+// foldQuery returns key folded as the keys of the trie are.
+func (t *SuccinctTrie) foldQuery(key string) string {
+	if t.fold == foldNone {
+		return key
+	}
+	return foldKey(key, t.fold)
+}
+
+// path returns the nodes along the longest prefix of key in the trie, path[i] being the node of key[:i].
+func (t *SuccinctTrie) path(key string) (path []Node, i int) {
+	n := t.Root()
+	path = append(make([]Node, 0, len(key)+1), n)
+	for ; i < len(key); i++ {
+		if n = n.Next(key[i]); !n.Exists() {
+			break
+		}
+		path = append(path, n)
+	}
+	return
+}
+
+// lowerBound returns the index of the first child whose label is not less than b,
+// or greater than b if strict is true.
+func (n Node) lowerBound(b byte, strict bool) int32 {
+	return n.firstChild + int32(sort.Search(n.Size(), func(i int) bool {
+		label := n.trie.label(n.firstChild + int32(i))
+		return label > b || !strict && label == b
+	}))
+}
+
+// appendMin appends the smallest key under n to buf.
+func appendMin(buf []byte, n Node) []byte {
+	for !n.leaf && n.firstChild < n.afterLastChild {
+		buf = append(buf, n.trie.label(n.firstChild))
+		n = n.next(n.firstChild)
+	}
+	return buf
+}
+
+// appendMax appends the largest key under n to buf.
+func appendMax(buf []byte, n Node) []byte {
+	for n.firstChild < n.afterLastChild {
+		buf = append(buf, n.trie.label(n.afterLastChild-1))
+		n = n.next(n.afterLastChild - 1)
+	}
+	return buf
+}
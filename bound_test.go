@@ -0,0 +1,123 @@
+package sutrie
+
+import (
+	mrand "math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCeilingFloor(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"b", "bat", "bath", "cat", "d"})
+
+	for key, want := range map[string]string{
+		"":      "b",
+		"a":     "b",
+		"b":     "b",
+		"ba":    "bat",
+		"bata":  "bath",
+		"bati":  "cat",
+		"c":     "cat",
+		"cat":   "cat",
+		"catz":  "d",
+		"d":     "d",
+		"\xff":  "",
+		"dd":    "",
+		"bath0": "cat",
+	} {
+		ceiling, ok := trie.Ceiling(key)
+		assert.Equal(t, want != "", ok, key)
+		assert.Equal(t, want, ceiling, key)
+	}
+
+	for key, want := range map[string]string{
+		"":      "",
+		"a":     "",
+		"b":     "b",
+		"ba":    "b",
+		"bata":  "bat",
+		"bati":  "bath",
+		"c":     "bath",
+		"cat":   "cat",
+		"catz":  "cat",
+		"d":     "d",
+		"\xff":  "d",
+		"dd":    "d",
+		"bath0": "bath",
+	} {
+		floor, ok := trie.Floor(key)
+		assert.Equal(t, want != "", ok, key)
+		assert.Equal(t, want, floor, key)
+	}
+
+	// queries are folded as the keys of folded tries
+	folded := BuildSuccinctTrie([]string{"bar", "foo", "éte"}, WithCaseFolding())
+	for key, want := range map[string][2]string{
+		"BAR": {"bar", "bar"},
+		"C":   {"foo", "bar"},
+		"Fo":  {"foo", "bar"},
+		"FOO": {"foo", "foo"},
+		"FOP": {"éte", "foo"},
+	} {
+		ceiling, _ := folded.Ceiling(key)
+		floor, _ := folded.Floor(key)
+		assert.Equal(t, want, [2]string{ceiling, floor}, key)
+	}
+	unicodeFolded := BuildSuccinctTrie([]string{"bar", "éte", "ézz"}, WithUnicodeCaseFolding())
+	ceiling, ok := unicodeFolded.Ceiling("ÉTE")
+	assert.True(t, ok)
+	assert.Equal(t, "éte", ceiling)
+	ceiling, _ = unicodeFolded.Ceiling("ÉU")
+	assert.Equal(t, "ézz", ceiling)
+	floor, _ := unicodeFolded.Floor("ÉU")
+	assert.Equal(t, "éte", floor)
+
+	empty := BuildSuccinctTrie(nil)
+	_, ok = empty.Ceiling("a")
+	assert.False(t, ok)
+	_, ok = empty.Floor("a")
+	assert.False(t, ok)
+}
+
+func TestRandomCeilingFloor(t *testing.T) {
+	const l = 2000
+	alphabet := "abc"
+	randomKey := func() string {
+		b := make([]byte, 1+mrand.Intn(6))
+		for i := range b {
+			b[i] = alphabet[mrand.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomKey()
+	}
+	trie := BuildSuccinctTrie(dict)
+
+	sorted := append([]string(nil), dict...)
+	sort.Strings(sorted)
+
+	for i := 0; i < l; i++ {
+		key := randomKey()
+		j := sort.SearchStrings(sorted, key)
+
+		ceiling, ok := trie.Ceiling(key)
+		assert.Equal(t, j < len(sorted), ok)
+		if ok {
+			assert.Equal(t, sorted[j], ceiling)
+		}
+
+		floor, ok := trie.Floor(key)
+		if j < len(sorted) && sorted[j] == key {
+			assert.Equal(t, key, floor)
+		} else {
+			assert.Equal(t, j > 0, ok)
+			if ok {
+				assert.Equal(t, sorted[j-1], floor)
+			}
+		}
+	}
+}
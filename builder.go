@@ -0,0 +1,41 @@
+package sutrie
+
+import "github.com/nobekanai/sutrie/bitvec"
+
+// Builder builds tries with the same options, reusing the memory of the tries handed back with Reset,
+// so that services rebuilding a trie periodically allocate less and cause shorter GC pauses.
+// The bitmaps and their indexes, the dense layout and the scratch space of the build are reused,
+// but not the labels, which are an immutable string. A Builder is not safe for concurrent use.
+type Builder struct {
+	opts  []Option
+	spare buildBuffers
+}
+
+// buildBuffers is the memory of a retired trie and of the previous build, see Builder.
+type buildBuffers struct {
+	bitmap, leaves, dense bitvec.Vector
+	denseBits             []uint64
+	scratch               []uint64
+}
+
+// NewBuilder returns a Builder of tries built with opts.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{opts: opts}
+}
+
+// Build is like Build with the options of b, reusing the memory given back with Reset.
+func (b *Builder) Build(dict []string) (*SuccinctTrie, error) {
+	opts := append(b.opts[:len(b.opts):len(b.opts)], func(o *buildOptions) error {
+		o.spare = &b.spare
+		return nil
+	})
+	return Build(dict, opts...)
+}
+
+// Reset hands t back to b, whose next Build reuses its memory. t is emptied and neither it nor its nodes
+// may be used anymore, which usually means waiting for the queries started before its replacement to finish.
+func (b *Builder) Reset(t *SuccinctTrie) {
+	b.spare.bitmap, b.spare.leaves = t.bitmap, t.leaves
+	b.spare.dense, b.spare.denseBits = t.dense, t.denseBits
+	*t = SuccinctTrie{}
+}
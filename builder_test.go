@@ -0,0 +1,67 @@
+package sutrie
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	dict := domainKeys(20000)
+	b := NewBuilder(WithReversedKeys())
+
+	var prev *SuccinctTrie
+	for i, keys := range [][]string{dict, dict[:19000], dict[500:], {}, dict[:100], dict} {
+		plain := BuildSuccinctTrie(append([]string{}, keys...), WithReversedKeys())
+		trie, err := b.Build(append([]string{}, keys...))
+		assert.NoError(t, err)
+		assert.True(t, plain.Equal(trie), i)
+		assert.Equal(t, plain.Keys(), trie.Keys(), i)
+		assert.Equal(t, plain.MemStats().Dense, trie.MemStats().Dense, i)
+		for _, key := range keys[:min(len(keys), 100)] {
+			assert.True(t, trie.Root().Search(reverseKeys([]string{key})[0]).Leaf())
+		}
+		if prev != nil {
+			b.Reset(prev)
+			assert.Equal(t, 0, prev.Size())
+		}
+		prev = trie
+	}
+
+	allocated := func(fn func()) uint64 {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		fn()
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	keys := append([]string(nil), dict...)
+	fresh := allocated(func() { Build(keys) })
+	b = NewBuilder()
+	old, _ := b.Build(keys)
+	b.Reset(old)
+	reused := allocated(func() { b.Build(keys) })
+	assert.Less(t, reused, fresh*3/4)
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	dict := domainKeys(200000)
+	keys := make([]string, len(dict))
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(keys, dict)
+			Build(keys)
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+		builder := NewBuilder()
+		for i := 0; i < b.N; i++ {
+			copy(keys, dict)
+			t, _ := builder.Build(keys)
+			builder.Reset(t)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package sutrie
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+)
+
+// A bundle starts with bundleMagic and is followed by the tries, written by MarshalSections, the index
+// of their names, offsets, lengths and checksums, and a footer locating the index, ending with bundleMagic.
+const (
+	bundleMagic      = "SUTRIEBN"
+	bundleFooterSize = 8 + 8 + 4 + len(bundleMagic)
+)
+
+// ErrInvalidBundle is returned when the input is not a bundle or is corrupt.
+var ErrInvalidBundle = errors.New("sutrie: invalid bundle")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type bundleEntry struct {
+	name           string
+	offset, length int64
+	crc            uint32
+}
+
+// BundleWriter writes several named tries to a single file, see Bundle. The tries are streamed,
+// and their checksums computed on the fly.
+type BundleWriter struct {
+	w       *countingWriter
+	entries []bundleEntry
+	names   map[string]bool
+	err     error
+}
+
+// NewBundleWriter returns a BundleWriter writing to w, which must be closed to write the index.
+func NewBundleWriter(w io.Writer) *BundleWriter {
+	bw := &BundleWriter{w: &countingWriter{w: w}, names: make(map[string]bool)}
+	_, bw.err = io.WriteString(bw.w, bundleMagic)
+	return bw
+}
+
+// Add writes t named name. Names must be unique and at most 65535 bytes long.
+func (bw *BundleWriter) Add(name string, t *SuccinctTrie) error {
+	if bw.err != nil {
+		return bw.err
+	}
+	if name == "" || len(name) > 0xffff || bw.names[name] {
+		return fmt.Errorf("sutrie: invalid or duplicate bundle name %q", name)
+	}
+
+	crc := crc32.New(crcTable)
+	e := bundleEntry{name: name, offset: bw.w.n}
+	if bw.err = t.MarshalSections(io.MultiWriter(bw.w, crc)); bw.err != nil {
+		return bw.err
+	}
+	e.length, e.crc = bw.w.n-e.offset, crc.Sum32()
+
+	bw.entries = append(bw.entries, e)
+	bw.names[name] = true
+	return nil
+}
+
+// Close writes the index of the tries, it does not close the underlying writer.
+func (bw *BundleWriter) Close() error {
+	if bw.err != nil {
+		return bw.err
+	}
+
+	index := binary.LittleEndian.AppendUint32(nil, uint32(len(bw.entries)))
+	for _, e := range bw.entries {
+		index = binary.LittleEndian.AppendUint16(index, uint16(len(e.name)))
+		index = append(index, e.name...)
+		index = binary.LittleEndian.AppendUint64(index, uint64(e.offset))
+		index = binary.LittleEndian.AppendUint64(index, uint64(e.length))
+		index = binary.LittleEndian.AppendUint32(index, e.crc)
+	}
+
+	footer := binary.LittleEndian.AppendUint64(nil, uint64(bw.w.n))
+	footer = binary.LittleEndian.AppendUint64(footer, uint64(len(index)))
+	footer = binary.LittleEndian.AppendUint32(footer, crc32.Checksum(index, crcTable))
+	footer = append(footer, bundleMagic...)
+
+	if _, bw.err = bw.w.Write(append(index, footer...)); bw.err != nil {
+		return bw.err
+	}
+	bw.err = errors.New("sutrie: bundle writer closed")
+	return nil
+}
+
+// Bundle is a file of several named tries written by BundleWriter, like the block and allow lists
+// of a filter, which are deployed together. Only the index is read when it is opened, the tries are loaded
+// by name, and a checksum of every trie and of the index guards against corrupt or mismatched parts.
+type Bundle struct {
+	r       io.ReaderAt
+	entries map[string]bundleEntry
+}
+
+// OpenBundle reads the index of the bundle of size bytes read from r, the tries are read from r
+// when loaded so r must stay valid as long as the Bundle is used.
+func OpenBundle(r io.ReaderAt, size int64) (*Bundle, error) {
+	if size < int64(len(bundleMagic)+bundleFooterSize) {
+		return nil, ErrInvalidBundle
+	}
+
+	footer := make([]byte, bundleFooterSize)
+	if _, err := r.ReadAt(footer, size-int64(bundleFooterSize)); err != nil {
+		return nil, err
+	}
+	if string(footer[20:]) != bundleMagic {
+		return nil, ErrInvalidBundle
+	}
+	offset := int64(binary.LittleEndian.Uint64(footer))
+	length := int64(binary.LittleEndian.Uint64(footer[8:]))
+	if offset < int64(len(bundleMagic)) || length < 4 || offset+length != size-int64(bundleFooterSize) {
+		return nil, ErrInvalidBundle
+	}
+
+	index := make([]byte, length)
+	if _, err := r.ReadAt(index, offset); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(index, crcTable) != binary.LittleEndian.Uint32(footer[16:]) {
+		return nil, fmt.Errorf("%w: index checksum mismatch", ErrInvalidBundle)
+	}
+
+	b := &Bundle{r: r, entries: make(map[string]bundleEntry)}
+	n, index := binary.LittleEndian.Uint32(index), index[4:]
+	for i := uint32(0); i < n; i++ {
+		if len(index) < 2 {
+			return nil, ErrInvalidBundle
+		}
+		l := int(binary.LittleEndian.Uint16(index))
+		if len(index) < 2+l+20 {
+			return nil, ErrInvalidBundle
+		}
+
+		e := bundleEntry{name: string(index[2 : 2+l])}
+		index = index[2+l:]
+		e.offset = int64(binary.LittleEndian.Uint64(index))
+		e.length = int64(binary.LittleEndian.Uint64(index[8:]))
+		e.crc = binary.LittleEndian.Uint32(index[16:])
+		index = index[20:]
+
+		if e.offset < int64(len(bundleMagic)) || e.length < 0 || e.offset+e.length > offset {
+			return nil, ErrInvalidBundle
+		}
+		b.entries[e.name] = e
+	}
+	return b, nil
+}
+
+// Names returns the names of the tries of the bundle in lexicographic order.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.entries))
+	for name := range b.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads the trie named name, checking its checksum. ok is false if there is no such trie.
+func (b *Bundle) Load(name string) (t *SuccinctTrie, ok bool, err error) {
+	e, ok := b.entries[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	crc := crc32.New(crcTable)
+	r := io.TeeReader(io.NewSectionReader(b.r, e.offset, e.length), crc)
+	t = new(SuccinctTrie)
+	if err := t.Unmarshal(r); err != nil {
+		return nil, true, fmt.Errorf("%w: trie %q: %v", ErrInvalidBundle, name, err)
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, true, err
+	}
+	if crc.Sum32() != e.crc {
+		return nil, true, fmt.Errorf("%w: trie %q checksum mismatch", ErrInvalidBundle, name)
+	}
+	return t, true, nil
+}
+
+// Verify checks the checksums of all the tries of the bundle without loading them.
+func (b *Bundle) Verify() error {
+	for _, name := range b.Names() {
+		e := b.entries[name]
+		crc := crc32.New(crcTable)
+		if _, err := io.Copy(crc, io.NewSectionReader(b.r, e.offset, e.length)); err != nil {
+			return err
+		}
+		if crc.Sum32() != e.crc {
+			return fmt.Errorf("%w: trie %q checksum mismatch", ErrInvalidBundle, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	block := BuildSuccinctTrie([]string{"ads.example.com", "tracker.example.net"}, WithReversedKeys())
+	allow := BuildSuccinctTrie([]string{"good.ads.example.com"}, WithReversedKeys(), WithEliasFanoLeaves())
+	empty := BuildSuccinctTrie([]string{})
+
+	var buf bytes.Buffer
+	bw := NewBundleWriter(&buf)
+	assert.NoError(t, bw.Add("block", block))
+	assert.NoError(t, bw.Add("allow", allow))
+	assert.NoError(t, bw.Add("empty", empty))
+	assert.Error(t, bw.Add("block", block))
+	assert.Error(t, bw.Add("", block))
+	assert.NoError(t, bw.Close())
+	assert.Error(t, bw.Add("late", block))
+	data := buf.Bytes()
+
+	b, err := OpenBundle(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"allow", "block", "empty"}, b.Names())
+	assert.NoError(t, b.Verify())
+
+	for name, want := range map[string]*SuccinctTrie{"block": block, "allow": allow, "empty": empty} {
+		got, ok, err := b.Load(name)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, want.Equal(got), name)
+	}
+	got, ok, err := b.Load("missing")
+	assert.Nil(t, got)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	// a flipped byte in a trie is caught by its checksum
+	corrupt := append([]byte(nil), data...)
+	corrupt[b.entries["block"].offset+b.entries["block"].length-12] ^= 1
+	cb, err := OpenBundle(bytes.NewReader(corrupt), int64(len(corrupt)))
+	assert.NoError(t, err)
+	_, _, err = cb.Load("block")
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	assert.ErrorIs(t, cb.Verify(), ErrInvalidBundle)
+	_, _, err = cb.Load("allow")
+	assert.NoError(t, err)
+
+	// and in the index by the index checksum
+	corrupt = append([]byte(nil), data...)
+	corrupt[len(corrupt)-bundleFooterSize-3] ^= 1
+	_, err = OpenBundle(bytes.NewReader(corrupt), int64(len(corrupt)))
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	_, err = OpenBundle(bytes.NewReader(data[:len(data)-1]), int64(len(data)-1))
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = OpenBundle(bytes.NewReader(nil), 0)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	buf.Reset()
+	assert.NoError(t, NewBundleWriter(&buf).Close())
+	b, err = OpenBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Empty(t, b.Names())
+}
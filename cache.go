@@ -0,0 +1,107 @@
+package sutrie
+
+import "sync"
+
+// Cache memoizes the Contains and SearchPrefix queries of a Searcher in a fixed number of slots,
+// for skewed workloads like DNS filtering where a few hot names make most of the queries.
+// Slots are evicted with the CLOCK algorithm, an approximation of LRU which only marks a slot on a hit.
+// It is safe for concurrent use, provided the Searcher does not change: an Overlay should not be cached.
+type Cache struct {
+	s Searcher
+
+	mu    sync.Mutex
+	slots []cacheSlot
+	index map[cacheKey]int // the slot of every cached query
+	hand  int              // the next slot to consider for eviction
+
+	hits, misses uint64
+}
+
+type cacheKey struct {
+	key    string
+	prefix bool // SearchPrefix, or else Contains
+}
+
+type cacheSlot struct {
+	cacheKey
+	value int // the result of SearchPrefix, or 1 if Contains
+	used  bool
+}
+
+var _ Searcher = (*Cache)(nil)
+
+// NewCache returns a cache of the last size queries of s, it panics if size is not positive.
+func NewCache(s Searcher, size int) *Cache {
+	if size <= 0 {
+		panic("sutrie: cache size must be positive")
+	}
+	return &Cache{s: s, slots: make([]cacheSlot, 0, size), index: make(map[cacheKey]int, size)}
+}
+
+// Contains is like the Contains of the cached Searcher.
+func (c *Cache) Contains(key string) bool {
+	return c.query(cacheKey{key, false}) == 1
+}
+
+// SearchPrefix is like the SearchPrefix of the cached Searcher.
+func (c *Cache) SearchPrefix(key string) int {
+	return c.query(cacheKey{key, true})
+}
+
+// Size returns the number of keys of the cached Searcher.
+func (c *Cache) Size() int {
+	return c.s.Size()
+}
+
+// Stats returns the number of queries answered from the cache and by the cached Searcher.
+func (c *Cache) Stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *Cache) query(k cacheKey) int {
+	c.mu.Lock()
+	if i, ok := c.index[k]; ok {
+		c.slots[i].used = true
+		c.hits++
+		value := c.slots[i].value
+		c.mu.Unlock()
+		return value
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// not holding the lock while searching, two goroutines may then miss the same key
+	var value int
+	if k.prefix {
+		value = c.s.SearchPrefix(k.key)
+	} else if c.s.Contains(k.key) {
+		value = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.index[k]; !ok {
+		c.index[k] = c.evict()
+		c.slots[c.index[k]] = cacheSlot{cacheKey: k, value: value}
+	}
+	return value
+}
+
+// evict returns a free slot, evicting the first unused one from the hand on if all are taken.
+func (c *Cache) evict() int {
+	if len(c.slots) < cap(c.slots) {
+		c.slots = c.slots[:len(c.slots)+1]
+		return len(c.slots) - 1
+	}
+
+	for c.slots[c.hand].used {
+		c.slots[c.hand].used = false
+		c.hand = (c.hand + 1) % len(c.slots)
+	}
+	i := c.hand
+	delete(c.index, c.slots[i].cacheKey)
+	c.hand = (c.hand + 1) % len(c.slots)
+	return i
+}
@@ -0,0 +1,88 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	var dict []string
+	for i := 0; i < 1000; i++ {
+		dict = append(dict, fmt.Sprintf("%x", i*7))
+	}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	for _, s := range []Searcher{trie, BuildDoubleArrayTrie(dict)} {
+		c := NewCache(s, 64)
+		assert.Equal(t, s.Size(), c.Size())
+
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 20000; i++ {
+			// a skewed distribution, most queries in a small hot set
+			n := rnd.Intn(20)
+			if rnd.Intn(10) == 0 {
+				n = rnd.Intn(10000)
+			}
+			key := fmt.Sprintf("%x", n)
+			assert.Equal(t, s.Contains(key), c.Contains(key), key)
+			assert.Equal(t, s.SearchPrefix(key+"z"), c.SearchPrefix(key+"z"), key)
+		}
+
+		hits, misses := c.Stats()
+		assert.Equal(t, uint64(40000), hits+misses)
+		assert.Greater(t, hits, misses)
+		assert.Len(t, c.index, 64)
+		assert.Len(t, c.slots, 64)
+		for i, slot := range c.slots {
+			assert.Equal(t, i, c.index[slot.cacheKey])
+		}
+	}
+
+	assert.Panics(t, func() { NewCache(trie, 0) })
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := NewCache(BuildSuccinctTrie([]string{"a", "b", "c"}), 2)
+
+	c.Contains("a")
+	c.Contains("b")
+	c.Contains("a") // marks a as used
+	c.Contains("c") // evicts b, clearing a
+	assert.Contains(t, c.index, cacheKey{"a", false})
+	assert.Contains(t, c.index, cacheKey{"c", false})
+	assert.NotContains(t, c.index, cacheKey{"b", false})
+
+	// the same key as another query is cached on its own
+	assert.Equal(t, 1, c.SearchPrefix("a"))
+	assert.Contains(t, c.index, cacheKey{"a", true})
+	hits, misses := c.Stats()
+	assert.Equal(t, uint64(1), hits)
+	assert.Equal(t, uint64(4), misses)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"example.com", "example.org", "test.net"})
+	c := NewCache(trie, 4)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := []string{"example.com", "example.org", "test.net", "example.net", "test"}[(g+i)%5]
+				assert.Equal(t, trie.Contains(key), c.Contains(key))
+				assert.Equal(t, trie.SearchPrefix(key+".x"), c.SearchPrefix(key+".x"))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	hits, misses := c.Stats()
+	assert.Equal(t, uint64(16000), hits+misses)
+	assert.LessOrEqual(t, len(c.index), 4)
+}
@@ -0,0 +1,52 @@
+package sutrie
+
+// ChildAt returns the label and the node of the i-th child of the current node in label order,
+// that is Children()[i] and Next(Children()[i]). It panics if i is out of range [0, Size()).
+func (n Node) ChildAt(i int) (byte, Node) {
+	if i < 0 || i >= n.Size() {
+		panic("sutrie: child index out of range")
+	}
+	k := n.firstChild + int32(i)
+	return n.trie.label(k), n.trie.node(k)
+}
+
+// ChildrenIter iterates over the children of a node in label order, see Node.ChildrenIter.
+type ChildrenIter struct {
+	parent Node
+	k      int32 // the next child
+	pos    int32 // the position of the one bit before the children of k, -1 before the first call to Next
+}
+
+// ChildrenIter returns an iterator over the children of the current node:
+//
+//	it := n.ChildrenIter()
+//	for b, child, ok := it.Next(); ok; b, child, ok = it.Next() {
+//		...
+//	}
+//
+// Like ExpandAll, it decodes the children in a single pass over the bitmap, which is cheaper than ChildAt.
+func (n Node) ChildrenIter() ChildrenIter {
+	return ChildrenIter{parent: n, k: n.firstChild, pos: -1}
+}
+
+// Next returns the label and the node of the next child, ok is false when there are no more children.
+func (it *ChildrenIter) Next() (b byte, child Node, ok bool) {
+	if it.k >= it.parent.afterLastChild {
+		return 0, Node{}, false
+	}
+
+	t, k := it.parent.trie, it.k
+	if it.pos < 0 {
+		it.pos = int32(t.bitmap.Select1(int(k)))
+	}
+	next := int32(t.bitmap.NextOne(int(it.pos) + 1))
+	child = Node{
+		index:          k,
+		firstChild:     it.pos - k,
+		afterLastChild: next - k - 1,
+		leaf:           t.isLeaf(k),
+		trie:           t,
+	}
+	it.k, it.pos = k+1, next
+	return t.label(k), child, true
+}
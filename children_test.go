@@ -0,0 +1,52 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChildAt(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "中文"})
+	root := trie.Root()
+
+	for i := 0; i < root.Size(); i++ {
+		b, child := root.ChildAt(i)
+		assert.Equal(t, root.Children()[i], b)
+		assert.Equal(t, root.Next(b), child)
+	}
+	b, child := root.Next('i').ChildAt(1)
+	assert.Equal(t, byte('t'), b)
+	assert.Equal(t, "it", child.Key())
+	assert.True(t, child.Leaf())
+
+	assert.Panics(t, func() { root.ChildAt(-1) })
+	assert.Panics(t, func() { root.ChildAt(root.Size()) })
+	assert.Panics(t, func() { child.ChildAt(0) })
+}
+
+func TestChildrenIter(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "中文"})
+
+	// rebuilding the keys from the public API only
+	var keys []string
+	var visit func(n Node, key string)
+	visit = func(n Node, key string) {
+		if n.Leaf() {
+			keys = append(keys, key)
+		}
+		it := n.ChildrenIter()
+		for b, child, ok := it.Next(); ok; b, child, ok = it.Next() {
+			assert.Equal(t, n.Next(b).Key(), child.Key())
+			assert.Equal(t, n.Next(b).Children(), child.Children())
+			assert.Equal(t, n.Next(b).Leaf(), child.Leaf())
+			visit(child, key+string([]byte{b}))
+		}
+	}
+	visit(trie.Root(), "")
+	assert.Equal(t, trie.Keys(), keys)
+
+	it := Node{}.ChildrenIter()
+	_, _, ok := it.Next()
+	assert.False(t, ok)
+}
@@ -0,0 +1,175 @@
+package sutrie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseClashRules returns the domain rules of a Clash rule provider, in the YAML format with a "payload" list
+// or as plain text, and of either behavior: "classical" rules "DOMAIN,example.com" and "DOMAIN-SUFFIX,example.com",
+// which are also the rules of Surge rule sets, or "domain" entries "example.com" and "+.example.com".
+// They are returned as domain set rules, see ParseSurgeDomainSet. Rules which are not of a domain or
+// of all of its subdomains, like "DOMAIN-KEYWORD", "IP-CIDR" or "*.example.com", are skipped,
+// as well as comments from "#" and blank lines.
+func ParseClashRules(r io.Reader) ([]string, error) {
+	var rules []string
+	err := scanLines(r, func(n int, line string) error {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "payload:" {
+			return nil
+		}
+		if item, ok := strings.CutPrefix(line, "-"); ok {
+			line = strings.Trim(strings.TrimSpace(item), `'"`)
+		}
+		if line == "" {
+			return nil
+		}
+
+		kind, value, classical := strings.Cut(line, ",")
+		suffix := false
+		switch {
+		case classical && kind == "DOMAIN":
+		case classical && kind == "DOMAIN-SUFFIX":
+			suffix = true
+		case classical:
+			return nil
+		case strings.HasPrefix(line, "+."):
+			value, suffix = line[2:], true
+		case strings.HasPrefix(line, ".") || strings.HasPrefix(line, "*"):
+			return nil // subdomains only
+		default:
+			value = line
+		}
+
+		// a rule of a rule set may be followed by options, like "DOMAIN,example.com,no-resolve"
+		value, _, _ = strings.Cut(value, ",")
+		domain, ok := cleanDomain(strings.TrimSpace(value))
+		if !ok {
+			return fmt.Errorf("sutrie: line %d: invalid domain rule %q", n, line)
+		}
+		if suffix {
+			domain = "." + domain
+		}
+		rules = append(rules, domain)
+		return nil
+	})
+	return rules, err
+}
+
+// ParseSurgeDomainSet returns the rules of a Surge domain-set file, one per line, lower-cased: a domain
+// "example.com" matches itself only, and a domain with a leading dot ".example.com" matches itself and
+// its subdomains. Comments from "#" and blank lines are skipped. Such domain set rules are the format
+// the rules of Clash and Surge are converted to, see BuildDomainSet and MatchDomainSet.
+func ParseSurgeDomainSet(r io.Reader) ([]string, error) {
+	var rules []string
+	err := scanLines(r, func(n int, line string) error {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return nil
+		}
+
+		domain, ok := cleanDomain(strings.TrimPrefix(line, "."))
+		if !ok {
+			return fmt.Errorf("sutrie: line %d: invalid domain rule %q", n, line)
+		}
+		if line[0] == '.' {
+			domain = "." + domain
+		}
+		rules = append(rules, domain)
+		return nil
+	})
+	return rules, err
+}
+
+// BuildDomainSet builds a trie of domain set rules, see ParseSurgeDomainSet, WithReversedKeys,
+// query it with MatchDomainSet.
+func BuildDomainSet(rules []string, opts ...Option) (*SuccinctTrie, error) {
+	return buildDomains(copyKeys(rules), opts)
+}
+
+// MatchDomainSet reports whether host matches a rule of the trie, whose keys are domain set rules:
+// it is the domain of a rule, or a subdomain of the domain of a rule with a leading dot.
+// Like MatchDomainSuffix, it does not allocate when the trie was built WithReversedKeys, unless its normalizer does.
+func (t *SuccinctTrie) MatchDomainSet(host string) bool {
+	if t.normalize != nil {
+		norm, buf := normalized(t, host)
+		defer normBuffers.Put(buf)
+		return matchDomainSet(t, norm)
+	}
+	return matchDomainSet(t, host)
+}
+
+func matchDomainSet[K string | []byte](t *SuccinctTrie, host K) bool {
+	if !t.reversed {
+		root := t.Root()
+		if search(root, host).Leaf() || search(root.Next('.'), host).Leaf() {
+			return true
+		}
+		for i := 0; i < len(host); i++ {
+			if host[i] == '.' && search(root, host[i:]).Leaf() {
+				return true
+			}
+		}
+		return false
+	}
+
+	n := t.Root()
+	for i := len(host) - 1; i >= 0; i-- {
+		if n = n.Next(host[i]); !n.Exists() {
+			return false
+		}
+		if n.leaf && host[i] == '.' {
+			return true
+		}
+	}
+	return n.leaf || n.Next('.').leaf
+}
+
+// WriteClashRules writes the domain set rules of the trie as a Clash rule provider of the "classical" behavior,
+// in the YAML format, see ParseClashRules.
+func (t *SuccinctTrie) WriteClashRules(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("payload:\n")
+	err := t.walkRules(func(rule string) error {
+		if domain, ok := strings.CutPrefix(rule, "."); ok {
+			_, err := fmt.Fprintf(bw, "  - DOMAIN-SUFFIX,%s\n", domain)
+			return err
+		}
+		_, err := fmt.Fprintf(bw, "  - DOMAIN,%s\n", rule)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteSurgeDomainSet writes the domain set rules of the trie as a Surge domain-set file, one rule per line.
+func (t *SuccinctTrie) WriteSurgeDomainSet(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	err := t.walkRules(func(rule string) error {
+		bw.WriteString(rule)
+		return bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// walkRules calls fn with the keys of the trie in their natural order, see WithReversedKeys.
+func (t *SuccinctTrie) walkRules(fn func(rule string) error) error {
+	return t.Walk(func(key string, n Node) error {
+		if !n.leaf {
+			return nil
+		}
+		if t.reversed {
+			key = reverse(key)
+		}
+		return fn(key)
+	})
+}
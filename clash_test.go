@@ -0,0 +1,88 @@
+package sutrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClashRules(t *testing.T) {
+	yaml := `payload:
+  # classical
+  - DOMAIN-SUFFIX,Example.com
+  - 'DOMAIN,exact.example.org'
+  - "DOMAIN,tracker.example.net,no-resolve"
+  - DOMAIN-KEYWORD,ads
+  - IP-CIDR,10.0.0.0/8,no-resolve
+  # domain behavior
+  - '+.ads.example.net'
+  - plain.example.io
+  - '.sub.example.io'
+  - '*.one.example.io'
+`
+	rules, err := ParseClashRules(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".example.com", "exact.example.org", "tracker.example.net", ".ads.example.net", "plain.example.io"}, rules)
+
+	text, err := ParseClashRules(strings.NewReader("DOMAIN-SUFFIX,example.com\nDOMAIN,exact.example.org\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".example.com", "exact.example.org"}, text)
+
+	_, err = ParseClashRules(strings.NewReader("payload:\n  - DOMAIN,a..b\n"))
+	assert.Error(t, err)
+
+	for _, opts := range [][]Option{nil, {WithEliasFanoLeaves()}} {
+		trie, err := BuildDomainSet(rules, opts...)
+		assert.NoError(t, err)
+		checkDomainSet(t, trie)
+		checkDomainSet(t, BuildSuccinctTrie(append([]string(nil), rules...)))
+
+		var out bytes.Buffer
+		assert.NoError(t, trie.WriteClashRules(&out))
+		assert.True(t, strings.HasPrefix(out.String(), "payload:\n  - DOMAIN"))
+		again, err := ParseClashRules(&out)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, rules, again)
+	}
+}
+
+func TestSurgeDomainSet(t *testing.T) {
+	rules, err := ParseSurgeDomainSet(strings.NewReader("# list\n.example.com\nexact.example.org\n\ntracker.example.net\n.ads.example.net\nplain.example.io\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".example.com", "exact.example.org", "tracker.example.net", ".ads.example.net", "plain.example.io"}, rules)
+
+	_, err = ParseSurgeDomainSet(strings.NewReader("..example.com\n"))
+	assert.Error(t, err)
+
+	trie, err := BuildDomainSet(rules)
+	assert.NoError(t, err)
+	checkDomainSet(t, trie)
+
+	var out bytes.Buffer
+	assert.NoError(t, trie.WriteSurgeDomainSet(&out))
+	again, err := ParseSurgeDomainSet(&out)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, rules, again)
+}
+
+func checkDomainSet(t *testing.T, trie *SuccinctTrie) {
+	for host, match := range map[string]bool{
+		"example.com":           true,
+		"www.example.com":       true,
+		"badexample.com":        false,
+		"exact.example.org":     true,
+		"www.exact.example.org": false,
+		"example.org":           false,
+		"tracker.example.net":   true,
+		"ads.example.net":       true,
+		"x.y.ads.example.net":   true,
+		"plain.example.io":      true,
+		"sub.example.io":        false,
+		"x.sub.example.io":      false,
+		"":                      false,
+	} {
+		assert.Equal(t, match, trie.MatchDomainSet(host), host)
+	}
+}
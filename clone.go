@@ -0,0 +1,63 @@
+package sutrie
+
+import "strings"
+
+// Clone returns a deep copy of the trie, its slices having no spare capacity.
+func (t *SuccinctTrie) Clone() *SuccinctTrie {
+	t.Warmup()
+	c := *t
+	c.lazy = nil
+	c.bitmap = *t.bitmap.Clone()
+	c.leaves = *t.leaves.Clone()
+	if t.sparseLeaves != nil {
+		c.sparseLeaves = t.sparseLeaves.Clone()
+	}
+	c.nodes = strings.Clone(t.nodes)
+	c.packed = clone(t.packed)
+	c.runs = *t.runs.Clone()
+	c.suffixes = clone(t.suffixes)
+	c.dense = *t.dense.Clone()
+	c.denseBits = clone(t.denseBits)
+	c.dispatch = clone(t.dispatch)
+	c.counts.words = clone(t.counts.words)
+	c.values.words = clone(t.values.words)
+	c.scores.words = clone(t.scores.words)
+	return &c
+}
+
+// Compact reallocates the slices of the trie having spare capacity, as left by growing them while building,
+// to their length, and copies the labels. It must not be called while the trie is queried.
+func (t *SuccinctTrie) Compact() {
+	t.bitmap.Compact()
+	t.leaves.Compact()
+	if t.sparseLeaves != nil {
+		t.sparseLeaves.Compact()
+	}
+	t.nodes = strings.Clone(t.nodes)
+	t.packed = clip(t.packed)
+	t.runs.Compact()
+	t.suffixes = clip(t.suffixes)
+	t.dense.Compact()
+	t.denseBits = clip(t.denseBits)
+	t.dispatch = clip(t.dispatch)
+	t.counts.words = clip(t.counts.words)
+	t.values.words = clip(t.values.words)
+	t.scores.words = clip(t.scores.words)
+}
+
+func clone[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	copy(c, s)
+	return c
+}
+
+// clip returns s reallocated to its length if it has spare capacity.
+func clip[T any](s []T) []T {
+	if cap(s) == len(s) {
+		return s
+	}
+	return clone(s)
+}
@@ -0,0 +1,37 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, randomString(1+i%9))
+	}
+
+	for _, opts := range [][]Option{nil, {WithEliasFanoLeaves()}, {WithSuffixTruncation(8)}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		c := trie.Clone()
+		assert.True(t, trie.Equal(c))
+		assert.Equal(t, trie.MemStats(), c.MemStats())
+		for _, key := range dict {
+			assert.True(t, c.MayContain(key))
+		}
+		assert.Equal(t, cap(c.bitmap.Words()), len(c.bitmap.Words()))
+		if len(trie.suffixes) > 0 {
+			assert.NotSame(t, &trie.suffixes[0], &c.suffixes[0])
+		}
+	}
+
+	// a merged trie grows its vectors bit by bit, though their index lays them out again exactly
+	merged := mustTrie(Union(BuildSuccinctTrie(dict[:2500]), BuildSuccinctTrie(dict[2500:])))
+	keys := merged.Keys()
+	merged.Compact()
+	assert.Equal(t, cap(merged.bitmap.Words()), len(merged.bitmap.Words()))
+	assert.Equal(t, cap(merged.leaves.Words()), len(merged.leaves.Words()))
+	assert.Equal(t, keys, merged.Keys())
+	assert.True(t, merged.Root().Search(dict[0]).Leaf())
+}
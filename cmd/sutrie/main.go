@@ -0,0 +1,270 @@
+// Command sutrie builds serialized succinct tries from newline-delimited keys and inspects them.
+//
+// Usage:
+//
+//	sutrie build [-format lines|hosts|dnsmasq] [-reversed] [-fold] [-elias-fano] [-o file] [input...]
+//	sutrie query -t file exists|prefix|suffix-domain [key...]
+//	sutrie stats -t file
+//	sutrie dump -t file
+//
+// build reads the keys of the inputs, or of the standard input if there is none, one per line, blank lines skipped,
+// or the domains of hosts files or dnsmasq configurations, see sutrie.ParseHosts and sutrie.ParseDnsmasq,
+// and writes the trie to the output file or the standard output.
+// query reads the keys from the standard input if none is given, and prints one line per key:
+// exists prints whether the key is in the trie, prefix the longest key of the trie which is a prefix of it,
+// and suffix-domain whether it is a domain of the trie or a subdomain of one.
+// Keys are given and printed in their natural order, also for tries built with -reversed.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nobekanai/sutrie"
+)
+
+const usage = `usage:
+	sutrie build [-format lines|hosts|dnsmasq] [-reversed] [-fold] [-elias-fano] [-o file] [input...]
+	sutrie query -t file exists|prefix|suffix-domain [key...]
+	sutrie stats -t file
+	sutrie dump -t file
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "sutrie:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command of args, reading keys from stdin and writing results to stdout.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "build":
+		return build(args, stdin, stdout)
+	case "query":
+		return query(args, stdin, stdout)
+	case "stats":
+		return stats(args, stdout)
+	case "dump":
+		return dump(args, stdout)
+	}
+	return fmt.Errorf("unknown command %q\n%s", cmd, usage)
+}
+
+func build(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	reversed := flags.Bool("reversed", false, "store keys reversed, for domain suffix matching")
+	fold := flags.Bool("fold", false, "fold ASCII letters to lower case")
+	eliasFano := flags.Bool("elias-fano", false, "compress the leaves with Elias-Fano coding")
+	output := flags.String("o", "", "output `file`, the standard output if empty")
+	format := flags.String("format", "lines", "input `format`: lines, hosts or dnsmasq")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	parse := readKeys
+	switch *format {
+	case "lines":
+	case "hosts":
+		parse = sutrie.ParseHosts
+	case "dnsmasq":
+		parse = sutrie.ParseDnsmasq
+	default:
+		return fmt.Errorf("build: unknown format %q", *format)
+	}
+
+	var keys []string
+	if flags.NArg() == 0 {
+		var err error
+		if keys, err = parse(stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range flags.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		more, err := parse(f)
+		keys = append(keys, more...)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	var opts []sutrie.Option
+	if *reversed {
+		opts = append(opts, sutrie.WithReversedKeys())
+	}
+	if *fold {
+		opts = append(opts, sutrie.WithCaseFolding())
+	}
+	if *eliasFano {
+		opts = append(opts, sutrie.WithEliasFanoLeaves())
+	}
+	if keys == nil {
+		keys = []string{}
+	}
+	trie, err := sutrie.Build(keys, opts...)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return trie.Marshal(stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := trie.Marshal(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func query(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	trie := trieFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("query: missing mode\n" + usage)
+	}
+	t, err := load(*trie)
+	if err != nil {
+		return err
+	}
+
+	var answer func(key string) string
+	switch mode := flags.Arg(0); mode {
+	case "exists":
+		answer = func(key string) string {
+			return fmt.Sprint(t.Contains(key))
+		}
+	case "prefix":
+		if t.Reversed() {
+			return errors.New("query: prefix is not supported by tries built with -reversed, see suffix-domain")
+		}
+		answer = func(key string) string {
+			if n := t.SearchPrefix(key); n > 0 || t.Root().Leaf() {
+				return key[:n]
+			}
+			return "-"
+		}
+	case "suffix-domain":
+		answer = func(key string) string {
+			return fmt.Sprint(t.MatchDomainSuffix(key))
+		}
+	default:
+		return fmt.Errorf("query: unknown mode %q\n%s", mode, usage)
+	}
+
+	keys := flags.Args()[1:]
+	if len(keys) == 0 {
+		if keys, err = readKeys(stdin); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(stdout)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, answer(key))
+	}
+	return w.Flush()
+}
+
+func stats(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	trie := trieFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	t, err := load(*trie)
+	if err != nil {
+		return err
+	}
+
+	s, m := t.Stats(), t.MemStats()
+	w := bufio.NewWriter(stdout)
+	fmt.Fprintf(w, "keys\t%d\n", t.Size())
+	fmt.Fprintf(w, "nodes\t%d\n", s.Nodes)
+	fmt.Fprintf(w, "internal\t%d\n", s.Internal)
+	fmt.Fprintf(w, "max depth\t%d\n", s.MaxDepth)
+	fmt.Fprintf(w, "avg depth\t%.2f\n", s.AvgDepth)
+	fmt.Fprintf(w, "reversed\t%t\n", t.Reversed())
+	fmt.Fprintf(w, "fold case\t%t\n", t.FoldsCase())
+	fmt.Fprintf(w, "bytes\t%d\n", m.Total())
+	fmt.Fprintf(w, "bytes bitmap\t%d\n", m.Bitmap+m.BitmapIndex)
+	fmt.Fprintf(w, "bytes leaves\t%d\n", m.Leaves+m.LeavesIndex)
+	fmt.Fprintf(w, "bytes labels\t%d\n", m.Labels)
+	fmt.Fprintf(w, "bytes dense\t%d\n", m.Dense)
+	fmt.Fprintf(w, "bytes suffixes\t%d\n", m.Suffixes)
+	return w.Flush()
+}
+
+func dump(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	trie := trieFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	t, err := load(*trie)
+	if err != nil {
+		return err
+	}
+
+	return t.WriteKeys(stdout)
+}
+
+func trieFlag(flags *flag.FlagSet) *string {
+	return flags.String("t", "", "trie `file` written by build")
+}
+
+// load unmarshals the trie of the file name.
+func load(name string) (*sutrie.SuccinctTrie, error) {
+	if name == "" {
+		return nil, errors.New("missing trie file, see -t")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := new(sutrie.SuccinctTrie)
+	if err := t.Unmarshal(bufio.NewReader(f)); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// readKeys returns the lines of r, skipping blank lines and trimming carriage returns.
+func readKeys(r io.Reader) ([]string, error) {
+	var keys []string
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Text()
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+		if line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, s.Err()
+}
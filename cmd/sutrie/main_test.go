@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "keys.txt")
+	assert.NoError(t, os.WriteFile(input, []byte("example.com\r\n\nads.example.org\nexample.net\n"), 0o644))
+
+	exec := func(stdin string, args ...string) string {
+		var out bytes.Buffer
+		assert.NoError(t, run(args, strings.NewReader(stdin), &out), args)
+		return out.String()
+	}
+
+	plain := filepath.Join(dir, "plain.trie")
+	reversed := filepath.Join(dir, "reversed.trie")
+	exec("", "build", "-o", plain, input)
+	exec("example.com\nads.example.org\nexample.net\n", "build", "-reversed", "-elias-fano", "-o", reversed)
+
+	hosts := filepath.Join(dir, "hosts.trie")
+	exec("127.0.0.1 localhost\n0.0.0.0 example.com ads.example.org # ads\n0.0.0.0 example.net\n", "build", "-format", "hosts", "-reversed", "-o", hosts)
+
+	for _, trie := range []string{plain, reversed, hosts} {
+		assert.Equal(t, "ads.example.org\nexample.com\nexample.net\n", sortLines(exec("", "dump", "-t", trie)))
+		assert.Equal(t, "example.com\ttrue\nexample\tfalse\n", exec("", "query", "-t", trie, "exists", "example.com", "example"))
+		assert.Equal(t, "www.example.com\ttrue\nexample.org\tfalse\n", exec("www.example.com\nexample.org\n", "query", "-t", trie, "suffix-domain"))
+		assert.Contains(t, exec("", "stats", "-t", trie), "keys\t3\n")
+	}
+	assert.Equal(t, "example.company\texample.com\nexample\t-\n", exec("", "query", "-t", plain, "prefix", "example.company", "example"))
+
+	var out bytes.Buffer
+	assert.Error(t, run(nil, nil, &out))
+	assert.Error(t, run([]string{"frobnicate"}, nil, &out))
+	assert.Error(t, run([]string{"build", "-format", "csv"}, nil, &out))
+	assert.Error(t, run([]string{"query", "-t", plain, "frobnicate"}, nil, &out))
+	assert.Error(t, run([]string{"query", "-t", reversed, "prefix", "a"}, nil, &out))
+	assert.Error(t, run([]string{"dump"}, nil, &out))
+	assert.Error(t, run([]string{"dump", "-t", input}, nil, &out))
+}
+
+func sortLines(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,186 @@
+package sutrie
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// trieMagic starts the tries not written by Marshal, followed by a byte telling their format.
+// Its first byte, a zero, never starts a gob stream, which tells them from the tries written by Marshal.
+const trieMagic = "\x00SUTRIE"
+
+// Formats of the tries starting with trieMagic.
+const (
+	formatGob        = 0
+	formatCompressed = 'Z'
+	formatSections   = 'S'
+)
+
+// Codec is a compression format of MarshalCompressed. The package provides Flate and Gzip, other formats,
+// like zstd or snappy, are plugged with RegisterCodec so that Unmarshal recognizes them.
+type Codec interface {
+	// ID identifies the codec in the header of compressed tries, the IDs below 128 are reserved for the package
+	ID() uint8
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	// Flate is the DEFLATE format of compress/flate, at the best compression
+	Flate Codec = flateCodec{}
+	// Gzip is the gzip format of compress/gzip, at the best compression
+	Gzip Codec = gzipCodec{}
+)
+
+type flateCodec struct{}
+
+func (flateCodec) ID() uint8 { return 1 }
+func (flateCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestCompression)
+}
+func (flateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) ID() uint8 { return 2 }
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, gzip.BestCompression)
+}
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(false) // what follows the trie is not for us
+	return zr, nil
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[uint8]Codec{1: Flate, 2: Gzip}
+)
+
+// RegisterCodec makes Unmarshal recognize the tries compressed with c. It panics if the ID of c is taken.
+func RegisterCodec(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if _, ok := codecs[c.ID()]; ok {
+		panic(fmt.Sprintf("sutrie: codec %d already registered", c.ID()))
+	}
+	codecs[c.ID()] = c
+}
+
+// MarshalCompressed writes the trie to w like Marshal, compressed with codec, usually to a half or a quarter
+// of the size. The compression is streamed, and Unmarshal detects it. It fails without writing if codec is nil.
+func (t *SuccinctTrie) MarshalCompressed(w io.Writer, codec Codec) error {
+	if codec == nil {
+		return errors.New("sutrie: nil codec")
+	}
+	if _, err := io.WriteString(w, trieMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{formatCompressed, codec.ID()}); err != nil {
+		return err
+	}
+
+	zw, err := codec.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := t.Marshal(zw); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// openTrie returns the format of the trie read from r and the reader of its content, the gob stream
+// of the trie unless it is formatSections, which is r itself unless the trie is compressed.
+// done reads the rest of the compressed stream once the trie is decoded.
+func openTrie(r io.Reader) (format byte, content io.Reader, done func() error, err error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		// as gob would do, this may read past the trie
+		b := bufio.NewReader(r)
+		r, br = b, b
+	}
+	noop := func() error { return nil }
+
+	first, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if first != trieMagic[0] {
+		return formatGob, &unreadReader{r: r, first: first, unread: true}, noop, nil
+	}
+
+	magic := make([]byte, len(trieMagic))
+	if _, err := io.ReadFull(r, magic[1:]); err != nil {
+		return 0, nil, nil, err
+	}
+	if string(magic[1:]) != trieMagic[1:] {
+		return 0, nil, nil, fmt.Errorf("sutrie: invalid trie header")
+	}
+	if format, err = br.ReadByte(); err != nil {
+		return 0, nil, nil, err
+	}
+	switch format {
+	case formatSections:
+		return format, r, noop, nil
+	case formatCompressed:
+	default:
+		return 0, nil, nil, fmt.Errorf("sutrie: unknown trie format %q", format)
+	}
+
+	id, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	codecsMu.RLock()
+	codec, ok := codecs[id]
+	codecsMu.RUnlock()
+	if !ok {
+		return 0, nil, nil, fmt.Errorf("sutrie: unknown codec %d, see RegisterCodec", id)
+	}
+
+	zr, err := codec.NewReader(r)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return formatGob, zr, func() error {
+		if _, err := io.Copy(io.Discard, zr); err != nil {
+			zr.Close()
+			return err
+		}
+		return zr.Close()
+	}, nil
+}
+
+// unreadReader is r with first put back in front, it is an io.ByteReader so that gob does not buffer it.
+type unreadReader struct {
+	r      io.Reader
+	first  byte
+	unread bool
+}
+
+func (u *unreadReader) Read(p []byte) (int, error) {
+	if u.unread && len(p) > 0 {
+		p[0], u.unread = u.first, false
+		return 1, nil
+	}
+	return u.r.Read(p)
+}
+
+func (u *unreadReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(u, b[:])
+	return b[0], err
+}
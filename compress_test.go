@@ -0,0 +1,65 @@
+package sutrie
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type zlibCodec struct{}
+
+var registerZlib sync.Once
+
+func (zlibCodec) ID() uint8                                     { return 200 }
+func (zlibCodec) NewWriter(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil }
+func (zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error)  { return zlib.NewReader(r) }
+
+func TestMarshalCompressed(t *testing.T) {
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, fmt.Sprintf("host%d.example.com", i*7))
+	}
+	trie := BuildSuccinctTrie(dict)
+
+	var plain bytes.Buffer
+	assert.NoError(t, trie.Marshal(&plain))
+
+	registerZlib.Do(func() { RegisterCodec(zlibCodec{}) })
+	assert.Panics(t, func() { RegisterCodec(zlibCodec{}) })
+
+	for _, codec := range []Codec{Flate, Gzip, zlibCodec{}} {
+		var buf bytes.Buffer
+		assert.NoError(t, trie.MarshalCompressed(&buf, codec))
+		assert.Less(t, buf.Len(), plain.Len()/2, codec.ID())
+
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(bytes.NewReader(buf.Bytes())))
+		assert.True(t, trie.Equal(&loaded))
+
+		// followed by another trie, read from a reader which is not an io.ByteReader
+		assert.NoError(t, trie.Marshal(&buf))
+		r := io.MultiReader(&buf)
+		var first, second SuccinctTrie
+		_, err := first.ReadFrom(r)
+		assert.NoError(t, err)
+		_, err = second.ReadFrom(r)
+		assert.NoError(t, err)
+		assert.True(t, trie.Equal(&first))
+		assert.True(t, trie.Equal(&second))
+	}
+
+	var buf bytes.Buffer
+	assert.Error(t, trie.MarshalCompressed(&buf, nil))
+	assert.Zero(t, buf.Len())
+	assert.NoError(t, trie.MarshalCompressed(&buf, Flate))
+	data := buf.Bytes()
+	data[len(trieMagic)+1] = 99
+	assert.Error(t, new(SuccinctTrie).Unmarshal(bytes.NewReader(data)))
+	assert.Error(t, new(SuccinctTrie).Unmarshal(bytes.NewReader([]byte("\x00SUTRIE"))))
+	assert.Error(t, new(SuccinctTrie).Unmarshal(bytes.NewReader(nil)))
+}
@@ -0,0 +1,71 @@
+package sutrie
+
+// Cursor is a position in the trie that remembers the bytes of the path leading to it,
+// so the key of the current node can be recovered with Key.
+// Unlike Node, a Cursor is mutable and should be passed by pointer.
+type Cursor struct {
+	node Node
+	key  []byte
+}
+
+// Cursor returns a cursor positioned at the root of trie.
+func (t *SuccinctTrie) Cursor() *Cursor {
+	return &Cursor{node: t.Root()}
+}
+
+// Node returns the node the cursor is positioned at.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// Key returns the accumulated path from the root to the current node.
+func (c *Cursor) Key() string {
+	return string(c.key)
+}
+
+// Depth returns the length of the current key.
+func (c *Cursor) Depth() int {
+	return len(c.key)
+}
+
+// Next moves the cursor to the child on byte b and reports whether it exists.
+// The cursor is left unchanged if there is no such child.
+func (c *Cursor) Next(b byte) bool {
+	next := c.node.Next(b)
+	if !next.Exists() {
+		return false
+	}
+
+	c.node = next
+	c.key = append(c.key, b)
+	return true
+}
+
+// Search moves the cursor along s and reports whether the whole of s was consumed.
+// When it returns false, the cursor stays at the node of the longest prefix of s found in the trie.
+func (c *Cursor) Search(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !c.Next(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset moves the cursor back to the root, reusing its key buffer.
+func (c *Cursor) Reset() {
+	c.node = c.node.trie.Root()
+	c.key = c.key[:0]
+}
+
+// Back moves the cursor to the parent of the current node and reports whether it moved,
+// it is false at the root.
+func (c *Cursor) Back() bool {
+	if len(c.key) == 0 {
+		return false
+	}
+
+	c.node = c.node.Parent()
+	c.key = c.key[:len(c.key)-1]
+	return true
+}
@@ -0,0 +1,28 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a"})
+
+	c := trie.Cursor()
+	assert.Equal(t, "", c.Key())
+
+	assert.True(t, c.Next('h'))
+	assert.False(t, c.Next('x'))
+	assert.Equal(t, "h", c.Key())
+
+	assert.True(t, c.Search("at"))
+	assert.Equal(t, "hat", c.Key())
+	assert.True(t, c.Node().Leaf())
+
+	c.Reset()
+	assert.False(t, c.Search("iz"))
+	assert.Equal(t, "i", c.Key())
+	assert.Equal(t, 1, c.Depth())
+	assert.False(t, c.Node().Leaf())
+}
@@ -0,0 +1,87 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var errInvalidCounts = errors.New("sutrie: invalid occurrence counts")
+
+// ReportDuplicates calls report with every key given more than once and its number of occurrences,
+// in ascending order of the keys as stored, that is after case folding and reversal. Duplicates are
+// collapsed into one key either way, see WithMultiplicities to keep their counts.
+func ReportDuplicates(report func(key string, count int)) Option {
+	return func(o *buildOptions) error {
+		if report == nil {
+			return fmt.Errorf("%w: nil duplicate report", ErrInvalidOption)
+		}
+		o.onDuplicate = report
+		return nil
+	}
+}
+
+// WithMultiplicities stores with every key its number of occurrences in the dict, returned by Count,
+// making the trie a multiset. Counts take the bits of the largest one minus one per key, nothing if there are
+// no duplicates. Tries derived with Union, Intersection, Difference or Subtrie count every key once.
+// It cannot be combined with WithSuffixTruncation.
+func WithMultiplicities() Option {
+	return func(o *buildOptions) error {
+		o.multiset = true
+		return nil
+	}
+}
+
+// dedup sorts dict, reports its duplicates and returns the runs of equal keys, as the key and its count,
+// if the multiplicities are kept. Duplicates are left in dict, build skips them.
+func (o *buildOptions) dedup(dict []string) (runs []keyCount) {
+	sort.Strings(dict)
+	for i := 0; i < len(dict); {
+		j := i + 1
+		for j < len(dict) && dict[j] == dict[i] {
+			j++
+		}
+		if j-i > 1 {
+			if o.onDuplicate != nil {
+				o.onDuplicate(dict[i], j-i)
+			}
+			if o.multiset {
+				runs = append(runs, keyCount{dict[i], j - i})
+			}
+		}
+		i = j
+	}
+	return runs
+}
+
+// keyCount is a key given count times.
+type keyCount struct {
+	key   string
+	count int
+}
+
+// setCounts stores the count of the keys of runs, the others counting once.
+func (t *SuccinctTrie) setCounts(runs []keyCount) {
+	if len(runs) == 0 {
+		return
+	}
+	values := make([]uint64, t.size)
+	for _, r := range runs {
+		values[t.Root().Search(r.key).LeafIndex()] = uint64(r.count - 1)
+	}
+	t.counts = newPackedInts(values)
+}
+
+// Count returns the number of occurrences of the key of the current node in the dict the trie was built from,
+// 1 unless built WithMultiplicities, or 0 if the node is not a leaf.
+func (n Node) Count() int {
+	if !n.leaf {
+		return 0
+	}
+	return int(n.trie.counts.get(n.trie.leavesBefore(n.index))) + 1
+}
+
+// Count is short for t.Root().Search(key).Count() with key normalized and reversed like by Contains.
+func (t *SuccinctTrie) Count(key string) int {
+	return t.lookup(key).Count()
+}
@@ -0,0 +1,70 @@
+package sutrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportDuplicates(t *testing.T) {
+	reported := map[string]int{}
+	trie, err := Build([]string{"it", "Hat", "is", "hat", "it", "HAT"}, WithCaseFolding(), ReportDuplicates(func(key string, count int) {
+		reported[key] = count
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"hat": 3, "it": 2}, reported)
+	assert.Equal(t, 3, trie.Size())
+	assert.Equal(t, 1, trie.Count("hat"))
+
+	_, err = Build([]string{"a"}, ReportDuplicates(nil))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestMultiplicities(t *testing.T) {
+	dict := []string{"b", "a", "abc", "b", "", "b", "ab", "a"}
+	trie, err := Build(append([]string(nil), dict...), WithMultiplicities())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, trie.Size())
+
+	check := func(trie *SuccinctTrie) {
+		for key, count := range map[string]int{"": 1, "a": 2, "ab": 1, "abc": 1, "b": 3, "abcd": 0, "c": 0} {
+			assert.Equal(t, count, trie.Count(key), key)
+		}
+		assert.Equal(t, 0, trie.Root().Search("ab").Next('x').Count())
+	}
+	check(trie)
+	check(trie.Clone())
+	assert.Equal(t, 8, trie.MemStats().Counts)
+
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		check(&loaded)
+	}
+
+	// keys without duplicates cost nothing
+	set := BuildSuccinctTrie([]string{"a", "b"}, WithMultiplicities())
+	assert.Equal(t, 0, set.MemStats().Counts)
+	assert.Equal(t, 1, set.Count("a"))
+
+	// as with Contains, keys are reversed like those of the build
+	reversed := BuildSuccinctTrie([]string{"ab", "ab", "ba"}, WithMultiplicities(), WithReversedKeys(), WithPackedLabels())
+	assert.Equal(t, 2, reversed.Count("ab"))
+	assert.Equal(t, 1, reversed.Count("ba"))
+
+	// without the option, duplicates count once
+	assert.Equal(t, 1, BuildSuccinctTrie(append([]string(nil), dict...)).Count("b"))
+	assert.Equal(t, 1, mustTrie(Union(trie, set)).Count("b"))
+
+	_, err = Build([]string{"a"}, WithMultiplicities(), WithSuffixTruncation(8))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, err = BuildFromKeyReader(strings.NewReader("a\n"), WithMultiplicities())
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
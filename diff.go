@@ -0,0 +1,52 @@
+package sutrie
+
+import "errors"
+
+var errDiffReversed = errors.New("sutrie: cannot diff tries with reversed and non-reversed keys")
+
+// DiffFunc is the type of the function called by Diff for every key in only one of the tries,
+// added being true if the key is only in the new one.
+// If the function returns SkipAll, Diff stops and returns nil, any other non-nil error stops Diff and is returned by it.
+type DiffFunc func(key string, added bool) error
+
+// Diff calls fn for the keys removed from old and added in new, in lexicographic order.
+// The tries are traversed in parallel in a single pass, so no key list is materialized.
+// Keys are as stored, see WithReversedKeys, the tries must both be built with it or neither, else Diff returns an error.
+func Diff(old, new *SuccinctTrie, fn DiffFunc) error {
+	if old.reversed != new.reversed {
+		return errDiffReversed
+	}
+
+	var key []byte
+	err := diff([]Node{old.Root(), new.Root()}, &key, fn)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+// diff reports the keys under group, the nodes of the same path in the old and new trie, either of which may be null.
+func diff(group []Node, key *[]byte, fn DiffFunc) error {
+	if group[0].leaf != group[1].leaf {
+		if err := fn(string(*key), group[1].leaf); err != nil {
+			return err
+		}
+	}
+
+	next := make([]int32, 2)
+	resetCursors(group, next)
+	for {
+		label, ok := minLabel(group, next)
+		if !ok {
+			return nil
+		}
+
+		child := appendChildren(nil, group, next, label)
+		*key = append(*key, label)
+		err := diff(child, key, fn)
+		*key = (*key)[:len(*key)-1]
+		if err != nil {
+			return err
+		}
+	}
+}
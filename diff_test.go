@@ -0,0 +1,74 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	old, new := make(map[string]bool), make(map[string]bool)
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("%x.example", rnd.Intn(20000))
+		switch rnd.Intn(3) {
+		case 0:
+			old[key] = true
+		case 1:
+			new[key] = true
+		default:
+			old[key], new[key] = true, true
+		}
+	}
+	old["a"], new["ab"] = true, true
+
+	var keys []string
+	var want []string
+	for key := range old {
+		keys = append(keys, key)
+		if !new[key] {
+			want = append(want, "-"+key)
+		}
+	}
+	oldTrie := BuildSuccinctTrie(keys)
+	keys = nil
+	for key := range new {
+		keys = append(keys, key)
+		if !old[key] {
+			want = append(want, "+"+key)
+		}
+	}
+	newTrie := BuildSuccinctTrie(keys)
+	sort.Slice(want, func(i, j int) bool { return want[i][1:] < want[j][1:] })
+
+	var got []string
+	assert.NoError(t, Diff(oldTrie, newTrie, func(key string, added bool) error {
+		if added {
+			got = append(got, "+"+key)
+		} else {
+			got = append(got, "-"+key)
+		}
+		return nil
+	}))
+	assert.Equal(t, want, got)
+
+	n := 0
+	assert.NoError(t, Diff(oldTrie, newTrie, func(key string, added bool) error {
+		if n++; n == 3 {
+			return SkipAll
+		}
+		return nil
+	}))
+	assert.Equal(t, 3, n)
+
+	errStop := errors.New("stop")
+	assert.Equal(t, errStop, Diff(oldTrie, newTrie, func(string, bool) error { return errStop }))
+	assert.NoError(t, Diff(oldTrie, oldTrie, func(string, bool) error { return errStop }))
+
+	reversed := BuildSuccinctTrie([]string{"a.com"}, WithReversedKeys())
+	assert.Error(t, Diff(oldTrie, reversed, func(string, bool) error { return nil }))
+}
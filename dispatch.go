@@ -0,0 +1,63 @@
+package sutrie
+
+import "fmt"
+
+// WithDispatchTable adds a 256-entry dispatch table to every node of the first levels of the trie, 1 or 2,
+// mapping each byte straight to the child it labels, so that the first transitions of every lookup are
+// a single array load. A level costs 1 KiB per node: 1 KiB for the root alone, up to 257 KiB with its children.
+func WithDispatchTable(levels int) Option {
+	return func(o *buildOptions) error {
+		if levels < 1 || levels > 2 {
+			return fmt.Errorf("%w: %d dispatch levels, not 1 or 2", ErrInvalidOption, levels)
+		}
+		o.dispatchLevels = levels
+		return nil
+	}
+}
+
+// initDispatch builds the dispatch table of the first dispatchLevels levels. The nodes of these levels
+// are the first ones in level order, the table holding the child of node i labeled b at i<<8|b, or -1.
+func (t *SuccinctTrie) initDispatch() {
+	t.dispatch = nil
+	if t.dispatchLevels == 0 {
+		return
+	}
+
+	// not using Root or node, which wait for the indexes this is building
+	nodes := int32(1)
+	if t.dispatchLevels > 1 {
+		nodes = int32(t.bitmap.Select1(1)) - 1
+	}
+
+	t.dispatch = make([]int32, int(nodes)<<8)
+	for i := range t.dispatch {
+		t.dispatch[i] = -1
+	}
+	for i := int32(0); i < nodes; i++ {
+		firstChild := int32(t.bitmap.Select1(int(i))) - i
+		afterLastChild := int32(t.bitmap.Select1(int(i)+1)) - i - 1
+		for k := max(firstChild, 0); k < afterLastChild; k++ {
+			t.dispatch[int(i)<<8|int(t.label(k))] = k
+		}
+	}
+}
+
+// child returns the index of the child of n labeled b, or -1 if there is none.
+func (n Node) child(b byte) int32 {
+	if n.firstChild >= n.afterLastChild {
+		return -1 // null nodes included
+	}
+	return n.trie.childOf(n.index, n.firstChild, n.afterLastChild, b)
+}
+
+// childOf returns the index of the child labeled b of the node at index, whose children are [first, after),
+// or -1 if there is none.
+func (t *SuccinctTrie) childOf(index, first, after int32, b byte) int32 {
+	if int(index) < len(t.dispatch)>>8 {
+		if t.fold != foldNone {
+			b = lowerASCII(b)
+		}
+		return t.dispatch[int(index)<<8|int(b)]
+	}
+	return t.indexByte(first, after, b)
+}
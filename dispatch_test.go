@@ -0,0 +1,78 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatchTable(t *testing.T) {
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, fmt.Sprintf("%c%x", 'A'+i%40, i*31))
+	}
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	for levels := 1; levels <= 2; levels++ {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), WithDispatchTable(levels))
+		assert.True(t, plain.Equal(trie))
+		assert.Equal(t, 4*len(trie.dispatch), trie.MemStats().Dispatch)
+
+		nodes := []Node{trie.Root()}
+		if levels == 2 {
+			nodes = trie.Root().ExpandAll(nodes)
+		}
+		assert.Len(t, trie.dispatch, len(nodes)<<8)
+		for _, n := range nodes {
+			for b := 0; b < 256; b++ {
+				assert.Equal(t, n.trie.indexByte(n.firstChild, n.afterLastChild, byte(b)), n.child(byte(b)))
+			}
+		}
+		for _, key := range dict {
+			assert.True(t, trie.Root().Search(key).Leaf())
+			assert.Equal(t, len(key), trie.Root().SearchPrefix(key+"z"))
+		}
+
+		// the table is rebuilt on load
+		for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+			func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+			func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+		} {
+			var buf bytes.Buffer
+			assert.NoError(t, marshal(trie, &buf))
+			var loaded SuccinctTrie
+			assert.NoError(t, loaded.Unmarshal(&buf))
+			loaded.Warmup()
+			assert.Equal(t, trie.dispatch, loaded.dispatch)
+		}
+		assert.Equal(t, trie.dispatch, trie.Clone().dispatch)
+	}
+
+	folded := BuildSuccinctTrie([]string{"Hat", "is"}, WithCaseFolding(), WithDispatchTable(2))
+	assert.True(t, folded.Root().Search("HAT").Leaf())
+	assert.True(t, folded.Root().Search("IS").Leaf())
+
+	assert.True(t, BuildSuccinctTrie(nil, WithDispatchTable(2)).Root().Exists())
+	_, err := Build([]string{"a"}, WithDispatchTable(3))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	assert.Nil(t, plain.dispatch)
+}
+
+func BenchmarkDispatchTable(b *testing.B) {
+	var dict []string
+	for i := 0; i < 100000; i++ {
+		dict = append(dict, fmt.Sprintf("%c%c%x", 'A'+i%50, 'a'+i/50%26, i*31))
+	}
+
+	for _, opts := range [][]Option{nil, {WithDispatchTable(1)}, {WithDispatchTable(2)}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		b.Run(fmt.Sprint(len(trie.dispatch)>>8), func(b *testing.B) {
+			root := trie.Root()
+			for i := 0; i < b.N; i++ {
+				root.Search(dict[i%len(dict)])
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package sutrie
+
+// WithReversedKeys stores every key reversed, so that keys sharing a suffix share a path of the trie.
+// This is the layout of choice for domain names, see MatchDomainSuffix.
+// The queries of the trie, like Contains, Lookup, Count and SearchPrefix, reverse their key as the build does,
+// so that SearchPrefix and HasPrefix match the suffixes of keys.
+// Note that the traversal and enumeration APIs (Root, Walk, Keys, ...) see keys as stored, that is reversed.
+func WithReversedKeys() Option {
+	return func(o *buildOptions) error {
+		o.reversed = true
+		return nil
+	}
+}
+
+// Reversed reports whether the trie was built WithReversedKeys.
+func (t *SuccinctTrie) Reversed() bool {
+	return t.reversed
+}
+
+func reverseKeys(dict []string) []string {
+	ret := make([]string, len(dict))
+	for i, key := range dict {
+		ret[i] = reverse(key)
+	}
+	return ret
+}
+
+func reverse(s string) string {
+	b := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		b[len(s)-1-i] = s[i]
+	}
+	return string(b)
+}
+
+// MatchDomainSuffix reports whether host is one of the domains of the trie or a subdomain of one,
+// for example an entry "example.com" matches "example.com" and "www.example.com" but not "badexample.com".
+// It does not allocate when the trie was built WithReversedKeys, nor does normalizing host unless the normalizer
+// allocates, see WithNormalizer. Otherwise every parent domain of host is searched.
+func (t *SuccinctTrie) MatchDomainSuffix(host string) bool {
+	if t.normalize != nil {
+		norm, buf := normalized(t, host)
+		defer normBuffers.Put(buf)
+		return matchDomainSuffix(t, norm)
+	}
+	return matchDomainSuffix(t, host)
+}
+
+func matchDomainSuffix[K string | []byte](t *SuccinctTrie, host K) bool {
+	if !t.reversed {
+		root := t.Root()
+		for i := 0; i < len(host); i++ {
+			if (i == 0 || host[i-1] == '.') && search(root, host[i:]).Leaf() {
+				return true
+			}
+		}
+		return false
+	}
+
+	n := t.Root()
+	for i := len(host) - 1; i >= 0; i-- {
+		if n = n.Next(host[i]); !n.Exists() {
+			return false
+		}
+		if n.leaf && (i == 0 || host[i-1] == '.') {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchDomainSuffix(t *testing.T) {
+	dict := []string{"example.com", "ads.example.org", "co.uk"}
+
+	reversed := BuildSuccinctTrie(dict, WithReversedKeys())
+	assert.True(t, reversed.Reversed())
+	assert.Equal(t, []string{"gro.elpmaxe.sda", "ku.oc", "moc.elpmaxe"}, reversed.Keys())
+
+	var buf bytes.Buffer
+	assert.NoError(t, reversed.Marshal(&buf))
+	var decoded SuccinctTrie
+	assert.NoError(t, decoded.Unmarshal(&buf))
+
+	for _, trie := range []*SuccinctTrie{reversed, &decoded, BuildSuccinctTrie(dict)} {
+		assert.True(t, trie.MatchDomainSuffix("example.com"))
+		assert.True(t, trie.MatchDomainSuffix("www.example.com"))
+		assert.True(t, trie.MatchDomainSuffix("a.b.ads.example.org"))
+		assert.True(t, trie.MatchDomainSuffix("bbc.co.uk"))
+		assert.False(t, trie.MatchDomainSuffix("badexample.com"))
+		assert.False(t, trie.MatchDomainSuffix("example.org"))
+		assert.False(t, trie.MatchDomainSuffix("com"))
+		assert.False(t, trie.MatchDomainSuffix(""))
+	}
+}
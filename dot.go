@@ -0,0 +1,54 @@
+package sutrie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDOT writes the first maxNodes nodes of the trie in level order as a Graphviz DOT graph,
+// edges being labeled with their byte and leaves drawn as double circles.
+// Nodes whose children are cut off get a dashed edge to "...". All nodes are written if maxNodes <= 0.
+func (t *SuccinctTrie) WriteDOT(w io.Writer, maxNodes int) error {
+	if maxNodes <= 0 || maxNodes > t.numNodes() {
+		maxNodes = t.numNodes()
+	}
+
+	t.Warmup()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph sutrie {")
+	fmt.Fprintln(bw, "\tnode [shape=circle, label=\"\"];")
+
+	var i int32
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		defer func() { i++ }()
+		if i >= int32(maxNodes) {
+			return
+		}
+
+		if t.isLeaf(i) {
+			fmt.Fprintf(bw, "\tn%d [shape=doublecircle];\n", i)
+		} else {
+			fmt.Fprintf(bw, "\tn%d;\n", i)
+		}
+		for k := firstChild; k < afterLastChild; k++ {
+			if k >= int32(maxNodes) {
+				fmt.Fprintf(bw, "\tmore%d [shape=none, label=\"...\"];\n\tn%d -> more%d [style=dashed];\n", i, i, i)
+				break
+			}
+			fmt.Fprintf(bw, "\tn%d -> n%d [label=%s];\n", i, k, dotLabel(t.label(k)))
+		}
+	})
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotLabel returns b quoted for DOT, bytes which are not printable ASCII as \xNN.
+func dotLabel(b byte) string {
+	if b < 0x20 || b >= 0x7f {
+		return fmt.Sprintf(`"\\x%02x"`, b)
+	}
+	return strconv.Quote(string(rune(b)))
+}
@@ -0,0 +1,37 @@
+package sutrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDOT(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"a", "ab", "b\"", "\xff"})
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.WriteDOT(&buf, 0))
+	assert.Equal(t, `digraph sutrie {
+	node [shape=circle, label=""];
+	n0;
+	n0 -> n1 [label="a"];
+	n0 -> n2 [label="b"];
+	n0 -> n3 [label="\\xff"];
+	n1 [shape=doublecircle];
+	n1 -> n4 [label="b"];
+	n2;
+	n2 -> n5 [label="\""];
+	n3 [shape=doublecircle];
+	n4 [shape=doublecircle];
+	n5 [shape=doublecircle];
+}
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, trie.WriteDOT(&buf, 3))
+	assert.Contains(t, buf.String(), "n0 -> more0 [style=dashed]")
+	assert.NotContains(t, buf.String(), "n3")
+	assert.Equal(t, 1, strings.Count(buf.String(), "n1 ->"))
+}
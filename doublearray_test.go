@@ -0,0 +1,53 @@
+package sutrie
+
+import (
+	mrand "math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoubleArrayTrie(t *testing.T) {
+	dict := []string{"hat", "is", "it", "a", "中文", "abc", "abd", "", "\x00", "\xff\xff", "it"}
+	for i := 0; i < 20000; i++ {
+		dict = append(dict, randomString(1+mrand.Intn(6)))
+	}
+
+	searchers := []Searcher{BuildSuccinctTrie(append([]string(nil), dict...)), BuildDoubleArrayTrie(dict)}
+	assert.Equal(t, searchers[0].Size(), searchers[1].Size())
+
+	queries := append([]string{"", "h", "ha", "hatt", "中", "\x00\x00", "\xff"}, dict...)
+	for i := 0; i < 20000; i++ {
+		queries = append(queries, randomString(1+mrand.Intn(8)))
+	}
+	for _, key := range queries {
+		assert.Equal(t, searchers[0].Contains(key), searchers[1].Contains(key), "%q", key)
+		assert.Equal(t, searchers[0].SearchPrefix(key), searchers[1].SearchPrefix(key), "%q", key)
+	}
+
+	empty := BuildDoubleArrayTrie(nil)
+	assert.Equal(t, 0, empty.Size())
+	assert.False(t, empty.Contains(""))
+	assert.False(t, empty.Contains("a"))
+}
+
+func BenchmarkSearcher(b *testing.B) {
+	const l = 1000000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(10 + mrand.Intn(11))
+	}
+
+	for name, s := range map[string]Searcher{
+		"sutrie":      BuildSuccinctTrie(append([]string(nil), dict...)),
+		"doublearray": BuildDoubleArrayTrie(dict),
+	} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !s.Contains(dict[i%l]) {
+					b.FailNow()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package sutrie
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Equal reports whether t and other hold the same keys, as stored, with the same interpretation:
+// they must agree on WithReversedKeys, case folding and suffix truncation with the same suffix hashes.
+// How the leaves are encoded, see WithEliasFanoLeaves, does not matter.
+func (t *SuccinctTrie) Equal(other *SuccinctTrie) bool {
+	t.Warmup()
+	other.Warmup()
+	if t.numNodes() != other.numNodes() || t.labels(0, int32(t.numNodes())) != other.labels(0, int32(other.numNodes())) || t.size != other.size || t.reversed != other.reversed || t.fold != other.fold ||
+		t.truncated != other.truncated || t.hashBits != other.hashBits {
+		return false
+	}
+
+	a, b := t.shapeWords(), other.shapeWords()
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	for k := 0; k < t.size; k++ {
+		if t.selectLeaf(k) != other.selectLeaf(k) {
+			return false
+		}
+	}
+	for i := range t.suffixes {
+		if t.suffixes[i] != other.suffixes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns a 64-bit FNV-1a hash of what Equal compares, so equal tries have the same hash,
+// which is stable across Marshal and Unmarshal and across processes.
+func (t *SuccinctTrie) Hash() uint64 {
+	t.Warmup()
+	h := fnv.New64a()
+	var buf []byte
+	put := func(v uint64) {
+		buf = binary.LittleEndian.AppendUint64(buf, v)
+		if len(buf) >= 4096 {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+
+	flags := uint64(t.fold)
+	if t.reversed {
+		flags |= 1 << 8
+	}
+	if t.truncated {
+		flags |= 1<<9 | uint64(t.hashBits)<<16
+	}
+	put(flags)
+	put(uint64(t.size))
+	put(uint64(t.numNodes()))
+	for _, word := range t.shapeWords() {
+		put(word)
+	}
+	for k := 0; k < t.size; k++ {
+		put(uint64(t.selectLeaf(k)))
+	}
+	for _, word := range t.suffixes {
+		put(word)
+	}
+	h.Write(buf)
+	h.Write([]byte(t.labels(0, int32(t.numNodes()))))
+	return h.Sum64()
+}
+
+// shapeWords returns the words of the bitmap holding its 2n+1 bits.
+func (t *SuccinctTrie) shapeWords() []uint64 {
+	return t.bitmap.Words()[:(2*t.numNodes()+1+63)>>6]
+}
@@ -0,0 +1,47 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqualHash(t *testing.T) {
+	dict := []string{"hat", "is", "it", "a", "abc"}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	loaded := &SuccinctTrie{}
+	assert.NoError(t, loaded.Unmarshal(&buf))
+
+	same := []*SuccinctTrie{
+		loaded,
+		BuildSuccinctTrie([]string{"abc", "a", "it", "is", "hat", "hat"}),
+		BuildSuccinctTrie(append([]string(nil), dict...), WithEliasFanoLeaves()),
+		mustTrie(Union(BuildSuccinctTrie([]string{"hat", "a"}), BuildSuccinctTrie([]string{"is", "it", "abc"}))),
+	}
+	for _, other := range same {
+		assert.True(t, trie.Equal(other))
+		assert.True(t, other.Equal(trie))
+		assert.Equal(t, trie.Hash(), other.Hash())
+	}
+
+	different := []*SuccinctTrie{
+		BuildSuccinctTrie([]string{"hat", "is", "it", "a"}),
+		BuildSuccinctTrie([]string{"hat", "is", "it", "ab", "abc"}),
+		BuildSuccinctTrie(append([]string(nil), dict...), WithCaseFolding()),
+		BuildSuccinctTrie(nil),
+	}
+	for _, other := range different {
+		assert.False(t, trie.Equal(other))
+		assert.NotEqual(t, trie.Hash(), other.Hash())
+	}
+
+	// the same stored keys read differently
+	reversed := BuildSuccinctTrie([]string{"tah", "si", "ti", "a", "cba"}, WithReversedKeys())
+	assert.Equal(t, trie.nodes, reversed.nodes)
+	assert.False(t, trie.Equal(reversed))
+	assert.NotEqual(t, trie.Hash(), reversed.Hash())
+}
@@ -0,0 +1,367 @@
+package sutrie
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// extChunk is the size in bytes of the buffers of the streams of every level spilled by BuildExternal.
+var extChunk = 4 << 10
+
+// BuildExternal builds the trie of the keys of runs, each one newline-delimited and sorted in ascending byte order
+// like the output of WriteKeys or of sort(1) with LC_ALL=C, and writes it to w in the format of MarshalSections.
+// The runs are merged and their duplicates dropped as they are read, and the nodes of every level are spilled
+// to a temporary file in tmpDir, or os.TempDir() if empty, so that whatever the number of keys, memory holds
+// a few buffers per level of the trie only: tries over datasets larger than RAM can be built on modest machines,
+// then loaded with Unmarshal or inspected with ReadSections. As in BuildFromKeyReader, carriage returns ending
+// lines are trimmed and blank lines skipped.
+func BuildExternal(w io.Writer, tmpDir string, runs ...io.Reader) error {
+	f, err := os.CreateTemp(tmpDir, "sutrie-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	b := extBuilder{spill: bufio.NewWriterSize(f, sectionChunk)}
+	b.level(0).bitmap.appendBit(true)
+	if err := mergeRuns(runs, b.add); err != nil {
+		return err
+	}
+	if err := b.spill.Flush(); err != nil {
+		return err
+	}
+	if b.nodes > int64(maxNodes) {
+		return ErrTooLarge
+	}
+	return b.write(w, f)
+}
+
+// extBuilder builds a trie from sorted keys, in the order of which the nodes of every level come
+// in level order. A node adds its 1 to the bitmap of its level followed by the 0 of every child,
+// which come before the next node of the level.
+type extBuilder struct {
+	levels   []*extLevel
+	spill    *bufio.Writer
+	offset   int64
+	prev     string
+	started  bool
+	rootLeaf bool
+	size     int
+	nodes    int64
+}
+
+// extLevel is the bitmap, leaves and labels of the nodes of one level.
+type extLevel struct {
+	bitmap, leaves, labels extStream
+}
+
+// extStream is a stream of bits, the first one being the least significant bit of the first byte, or of bytes,
+// whose full chunks are spilled.
+type extStream struct {
+	buf    []byte
+	bits   int64 // the number of bits of a stream of bits
+	chunks []extSpan
+}
+
+// extSpan is a chunk of a stream in the spill file.
+type extSpan struct {
+	offset int64
+	length int
+}
+
+func (b *extBuilder) level(d int) *extLevel {
+	for len(b.levels) <= d {
+		b.levels = append(b.levels, new(extLevel))
+	}
+	return b.levels[d]
+}
+
+// add adds key, which must not be before the previous one.
+func (b *extBuilder) add(key string) error {
+	if b.started && key == b.prev {
+		return nil
+	}
+	if !b.started && key == "" {
+		b.rootLeaf = true
+	}
+
+	d := 0
+	if b.started {
+		d = lcp(b.prev, key)
+	}
+	for ; d < len(key); d++ {
+		parent, child := b.level(d), b.level(d+1)
+		parent.bitmap.appendBit(false)
+		child.bitmap.appendBit(true)
+		child.leaves.appendBit(d+1 == len(key))
+		child.labels.buf = append(child.labels.buf, key[d])
+		b.nodes++
+		for _, s := range []*extStream{&parent.bitmap, &child.bitmap, &child.leaves, &child.labels} {
+			if err := s.flush(b); err != nil {
+				return err
+			}
+		}
+	}
+	b.prev, b.started = key, true
+	b.size++
+	return nil
+}
+
+func (s *extStream) appendBit(bit bool) {
+	if s.bits&7 == 0 {
+		s.buf = append(s.buf, 0)
+	}
+	if bit {
+		s.buf[len(s.buf)-1] |= 1 << (s.bits & 7)
+	}
+	s.bits++
+}
+
+// flush spills the buffer of s once it is a full chunk of whole bytes, unless b keeps its streams in memory.
+func (s *extStream) flush(b *extBuilder) error {
+	if b.spill == nil || len(s.buf) < extChunk || s.bits&7 != 0 {
+		return nil
+	}
+	if _, err := b.spill.Write(s.buf); err != nil {
+		return err
+	}
+	s.chunks = append(s.chunks, extSpan{b.offset, len(s.buf)})
+	b.offset += int64(len(s.buf))
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// replay calls fn with the bytes of s, reading its chunks back from r.
+func (s *extStream) replay(r io.ReaderAt, buf []byte, fn func(b []byte)) error {
+	for _, c := range s.chunks {
+		if _, err := r.ReadAt(buf[:c.length], c.offset); err != nil {
+			return err
+		}
+		fn(buf[:c.length])
+	}
+	fn(s.buf)
+	return nil
+}
+
+// write writes the trie as MarshalSections does, the streams of the levels one after the other.
+func (b *extBuilder) write(w io.Writer, r io.ReaderAt) error {
+	bw := bufio.NewWriterSize(w, sectionChunk)
+	bw.WriteString(trieMagic)
+	bw.WriteByte(formatSections)
+
+	n := b.nodes + 1
+	h := sectionHeader{Size: uint64(b.size)}
+	writeSectionHeader(bw, SectionHeader, int64(binary.Size(h)))
+	binary.Write(bw, binary.LittleEndian, h)
+
+	buf := make([]byte, extChunk)
+	bits := func(s Section, length int64, prefix, suffix []bool, stream func(l *extLevel) *extStream) error {
+		writeSectionHeader(bw, s, 8*int64(words(int(length))))
+		out := bitSink{w: bw}
+		for _, bit := range prefix {
+			out.write(boolByte(bit), 1)
+		}
+		for _, l := range b.levels {
+			s := stream(l)
+			full := s.bits &^ 7
+			err := s.replay(r, buf, func(p []byte) {
+				for _, c := range p {
+					if full == 0 {
+						out.write(c, uint(s.bits&7))
+						return
+					}
+					out.write(c, 8)
+					full -= 8
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, bit := range suffix {
+			out.write(boolByte(bit), 1)
+		}
+		out.flush()
+		return nil
+	}
+
+	// position 0 of the bitmap is the zero bit of the root, which is in the leaves and labels of level 0
+	err := bits(SectionBitmap, 2*n+1, []bool{false}, []bool{true}, func(l *extLevel) *extStream { return &l.bitmap })
+	if err != nil {
+		return err
+	}
+	err = bits(SectionLeaves, n, []bool{b.rootLeaf}, nil, func(l *extLevel) *extStream { return &l.leaves })
+	if err != nil {
+		return err
+	}
+
+	writeSectionHeader(bw, SectionLabels, n)
+	bw.WriteByte(0)
+	for _, l := range b.levels {
+		if err := l.labels.replay(r, buf, func(p []byte) { bw.Write(p) }); err != nil {
+			return err
+		}
+	}
+
+	writeSectionHeader(bw, sectionEnd, 0)
+	return bw.Flush() // the errors of bw are sticky
+}
+
+// trie returns the trie of the streams of b, which kept them in memory.
+func (b *extBuilder) trie() *SuccinctTrie {
+	n := int(b.nodes) + 1
+	t := &SuccinctTrie{size: b.size}
+	t.bitmap.Reset(2*n + 1)
+	t.leaves.Reset(n)
+	t.leaves.Set(0, b.rootLeaf)
+
+	var labels strings.Builder
+	labels.Grow(n)
+	labels.WriteByte(0)
+	pos, k := 1, 1 // position 0 of the bitmap is the zero bit of the root
+	for _, l := range b.levels {
+		for i := int64(0); i < l.bitmap.bits; i++ {
+			t.bitmap.Set(pos, l.bitmap.buf[i>>3]&(1<<(i&7)) != 0)
+			pos++
+		}
+		for i := int64(0); i < l.leaves.bits; i++ {
+			t.leaves.Set(k, l.leaves.buf[i>>3]&(1<<(i&7)) != 0)
+			k++
+		}
+		labels.Write(l.labels.buf)
+	}
+	t.bitmap.Set(pos, true)
+
+	t.nodes = labels.String()
+	t.bitmap.Init()
+	t.leaves.Init()
+	t.initLayout()
+	return t
+}
+
+// bitSink packs bits into little-endian 64-bit words.
+type bitSink struct {
+	w   *bufio.Writer
+	acc uint64
+	n   uint
+}
+
+// write writes the k low bits of c.
+func (s *bitSink) write(c byte, k uint) {
+	s.acc |= uint64(c) << s.n
+	if s.n += k; s.n >= 64 {
+		binary.Write(s.w, binary.LittleEndian, s.acc)
+		s.n -= 64
+		s.acc = uint64(c) >> (k - s.n)
+	}
+}
+
+// flush writes the last word, padded with zeros.
+func (s *bitSink) flush() {
+	if s.n > 0 {
+		binary.Write(s.w, binary.LittleEndian, s.acc)
+	}
+	s.acc, s.n = 0, 0
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// mergeRuns calls fn with the keys of the sorted runs in ascending order, as in BuildFromKeyReader.
+func mergeRuns(runs []io.Reader, fn func(key string) error) error {
+	scanners := make([]*bufio.Scanner, len(runs))
+	next := make([]func() (string, bool), len(runs))
+	for i, r := range runs {
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 1<<20)
+		scanners[i] = s
+		next[i] = func() (string, bool) {
+			for s.Scan() {
+				line := s.Text()
+				if n := len(line); n > 0 && line[n-1] == '\r' {
+					line = line[:n-1]
+				}
+				if line != "" {
+					return line, true
+				}
+			}
+			return "", false
+		}
+	}
+
+	err := mergeKeys(next, fn)
+	for _, s := range scanners {
+		if err := s.Err(); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// mergeKeys calls fn with the keys of the sorted sources in ascending order, duplicates included.
+// It fails if a source is not sorted.
+func mergeKeys(sources []func() (string, bool), fn func(key string) error) error {
+	h := make(keyHeap, 0, len(sources))
+	for i, next := range sources {
+		if key, ok := next(); ok {
+			h = append(h, keyHead{key, i})
+		}
+	}
+	heap.Init(&h)
+
+	for len(h) > 0 {
+		head := h[0]
+		if err := fn(head.key); err != nil {
+			return err
+		}
+		key, ok := sources[head.source]()
+		if !ok {
+			heap.Pop(&h)
+			continue
+		}
+		if key < head.key {
+			return fmt.Errorf("sutrie: source %d is not sorted, %q is after %q", head.source, key, head.key)
+		}
+		h[0].key = key
+		heap.Fix(&h, 0)
+	}
+	return nil
+}
+
+// keyHead is the next key of a source of mergeKeys.
+type keyHead struct {
+	key    string
+	source int
+}
+
+type keyHeap []keyHead
+
+func (h keyHeap) Len() int { return len(h) }
+
+func (h keyHeap) Less(i, j int) bool {
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].source < h[j].source
+}
+
+func (h keyHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *keyHeap) Push(x any) { *h = append(*h, x.(keyHead)) }
+
+func (h *keyHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
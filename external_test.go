@@ -0,0 +1,54 @@
+package sutrie
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExternal(t *testing.T) {
+	defer func(n int) { extChunk = n }(extChunk)
+	extChunk = 16
+
+	dict := domainKeys(3000)
+	dict = append(dict, "a", "ab", "abc", dict[0]+"/path", dict[1][:3])
+	want := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	// overlapping runs with duplicates
+	var runs []io.Reader
+	for _, part := range [][]string{dict[:1500], dict[1000:], dict[2500:], {}} {
+		keys := append([]string(nil), part...)
+		sort.Strings(keys)
+		runs = append(runs, strings.NewReader(strings.Join(keys, "\r\n")+"\n\n"))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, BuildExternal(&buf, t.TempDir(), runs...))
+	var trie SuccinctTrie
+	assert.NoError(t, trie.Unmarshal(bytes.NewReader(buf.Bytes())))
+	assert.True(t, want.Equal(&trie))
+	assert.Equal(t, want.Size(), trie.Size())
+	assert.Equal(t, want.Keys(), trie.Keys())
+	for _, key := range dict[:200] {
+		assert.True(t, trie.Contains(key), key)
+		assert.Equal(t, len(key), trie.SearchPrefix(key+"/x"))
+	}
+
+	var sections bytes.Buffer
+	assert.NoError(t, want.MarshalSections(&sections))
+	assert.Equal(t, sections.Bytes(), buf.Bytes())
+
+	buf.Reset()
+	assert.NoError(t, BuildExternal(&buf, t.TempDir()))
+	assert.NoError(t, trie.Unmarshal(&buf))
+	assert.Equal(t, 0, trie.Size())
+	assert.False(t, trie.Contains("a"))
+
+	err := BuildExternal(io.Discard, t.TempDir(), strings.NewReader("a\nc\n"), strings.NewReader("b\na\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not sorted")
+}
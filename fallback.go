@@ -0,0 +1,120 @@
+package sutrie
+
+import "strings"
+
+// Variant is a named transformation of a query key, used by the fallback searches.
+type Variant struct {
+	Name string
+
+	// Apply returns the variant of key, ok is false if the variant does not apply to key.
+	Apply func(key string) (variant string, ok bool)
+}
+
+var (
+	// VariantExact is the key itself.
+	VariantExact = Variant{"exact", func(key string) (string, bool) {
+		return key, true
+	}}
+
+	// VariantCaseFolded is the key in lower case.
+	VariantCaseFolded = Variant{"case-folded", func(key string) (string, bool) {
+		return strings.ToLower(key), true
+	}}
+
+	// VariantNormalized is the key in lower case without surrounding white space and trailing dot,
+	// which is the canonical form of a domain name.
+	VariantNormalized = Variant{"normalized", func(key string) (string, bool) {
+		return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(key)), "."), true
+	}}
+
+	// VariantPunycode is the normalized key with its non-ASCII labels converted to Punycode ("xn--").
+	VariantPunycode = Variant{"punycode", func(key string) (string, bool) {
+		key, _ = VariantNormalized.Apply(key)
+		return toASCII(key)
+	}}
+)
+
+// DefaultFallback is the fallback chain exact → case-folded → normalized → punycode.
+var DefaultFallback = []Variant{VariantExact, VariantCaseFolded, VariantNormalized, VariantPunycode}
+
+// SearchFallback tries the variants of key in order and stops at the first one that is a key under the current node.
+// It returns the index of the matching variant in variants together with the variant key, or -1 if none matches.
+// The variants share the descent of their common prefix: each one is searched from the node where it differs
+// from the previous one, so a variant producing the same key is not searched again.
+func (n Node) SearchFallback(key string, variants ...Variant) (i int, variant string) {
+	i, variant, _ = n.fallback(key, variants, false)
+	return
+}
+
+// SearchPrefixFallback is like SearchFallback with the semantic of SearchPrefix:
+// it stops at the first variant that has a prefix in the trie and also returns its lastUnmatch.
+func (n Node) SearchPrefixFallback(key string, variants ...Variant) (i int, variant string, lastUnmatch int) {
+	return n.fallback(key, variants, true)
+}
+
+// fallbackStep is the node reached by a byte of the variant being searched,
+// and the lastUnmatch of the variant up to that byte.
+type fallbackStep struct {
+	k, first, after int32
+	lastUnmatch     int
+}
+
+func (n Node) fallback(key string, variants []Variant, prefix bool) (int, string, int) {
+	if !n.Exists() {
+		return -1, "", 0
+	}
+	t := n.trie
+
+	// path is the descent of prev as far as it goes, path[d] being the node after its first d bytes
+	var buf [64]fallbackStep
+	path := append(buf[:0], fallbackStep{n.index, n.firstChild, n.afterLastChild, 0})
+	prev, started := "", false
+	for i, variant := range variants {
+		v, ok := variant.Apply(key)
+		if !ok || started && v == prev {
+			continue
+		}
+
+		if t.fold == foldUnicode {
+			// runes fold to other lengths, the variants are searched from the node
+			if prefix {
+				if lastUnmatch := n.SearchPrefix(v); lastUnmatch > 0 {
+					return i, v, lastUnmatch
+				}
+			} else if n.Search(v).Leaf() {
+				return i, v, len(v)
+			}
+			prev, started = v, true
+			continue
+		}
+
+		if d := lcp(prev, v); d+1 < len(path) {
+			path = path[:d+1]
+		}
+		for d := len(path) - 1; d < len(v); d++ {
+			s := path[d]
+			if s.first >= s.after {
+				break
+			}
+			k := t.childOf(s.k, s.first, s.after, v[d])
+			if k < 0 {
+				break
+			}
+			first, after := t.childRange(k)
+			if t.isLeaf(k) {
+				s.lastUnmatch = d + 1
+			}
+			path = append(path, fallbackStep{k, first, after, s.lastUnmatch})
+		}
+		prev, started = v, true
+
+		s := path[len(path)-1]
+		if prefix && s.lastUnmatch > 0 {
+			return i, v, s.lastUnmatch
+		}
+		if !prefix && len(path) == len(v)+1 && t.isLeaf(s.k) {
+			return i, v, len(v)
+		}
+	}
+	return -1, "", 0
+}
@@ -0,0 +1,99 @@
+package sutrie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPunycode(t *testing.T) {
+	for in, want := range map[string]string{
+		"bücher":     "bcher-kva",
+		"münchen":    "mnchen-3ya",
+		"中文":         "fiq228c",
+		"ü":          "tda",
+		"abc-ü-def":  "abc--def-95a",
+		"παράδειγμα": "hxajbheg2az3al",
+	} {
+		assert.Equal(t, want, punyEncode(in), in)
+	}
+
+	ascii, ok := toASCII("www.中文.com")
+	assert.True(t, ok)
+	assert.Equal(t, "www.xn--fiq228c.com", ascii)
+
+	_, ok = toASCII("example.com")
+	assert.False(t, ok)
+}
+
+func TestSearchFallback(t *testing.T) {
+	root := BuildSuccinctTrie([]string{"Example.com", "example.org", "xn--fiq228c.com", "test"}).Root()
+
+	for key, want := range map[string]int{
+		"Example.com":   0,
+		"EXAMPLE.ORG":   1,
+		" example.org.": 2,
+		"中文.com":        3,
+		"中文.COM.":       3,
+		"missing":       -1,
+	} {
+		i, _ := root.SearchFallback(key, DefaultFallback...)
+		assert.Equal(t, want, i, key)
+	}
+
+	i, variant := root.SearchFallback("TEST", DefaultFallback...)
+	assert.Equal(t, 1, i)
+	assert.Equal(t, "test", variant)
+	assert.Equal(t, "case-folded", DefaultFallback[i].Name)
+
+	i, variant, lastUnmatch := root.SearchPrefixFallback("TESTING", VariantExact, VariantCaseFolded)
+	assert.Equal(t, 1, i)
+	assert.Equal(t, "testing", variant)
+	assert.Equal(t, 4, lastUnmatch)
+
+	// the variants sharing their descent answer as if searched one by one
+	dict := append(domainKeys(500), "", "a", "ab", "abc")
+	variants := []Variant{VariantExact, VariantCaseFolded, VariantNormalized, VariantPunycode,
+		{"dropped", func(key string) (string, bool) { return "", false }},
+		{"parent", func(key string) (string, bool) {
+			if i := strings.LastIndexByte(key, '.'); i >= 0 {
+				return key[:i], true
+			}
+			return key, true
+		}},
+		{"prefix", func(key string) (string, bool) { return key[:len(key)/2], true }},
+	}
+	for _, opts := range [][]Option{nil, {WithCaseFolding()}, {WithUnicodeCaseFolding()}} {
+		root := BuildSuccinctTrie(append([]string(nil), dict...), opts...).Root()
+		for _, key := range append(dict[:100], "A", "ABCD", " Example.Com.", "missing.x", "abx") {
+			for _, key := range []string{key, strings.ToUpper(key), key + "x", " " + key + ". "} {
+				want, wantPrefix, lastUnmatch := -1, -1, 0
+				for i, variant := range variants {
+					v, ok := variant.Apply(key)
+					if !ok {
+						continue
+					}
+					if want < 0 && root.Search(v).Leaf() {
+						want = i
+					}
+					if l := root.SearchPrefix(v); wantPrefix < 0 && l > 0 {
+						wantPrefix, lastUnmatch = i, l
+					}
+				}
+				i, _ := root.SearchFallback(key, variants...)
+				assert.Equal(t, want, i, key)
+				i, _, l := root.SearchPrefixFallback(key, variants...)
+				assert.Equal(t, wantPrefix, i, key)
+				assert.Equal(t, lastUnmatch, l, key)
+			}
+		}
+	}
+
+	key := strings.Repeat("x", 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		root.SearchFallback(key, VariantExact, VariantExact)
+		root.SearchPrefixFallback(key, VariantExact, VariantExact)
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,118 @@
+package sutrie
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WithSuffixTruncation turns the trie into an approximate membership filter, like SuRF:
+// every key is truncated after the first byte making it unique among the keys, which drops most of the nodes.
+// With hashBits > 0, that many bits of a hash of every full key are stored as well, see MayContain.
+// hashBits must be in [0, 32]. Note that the keys found by traversal and enumeration are the truncated ones.
+func WithSuffixTruncation(hashBits int) Option {
+	return func(o *buildOptions) error {
+		if hashBits < 0 || hashBits > 32 {
+			return fmt.Errorf("%w: %d suffix hash bits, not in [0, 32]", ErrInvalidOption, hashBits)
+		}
+		o.truncated = true
+		o.hashBits = hashBits
+		return nil
+	}
+}
+
+// Truncated reports whether the trie was built WithSuffixTruncation.
+func (t *SuccinctTrie) Truncated() bool {
+	return t.truncated
+}
+
+// truncateKeys sorts dict and returns its keys truncated after the byte telling them apart from their neighbors.
+func truncateKeys(dict []string) []string {
+	sort.Strings(dict)
+
+	ret := make([]string, len(dict))
+	for i, key := range dict {
+		n := 0
+		if i > 0 {
+			n = lcp(dict[i-1], key)
+		}
+		if i+1 < len(dict) {
+			n = max(n, lcp(key, dict[i+1]))
+		}
+		ret[i] = key[:min(n+1, len(key))]
+	}
+	return ret
+}
+
+// buildTruncated builds the trie of the keys of dict truncated by truncateKeys with the hashes of the full keys.
+func buildTruncated(dict []string, o *buildOptions) (*SuccinctTrie, error) {
+	truncated := truncateKeys(dict)
+	t, err := build(truncated, o)
+	if err != nil {
+		return nil, err
+	}
+
+	t.truncated = true
+	t.hashBits = o.hashBits
+	if t.hashBits > 0 {
+		t.suffixes = make([]uint64, (t.size*t.hashBits+63)>>6)
+		for i, key := range dict {
+			t.setSuffix(t.Root().Search(truncated[i]).LeafIndex(), suffixHash(key))
+		}
+	}
+	return t, nil
+}
+
+// suffixHash is the 32-bit FNV-1a hash of key.
+func suffixHash[K string | []byte](key K) uint64 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return uint64(h)
+}
+
+func (t *SuccinctTrie) setSuffix(leaf int, hash uint64) {
+	pos := uint(leaf * t.hashBits)
+	hash &= 1<<t.hashBits - 1
+	t.suffixes[pos>>6] |= hash << (pos & 63)
+	if pos&63+uint(t.hashBits) > 64 {
+		t.suffixes[pos>>6+1] |= hash >> (64 - pos&63)
+	}
+}
+
+func (t *SuccinctTrie) suffix(leaf int) uint64 {
+	pos := uint(leaf * t.hashBits)
+	hash := t.suffixes[pos>>6] >> (pos & 63)
+	if pos&63+uint(t.hashBits) > 64 {
+		hash |= t.suffixes[pos>>6+1] << (64 - pos&63)
+	}
+	return hash & (1<<t.hashBits - 1)
+}
+
+// MayContain reports whether key may be in the trie. If the trie was built WithSuffixTruncation,
+// it is true for every key of the dictionary, and for other keys having a truncated key as prefix,
+// with a probability of 2^-hashBits when hashBits > 0. Otherwise it is exact, like Set.Contains.
+func (t *SuccinctTrie) MayContain(key string) bool {
+	if t.normalize != nil {
+		key = string(t.normalize([]byte(key)))
+	}
+	if t.fold != foldNone && t.truncated {
+		key = foldKey(key, t.fold)
+	}
+	if t.reversed {
+		key = reverse(key)
+	}
+
+	n := t.Root()
+	if !t.truncated {
+		return n.Search(key).Leaf()
+	}
+
+	for i := 0; i < len(key) && !(n.Leaf() && n.firstChild == n.afterLastChild); i++ {
+		if n = n.Next(key[i]); !n.Exists() {
+			return false
+		}
+	}
+	return n.Leaf() && (t.hashBits == 0 || t.suffix(n.LeafIndex()) == suffixHash(key)&(1<<t.hashBits-1))
+}
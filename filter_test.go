@@ -0,0 +1,73 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuffixTruncation(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	exists := make(map[string]bool)
+	var dict []string
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("user:%08d:profile", rnd.Intn(100000000))
+		dict = append(dict, key)
+		exists[key] = true
+	}
+	dict = append(dict, "user:", "user:1")
+	exists["user:"], exists["user:1"] = true, true
+
+	full := BuildSuccinctTrie(append([]string(nil), dict...))
+	for _, hashBits := range []int{0, 8} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), WithSuffixTruncation(hashBits))
+		assert.True(t, trie.Truncated())
+		assert.Less(t, len(trie.nodes), len(full.nodes)/2)
+
+		var buf bytes.Buffer
+		assert.NoError(t, trie.Marshal(&buf))
+		loaded := &SuccinctTrie{}
+		assert.NoError(t, loaded.Unmarshal(&buf))
+
+		for _, trie := range []*SuccinctTrie{trie, loaded} {
+			for _, key := range dict {
+				assert.True(t, trie.MayContain(key), key)
+			}
+
+			fp, n := 0, 0
+			for i := 0; i < 10000; i++ {
+				key := fmt.Sprintf("user:%08d:profile", rnd.Intn(100000000))
+				if !exists[key] {
+					n++
+					if trie.MayContain(key) {
+						fp++
+					}
+				}
+			}
+			if hashBits > 0 {
+				assert.Less(t, float64(fp)/float64(n), 0.01)
+			} else {
+				assert.Greater(t, fp, 0)
+			}
+
+			assert.False(t, trie.MayContain("user"))
+			assert.False(t, trie.MayContain("admin"))
+		}
+	}
+
+	assert.False(t, full.Truncated())
+	assert.True(t, full.MayContain(dict[0]))
+	assert.False(t, full.MayContain(dict[0]+"x"))
+	assert.False(t, full.MayContain(dict[0][:len(dict[0])-1]))
+
+	trie := BuildSuccinctTrie([]string{"mail.example.com", "example.org"}, WithReversedKeys(), WithSuffixTruncation(16))
+	assert.True(t, trie.MayContain("mail.example.com"))
+	assert.True(t, trie.MayContain("example.org"))
+	assert.False(t, trie.MayContain("www.example.com"))
+
+	_, err := Build([]string{}, WithSuffixTruncation(33))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
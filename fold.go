@@ -0,0 +1,152 @@
+package sutrie
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// foldMode is how keys are case folded, see WithCaseFolding.
+type foldMode uint8
+
+const (
+	foldNone foldMode = iota
+	foldASCII
+	foldUnicode
+)
+
+// WithCaseFolding folds the keys to lower case at build time and the queries on the fly, without allocating,
+// so that the trie is case-insensitive. Only ASCII letters are folded, which suits host names and HTTP headers,
+// see WithUnicodeCaseFolding for other letters. Note that the keys found by traversal and enumeration are folded.
+func WithCaseFolding() Option {
+	return func(o *buildOptions) error {
+		o.fold = foldASCII
+		return nil
+	}
+}
+
+// WithUnicodeCaseFolding is like WithCaseFolding but also folds non-ASCII letters with Unicode simple case folding.
+// As a folded letter may not have the same length, non-ASCII letters are folded by Search, SearchPrefix,
+// SearchPrefixDelim and the APIs built on them, while Next, taking a single byte, folds ASCII letters only.
+func WithUnicodeCaseFolding() Option {
+	return func(o *buildOptions) error {
+		o.fold = foldUnicode
+		return nil
+	}
+}
+
+// FoldsCase reports whether the trie was built WithCaseFolding or WithUnicodeCaseFolding.
+func (t *SuccinctTrie) FoldsCase() bool {
+	return t.fold != foldNone
+}
+
+// SearchFold is like Search but matches the ASCII letters of key in either case, so that tries of keys in one case,
+// like lowercase host names, need not be built WithCaseFolding nor queried with strings.ToLower, which allocates.
+// A letter follows the child of its own case, else the child of the other case, without backtracking:
+// a trie having keys which differ only in case may miss those reached through the other case of a letter.
+func (n Node) SearchFold(key string) Node {
+	for i := 0; i < len(key) && n.Exists(); i++ {
+		n = n.nextFold(key[i])
+	}
+	return n
+}
+
+// SearchPrefixFold is like SearchPrefix but matches the ASCII letters of key in either case, see SearchFold.
+func (n Node) SearchPrefixFold(key string) (lastUnmatch int) {
+	for i := 0; i < len(key); i++ {
+		if n = n.nextFold(key[i]); !n.Exists() {
+			break
+		}
+		if n.leaf {
+			lastUnmatch = i + 1
+		}
+	}
+	return
+}
+
+// nextFold follows b, or b in the other case if it is an ASCII letter and there is no child labeled b.
+func (n Node) nextFold(b byte) Node {
+	next := n.Next(b)
+	if !next.Exists() && (b|0x20)-'a' < 26 {
+		next = n.Next(b ^ 0x20)
+	}
+	return next
+}
+
+// foldRune returns the lower case of the simple case folding orbit of r.
+func foldRune(r rune) rune {
+	return unicode.ToLower(unicode.ToUpper(r))
+}
+
+// lowerASCII returns b in lower case if it is an ASCII letter.
+func lowerASCII(b byte) byte {
+	if b-'A' < 26 {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// foldKey returns key folded, bytes which are not valid UTF-8 being kept as is.
+func foldKey(key string, mode foldMode) string {
+	var b []byte
+	for i := 0; i < len(key); {
+		c, size := key[i], 1
+		folded := ""
+		if c < utf8.RuneSelf {
+			if l := lowerASCII(c); l != c {
+				folded = string(rune(l))
+			}
+		} else if mode == foldUnicode {
+			var r rune
+			if r, size = utf8.DecodeRuneInString(key[i:]); r != utf8.RuneError || size > 1 {
+				if f := foldRune(r); f != r {
+					folded = string(f)
+				}
+			}
+		}
+
+		if folded != "" && b == nil {
+			b = append(make([]byte, 0, len(key)), key[:i]...)
+		}
+		if b != nil {
+			if folded == "" {
+				folded = key[i : i+size]
+			}
+			b = append(b, folded...)
+		}
+		i += size
+	}
+
+	if b == nil {
+		return key
+	}
+	return string(b)
+}
+
+func foldKeys(dict []string, mode foldMode) []string {
+	ret := make([]string, len(dict))
+	for i, key := range dict {
+		ret[i] = foldKey(key, mode)
+	}
+	return ret
+}
+
+// nextFolded follows the non-ASCII rune at key[i:] folded, it returns the node reached, which may be null,
+// and the size of the rune in key. Bytes which are not valid UTF-8 are followed as is.
+func nextFolded[K string | []byte](n Node, key K, i int) (Node, int) {
+	var buf [utf8.UTFMax]byte
+	m := 0
+	for ; m < len(buf) && i+m < len(key); m++ {
+		buf[m] = key[i+m]
+	}
+
+	r, size := utf8.DecodeRune(buf[:m])
+	if r == utf8.RuneError && size <= 1 {
+		return n.Next(key[i]), 1
+	}
+
+	m = utf8.EncodeRune(buf[:], foldRune(r))
+	for j := 0; j < m && n.Exists(); j++ {
+		n = n.Next(buf[j])
+	}
+	return n, size
+}
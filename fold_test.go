@@ -0,0 +1,72 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseFolding(t *testing.T) {
+	dict := []string{"Example.COM", "content-type", "Straße", "ΣΟΦΙΑ", "\xffA"}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithCaseFolding())
+	assert.True(t, trie.FoldsCase())
+	assert.Equal(t, []string{"content-type", "example.com", "straße", "ΣΟΦΙΑ", "\xffa"}, trie.Keys())
+
+	for _, key := range []string{"example.com", "EXAMPLE.com", "Content-Type", "STRAßE", "ΣΟΦΙΑ", "\xffA", "\xffa"} {
+		assert.True(t, trie.Root().Search(key).Leaf(), key)
+	}
+	assert.False(t, trie.Root().Search("σοφια").Leaf())
+	assert.Equal(t, 11, trie.Root().SearchPrefix("EXAMPLE.COM/path"))
+	assert.Equal(t, 11, trie.Root().SearchPrefixDelim("EXAMPLE.COM.", '.'))
+	assert.True(t, trie.Root().Next('C').Exists())
+
+	unicode := BuildSuccinctTrie(append([]string(nil), dict...), WithUnicodeCaseFolding())
+	assert.Equal(t, []string{"content-type", "example.com", "straße", "σοφια", "\xffa"}, unicode.Keys())
+	for _, key := range []string{"σοφια", "ΣοΦια", "STRAßE", "\xffA"} {
+		assert.True(t, unicode.Root().Search(key).Leaf(), key)
+		assert.True(t, unicode.Root().SearchBytes([]byte(key)).Leaf(), key)
+	}
+	assert.Equal(t, len("ΣοΦια"), unicode.Root().SearchPrefix("ΣοΦια!"))
+	assert.Equal(t, len("ΣοΦια"), unicode.Root().SearchPrefixDelim("ΣοΦια/x", '/'))
+	assert.Equal(t, 0, unicode.Root().SearchPrefix("ΣοΦ"))
+
+	// the Kelvin sign folds to an ASCII letter
+	kelvin := BuildSuccinctTrie([]string{"K"}, WithUnicodeCaseFolding())
+	assert.Equal(t, []string{"k"}, kelvin.Keys())
+	assert.True(t, kelvin.Root().Search("K").Leaf())
+	assert.Equal(t, 3, kelvin.Root().SearchPrefix("K!"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, unicode.Marshal(&buf))
+	loaded := &SuccinctTrie{}
+	assert.NoError(t, loaded.Unmarshal(&buf))
+	assert.True(t, loaded.Root().Search("ΣΟΦΙΑ").Leaf())
+
+	filter := BuildSuccinctTrie([]string{"Alpha", "Beta"}, WithUnicodeCaseFolding(), WithSuffixTruncation(16))
+	assert.True(t, filter.MayContain("ALPHA"))
+	assert.True(t, BuildSuccinctTrie([]string{"Alpha"}, WithCaseFolding(), WithReversedKeys()).MatchDomainSuffix("x.ALPHA"))
+}
+
+func TestSearchFold(t *testing.T) {
+	root := BuildSuccinctTrie([]string{"example.com", "ads.example.com", "x-y"}).Root()
+
+	assert.True(t, root.SearchFold("EXAMPLE.com").Leaf())
+	assert.True(t, root.SearchFold("x-Y").Leaf())
+	assert.False(t, root.SearchFold("x-z").Exists())
+	assert.False(t, root.SearchFold("X\x0d").Exists()) // not the other case of '-'
+	assert.Equal(t, 11, root.SearchPrefixFold("Example.Com.cn"))
+	assert.Equal(t, 15, root.SearchPrefixFold("ADS.EXAMPLE.COM"))
+	assert.Equal(t, 0, root.SearchPrefixFold("ADS.EXAMPLE"))
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		root.SearchFold("ADS.Example.COM")
+		root.SearchPrefixFold("ADS.Example.COM")
+	}))
+
+	// the case of the key first, without backtracking
+	root = BuildSuccinctTrie([]string{"Ab", "ab", "AC"}).Root()
+	assert.True(t, root.SearchFold("ab").Leaf())
+	assert.True(t, root.SearchFold("AB").Leaf())
+	assert.True(t, root.SearchFold("Ac").Leaf())
+	assert.False(t, root.SearchFold("ac").Exists())
+}
@@ -0,0 +1,202 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+// FST is a trie mapping every key to a uint64 output, like the finite-state transducers of Lucene or BurntSushi's fst.
+// Outputs are accumulated along the path of the key: every node stores the smallest output below it
+// minus that of its parent, so outputs growing with the keys, like offsets into a values file,
+// leave most nodes at 0. Only nodes with a non-zero output take space, a bit of a bitmap and a packed integer.
+type FST struct {
+	trie *SuccinctTrie
+
+	// delta marks the nodes adding a non-zero output, stored in deltas in level order,
+	// final the leaves whose key has an output greater than the smallest one below it, stored in finals
+	delta, final   bitvec.Vector
+	deltas, finals packedInts
+}
+
+// BuildFST constructs an FST mapping dict[i] to outputs[i], the trie being built with opts like by Build.
+// It fails if the lengths of dict and outputs differ, if a key is given twice with different outputs,
+// or with WithSuffixTruncation, which loses the keys the outputs belong to.
+func BuildFST(dict []string, outputs []uint64, opts ...Option) (*FST, error) {
+	if len(dict) != len(outputs) {
+		return nil, errors.New("sutrie: number of outputs does not match number of keys")
+	}
+
+	var o buildOptions
+	for _, opt := range opts {
+		if opt != nil {
+			_ = opt(&o)
+		}
+	}
+	if o.truncated {
+		return nil, fmt.Errorf("%w: FST keys cannot be truncated", ErrInvalidOption)
+	}
+
+	t, err := Build(copyKeys(dict), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the output of every key on its node, then the smallest output of every subtree
+	low := make([]uint64, t.numNodes())
+	set := make([]bool, t.numNodes())
+	own := make(map[int32]uint64, t.size)
+	for i, key := range dict {
+		n := t.lookup(key)
+		if !n.leaf {
+			continue // dropped by a validator
+		}
+		if out, ok := own[n.index]; ok && out != outputs[i] {
+			return nil, fmt.Errorf("sutrie: key %q has outputs %d and %d", key, out, outputs[i])
+		}
+		own[n.index] = outputs[i]
+		low[n.index], set[n.index] = outputs[i], true
+	}
+	for i := int32(t.numNodes()) - 1; i > 0; i-- {
+		if !set[i] {
+			continue
+		}
+		p := t.node(i).Parent().index
+		if !set[p] || low[i] < low[p] {
+			low[p], set[p] = low[i], true
+		}
+	}
+
+	f := &FST{trie: t}
+	var deltas, finals []uint64
+	for i := range low {
+		if !set[i] {
+			continue
+		}
+		d := low[i]
+		if i > 0 {
+			d -= low[t.node(int32(i)).Parent().index]
+		}
+		if d != 0 {
+			f.delta.Set(i, true)
+			deltas = append(deltas, d)
+		}
+		if out, ok := own[int32(i)]; ok && out != low[i] {
+			f.final.Set(i, true)
+			finals = append(finals, out-low[i])
+		}
+	}
+	f.delta.Init()
+	f.final.Init()
+	f.deltas = newPackedInts(deltas)
+	f.finals = newPackedInts(finals)
+	return f, nil
+}
+
+// Trie returns the underlying trie.
+func (f *FST) Trie() *SuccinctTrie {
+	return f.trie
+}
+
+// Size returns the number of keys of the FST.
+func (f *FST) Size() int {
+	return f.trie.Size()
+}
+
+// output returns the output added by the node at index.
+func (f *FST) output(index int32) uint64 {
+	if !f.delta.Get(int(index)) {
+		return 0
+	}
+	return f.deltas.get(f.delta.Rank1(int(index)))
+}
+
+// finalOutput returns the output added by the leaf at index to its own key.
+func (f *FST) finalOutput(index int32) uint64 {
+	if !f.final.Get(int(index)) {
+		return 0
+	}
+	return f.finals.get(f.final.Rank1(int(index)))
+}
+
+// Get returns the output of key, and whether key is in the FST.
+func (f *FST) Get(key string) (uint64, bool) {
+	if f.trie.reversed {
+		key = reverse(key)
+	}
+
+	n := f.trie.Root()
+	out := f.output(0)
+	for i := 0; i < len(key); i++ {
+		if n = n.Next(key[i]); !n.Exists() {
+			return 0, false
+		}
+		out += f.output(n.index)
+	}
+	if !n.leaf {
+		return 0, false
+	}
+	return out + f.finalOutput(n.index), true
+}
+
+// SearchPrefix returns the length of the longest key of the FST which is a prefix of key, like Node.SearchPrefix,
+// and its output. The output is 0 if there is no such key. Unlike Get, it does not reverse key for WithReversedKeys.
+func (f *FST) SearchPrefix(key string) (lastUnmatch int, output uint64) {
+	n := f.trie.Root()
+	out := f.output(0)
+	for i := 0; i < len(key); i++ {
+		if n = n.Next(key[i]); !n.Exists() {
+			break
+		}
+		out += f.output(n.index)
+		if n.leaf {
+			lastUnmatch, output = i+1, out+f.finalOutput(n.index)
+		}
+	}
+	return
+}
+
+// packedInts is an immutable array of integers packed in the bits of the largest one.
+type packedInts struct {
+	width uint
+	words []uint64
+}
+
+func newPackedInts(values []uint64) packedInts {
+	var all uint64
+	for _, v := range values {
+		all |= v
+	}
+	return packInts(values, uint(bits.Len64(all)))
+}
+
+// packInts packs values width bits each, width being at least the bit length of the largest one.
+func packInts(values []uint64, width uint) packedInts {
+	p := packedInts{width: width}
+	if p.width == 0 {
+		return p
+	}
+	p.words = make([]uint64, (uint(len(values))*p.width+63)>>6)
+	for i, v := range values {
+		pos := uint(i) * p.width
+		p.words[pos>>6] |= v << (pos & 63)
+		if pos&63+p.width > 64 {
+			p.words[pos>>6+1] |= v >> (64 - pos&63)
+		}
+	}
+	return p
+}
+
+func (p packedInts) get(i int) uint64 {
+	if p.width == 0 {
+		return 0
+	}
+	pos := uint(i) * p.width
+	v := p.words[pos>>6] >> (pos & 63)
+	if pos&63+p.width > 64 {
+		v |= p.words[pos>>6+1] << (64 - pos&63)
+	}
+	return v & (1<<p.width - 1)
+}
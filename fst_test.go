@@ -0,0 +1,90 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFST(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	want := make(map[string]uint64)
+	for i := 0; i < 20000; i++ {
+		want[fmt.Sprintf("%x", rnd.Intn(1<<20))] = rnd.Uint64()
+	}
+	want["a"], want["ab"], want["abc"] = 5, 3, 7
+
+	var dict []string
+	var outputs []uint64
+	for key, out := range want {
+		dict = append(dict, key)
+		outputs = append(outputs, out)
+	}
+	dict, outputs = append(dict, "ab"), append(outputs, 3)
+
+	for _, opts := range [][]Option{nil, {WithReversedKeys()}} {
+		f, err := BuildFST(dict, outputs, opts...)
+		assert.NoError(t, err)
+		assert.Equal(t, len(want), f.Size())
+		for key, out := range want {
+			got, ok := f.Get(key)
+			assert.True(t, ok, key)
+			assert.Equal(t, out, got, key)
+		}
+		for _, key := range []string{"", "z", "abcd", "fffff0"} {
+			_, ok := f.Get(key)
+			assert.False(t, ok, key)
+		}
+	}
+
+	f, _ := BuildFST(dict, outputs)
+	n, out := f.SearchPrefix("abz")
+	assert.Equal(t, 2, n)
+	assert.Equal(t, uint64(3), out)
+	n, out = f.SearchPrefix("z")
+	assert.Equal(t, 0, n)
+	assert.Equal(t, uint64(0), out)
+
+	_, err := BuildFST([]string{"a", "a"}, []uint64{1, 2})
+	assert.Error(t, err)
+	_, err = BuildFST([]string{"a"}, nil)
+	assert.Error(t, err)
+	_, err = BuildFST([]string{"a"}, []uint64{1}, WithSuffixTruncation(0))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestFSTOffsets(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	dict := make([]string, 10000)
+	for i := range dict {
+		dict[i] = fmt.Sprintf("key:%06d", rnd.Intn(1000000))
+	}
+	sort.Strings(dict)
+
+	// offsets of the values in a file, growing with the keys
+	outputs := make([]uint64, len(dict))
+	var offset uint64
+	for i := range dict {
+		if i > 0 && dict[i] == dict[i-1] {
+			outputs[i] = outputs[i-1]
+			continue
+		}
+		outputs[i] = offset
+		offset += uint64(1 + rnd.Intn(100))
+	}
+
+	f, err := BuildFST(dict, outputs)
+	assert.NoError(t, err)
+	for i, key := range dict {
+		out, ok := f.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, outputs[i], out)
+	}
+
+	// no key is a prefix of another, so the outputs are all on the edges
+	assert.Zero(t, f.final.Ones())
+	assert.Less(t, f.delta.Ones(), len(f.trie.nodes)/2)
+}
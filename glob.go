@@ -0,0 +1,125 @@
+package sutrie
+
+import "sort"
+
+// Glob returns all keys under the current node matching pattern, in lexicographic order unless changed by opts.
+// The returned keys are relative to the current node.
+// In pattern, '*' matches any sequence of bytes (including the empty one) and '?' matches exactly one byte,
+// every other byte matches itself. Branches of the trie that cannot match are pruned.
+func (n Node) Glob(pattern string, opts ...IterOption) (keys []string) {
+	n.GlobSeq(pattern, opts...)(func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
+
+// GlobSeq is like Glob but yields the keys one at a time, see KeysSeq.
+func (n Node) GlobSeq(pattern string, opts ...IterOption) func(yield func(key string) bool) {
+	return func(yield func(key string) bool) {
+		if !n.Exists() {
+			return
+		}
+
+		g := globber{pattern: pattern, order: newIterOrder(opts)}
+		g.walk(n, g.closure(nil, 0), func(key []byte) bool {
+			return yield(string(key))
+		})
+	}
+}
+
+type globber struct {
+	pattern string
+	order   iterOrder
+	key     []byte
+}
+
+// closure adds state i to states, following '*' which may match the empty sequence.
+func (g *globber) closure(states []int, i int) []int {
+	for {
+		for _, s := range states {
+			if s == i {
+				return states
+			}
+		}
+		states = append(states, i)
+		if i == len(g.pattern) || g.pattern[i] != '*' {
+			return states
+		}
+		i++
+	}
+}
+
+func (g *globber) step(states []int, b byte) (next []int) {
+	for _, s := range states {
+		if s == len(g.pattern) {
+			continue
+		}
+		switch c := g.pattern[s]; {
+		case c == '*':
+			next = g.closure(next, s)
+		case c == '?' || c == b:
+			next = g.closure(next, s+1)
+		}
+	}
+	return
+}
+
+// literals returns the bytes a transition can happen on, in iteration order,
+// ok is false if some state accepts any byte.
+func (g *globber) literals(states []int) (lits []byte, ok bool) {
+	for _, s := range states {
+		if s == len(g.pattern) {
+			continue
+		}
+		c := g.pattern[s]
+		if c == '*' || c == '?' {
+			return nil, false
+		}
+		lits = append(lits, c)
+	}
+	sort.Slice(lits, func(i, j int) bool { return g.order.lessByte(lits[i], lits[j]) })
+	return lits, true
+}
+
+// walk calls emit with the keys matching from n until it returns false, in which case walk does too.
+func (g *globber) walk(n Node, states []int, emit func([]byte) bool) bool {
+	if len(states) == 0 {
+		return true
+	}
+
+	accept := false
+	for _, s := range states {
+		accept = accept || s == len(g.pattern) && n.Leaf()
+	}
+	if accept && !g.order.reverse && !emit(g.key) {
+		return false
+	}
+
+	visit := func(k int32) bool {
+		b := n.trie.label(k)
+		if next := g.step(states, b); len(next) > 0 {
+			g.key = append(g.key, b)
+			ok := g.walk(n.next(k), next, emit)
+			g.key = g.key[:len(g.key)-1]
+			return ok
+		}
+		return true
+	}
+
+	ok := true
+	if lits, literal := g.literals(states); literal {
+		for i, b := range lits {
+			if i > 0 && lits[i-1] == b {
+				continue
+			}
+			if k := n.child(b); k != -1 && !visit(k) {
+				ok = false
+				break
+			}
+		}
+	} else {
+		ok = g.order.children(n, visit)
+	}
+	return ok && (!accept || !g.order.reverse || emit(g.key))
+}
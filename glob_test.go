@@ -0,0 +1,30 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlob(t *testing.T) {
+	dict := []string{
+		"a.cdn.x.com",
+		"b.cdn.y.com",
+		"cdn.z.com",
+		"a.cdn.x.org",
+		"www.example.com",
+		"ab",
+		"abc",
+	}
+	root := BuildSuccinctTrie(dict).Root()
+
+	assert.Equal(t, []string{"a.cdn.x.com", "b.cdn.y.com"}, root.Glob("*.cdn.*.com"))
+	assert.Equal(t, []string{"a.cdn.x.com", "a.cdn.x.org", "ab", "abc"}, root.Glob("a*"))
+	assert.Equal(t, []string{"ab"}, root.Glob("a?"))
+	assert.Equal(t, []string{"abc"}, root.Glob("a?c"))
+	assert.Equal(t, []string{"www.example.com"}, root.Glob("www.example.com"))
+	assert.Len(t, root.Glob("*"), len(dict))
+	assert.Empty(t, root.Glob("zz*"))
+
+	assert.Equal(t, []string{"", "c"}, root.Search("ab").Glob("*"))
+}
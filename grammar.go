@@ -0,0 +1,101 @@
+package sutrie
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// InvalidKey is a key rejected by the key grammar of a build.
+type InvalidKey struct {
+	Key string
+	Err error
+}
+
+// InvalidKeysError is returned by Build when some keys violate the key grammar.
+type InvalidKeysError struct {
+	Keys []InvalidKey
+}
+
+func (e *InvalidKeysError) Error() string {
+	if len(e.Keys) == 0 {
+		return "sutrie: invalid keys"
+	}
+	return fmt.Sprintf("sutrie: %d invalid keys, first %q: %v", len(e.Keys), e.Keys[0].Key, e.Keys[0].Err)
+}
+
+// WithKeyValidator requires every key to be accepted by validate, that is validate returns nil.
+// By default the build fails with an *InvalidKeysError listing all offenders, see DropInvalidKeys.
+func WithKeyValidator(validate func(key string) error) Option {
+	return func(o *buildOptions) error {
+		if validate == nil {
+			return fmt.Errorf("%w: nil key validator", ErrInvalidOption)
+		}
+		o.validators = append(o.validators, validate)
+		return nil
+	}
+}
+
+// WithKeyPattern requires every key to match re, see WithKeyValidator.
+// Use anchors in re to constrain whole keys.
+func WithKeyPattern(re *regexp.Regexp) Option {
+	if re == nil {
+		return func(o *buildOptions) error {
+			return fmt.Errorf("%w: nil key pattern", ErrInvalidOption)
+		}
+	}
+	return WithKeyValidator(func(key string) error {
+		if !re.MatchString(key) {
+			return fmt.Errorf("does not match %s", re)
+		}
+		return nil
+	})
+}
+
+// DropInvalidKeys leaves out keys violating the key grammar instead of failing the build.
+// If report is not nil, it is called with every dropped key.
+func DropInvalidKeys(report func(key string, err error)) Option {
+	return func(o *buildOptions) error {
+		o.dropBad = true
+		o.onInvalid = report
+		return nil
+	}
+}
+
+// validateKeys returns the valid keys of dict. dict is returned as is when there is nothing to drop.
+func (o *buildOptions) validateKeys(dict []string) ([]string, error) {
+	if len(o.validators) == 0 {
+		return dict, nil
+	}
+
+	var invalid []InvalidKey
+	valid := dict[:0:0]
+	for i, key := range dict {
+		var err error
+		for _, validate := range o.validators {
+			if err = validate(key); err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			if invalid == nil && o.dropBad {
+				valid = append(valid, dict[:i]...)
+			}
+			invalid = append(invalid, InvalidKey{key, err})
+			if o.onInvalid != nil {
+				o.onInvalid(key, err)
+			}
+		} else if invalid != nil && o.dropBad {
+			valid = append(valid, key)
+		}
+	}
+
+	switch {
+	case invalid == nil:
+		return dict, nil
+	case o.dropBad:
+		return valid, nil
+	default:
+		return nil, &InvalidKeysError{Keys: invalid}
+	}
+}
@@ -0,0 +1,41 @@
+package sutrie
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyGrammar(t *testing.T) {
+	domain := regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*$`)
+	dict := []string{"example.com", "bad domain", "a.b", "UPPER.com", ""}
+
+	_, err := Build(dict, WithKeyPattern(domain))
+	var invalid *InvalidKeysError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Len(t, invalid.Keys, 3)
+	assert.Equal(t, "bad domain", invalid.Keys[0].Key)
+	assert.Equal(t, "UPPER.com", invalid.Keys[1].Key)
+
+	var dropped []string
+	trie, err := Build(dict, WithKeyPattern(domain), DropInvalidKeys(func(key string, err error) {
+		dropped = append(dropped, key)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bad domain", "UPPER.com", ""}, dropped)
+	assert.Equal(t, []string{"a.b", "example.com"}, trie.Keys())
+
+	tooLong := errors.New("too long")
+	trie = BuildSuccinctTrie([]string{"abc", "abcdef", "x"}, DropInvalidKeys(nil), WithKeyValidator(func(key string) error {
+		if len(key) > 3 {
+			return tooLong
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"abc", "x"}, trie.Keys())
+
+	assert.Panics(t, func() { BuildSuccinctTrie(dict, WithKeyPattern(domain)) })
+	assert.Equal(t, "sutrie: invalid keys", (&InvalidKeysError{}).Error())
+}
@@ -0,0 +1,131 @@
+package sutrie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// hostsLocal are the names of the local host which hosts files map to themselves, they are not blocked domains.
+var hostsLocal = map[string]bool{
+	"localhost": true, "localhost.localdomain": true, "local": true, "broadcasthost": true,
+	"ip6-localhost": true, "ip6-loopback": true, "ip6-localnet": true, "ip6-mcastprefix": true,
+	"ip6-allnodes": true, "ip6-allrouters": true, "ip6-allhosts": true, "0.0.0.0": true,
+}
+
+// ParseHosts returns the domains of a blocklist in the format of /etc/hosts, one address followed by host names
+// per line, like "0.0.0.0 ads.example.com tracker.example.com". Lines of a single domain without an address
+// are accepted too, as many lists are published that way. Comments from "#", blank lines and the names of
+// the local host, like "localhost", are skipped. Domains are lower-cased and stripped of their trailing dot.
+func ParseHosts(r io.Reader) ([]string, error) {
+	var domains []string
+	err := scanLines(r, func(n int, line string) error {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil
+		}
+		if _, err := netip.ParseAddr(fields[0]); err == nil {
+			fields = fields[1:]
+		} else if len(fields) > 1 {
+			return fmt.Errorf("sutrie: line %d: invalid hosts entry %q", n, line)
+		}
+
+		for _, name := range fields {
+			domain, ok := cleanDomain(name)
+			if !ok {
+				return fmt.Errorf("sutrie: line %d: invalid domain %q", n, name)
+			}
+			if !hostsLocal[domain] {
+				domains = append(domains, domain)
+			}
+		}
+		return nil
+	})
+	return domains, err
+}
+
+// ParseDnsmasq returns the domains of the dnsmasq configuration directives "address=/domain/[address]",
+// "server=/domain/[server]" and "local=/domain/", a directive listing any number of domains between slashes
+// like "address=/example.com/example.org/0.0.0.0". Other directives, comment lines starting with "#", blank
+// lines and the "#" wildcard matching every domain are skipped. Domains are cleaned up like with ParseHosts.
+func ParseDnsmasq(r io.Reader) ([]string, error) {
+	var domains []string
+	err := scanLines(r, func(n int, line string) error {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			return nil
+		}
+		directive, value, ok := strings.Cut(line, "=")
+		if !ok || directive != "address" && directive != "server" && directive != "local" || !strings.HasPrefix(value, "/") {
+			return nil // not for specific domains, like a default upstream server
+		}
+
+		parts := strings.Split(value, "/")
+		if len(parts) < 3 {
+			return fmt.Errorf("sutrie: line %d: invalid dnsmasq directive %q", n, line)
+		}
+		for _, name := range parts[1 : len(parts)-1] {
+			if name == "#" {
+				continue
+			}
+			domain, ok := cleanDomain(name)
+			if !ok {
+				return fmt.Errorf("sutrie: line %d: invalid domain %q", n, name)
+			}
+			domains = append(domains, domain)
+		}
+		return nil
+	})
+	return domains, err
+}
+
+// BuildHosts builds a trie of the domains of a hosts file, see ParseHosts.
+// The trie is built WithReversedKeys, query it with MatchDomainSuffix.
+func BuildHosts(r io.Reader, opts ...Option) (*SuccinctTrie, error) {
+	domains, err := ParseHosts(r)
+	if err != nil {
+		return nil, err
+	}
+	return buildDomains(domains, opts)
+}
+
+// BuildDnsmasq builds a trie of the domains of a dnsmasq configuration, see ParseDnsmasq and BuildHosts.
+func BuildDnsmasq(r io.Reader, opts ...Option) (*SuccinctTrie, error) {
+	domains, err := ParseDnsmasq(r)
+	if err != nil {
+		return nil, err
+	}
+	return buildDomains(domains, opts)
+}
+
+// buildDomains builds a trie of domains WithReversedKeys, an empty list being an empty trie.
+func buildDomains(domains []string, opts []Option) (*SuccinctTrie, error) {
+	if domains == nil {
+		domains = []string{}
+	}
+	return Build(domains, append(opts, WithReversedKeys())...)
+}
+
+// scanLines calls fn with every line of r and its number from 1.
+func scanLines(r io.Reader, fn func(n int, line string) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		if err := fn(n, s.Text()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// cleanDomain returns name lower-cased without its trailing dot, ok is false if it is not a plausible domain.
+func cleanDomain(name string) (domain string, ok bool) {
+	domain = strings.ToLower(strings.TrimSuffix(name, "."))
+	if domain == "" || strings.ContainsAny(domain, "/=:") || strings.Contains(domain, "..") || domain[0] == '.' {
+		return "", false
+	}
+	return domain, true
+}
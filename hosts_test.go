@@ -0,0 +1,71 @@
+package sutrie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHosts(t *testing.T) {
+	hosts := `# Title: test list
+127.0.0.1 localhost
+::1 ip6-localhost ip6-loopback
+0.0.0.0 0.0.0.0
+
+0.0.0.0 Ads.Example.com tracker.example.com. # trailing comment
+	::	metrics.example.net
+plain.example.org
+192.168.0.1
+`
+	domains, err := ParseHosts(strings.NewReader(hosts))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ads.example.com", "tracker.example.com", "metrics.example.net", "plain.example.org"}, domains)
+
+	trie, err := BuildHosts(strings.NewReader(hosts))
+	assert.NoError(t, err)
+	assert.True(t, trie.Reversed())
+	assert.Equal(t, 4, trie.Size())
+	assert.True(t, trie.MatchDomainSuffix("x.ads.example.com"))
+	assert.False(t, trie.MatchDomainSuffix("example.com"))
+	assert.False(t, trie.MatchDomainSuffix("localhost"))
+
+	_, err = ParseHosts(strings.NewReader("0.0.0.0 ok.com\nnot-an-ip bad.com\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line 2")
+	}
+	_, err = ParseHosts(strings.NewReader("0.0.0.0 a..com\n"))
+	assert.Error(t, err)
+
+	empty, err := BuildHosts(strings.NewReader("# nothing\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, empty.Size())
+}
+
+func TestDnsmasq(t *testing.T) {
+	conf := `# blocklist
+cache-size=1000
+address=/ads.example.com/0.0.0.0
+address=/a.example.org/B.example.org/
+  server=/tracker.example.net/
+local=/intranet./
+address=/#/127.0.0.1
+server=8.8.8.8
+`
+	domains, err := ParseDnsmasq(strings.NewReader(conf))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ads.example.com", "a.example.org", "b.example.org", "tracker.example.net", "intranet"}, domains)
+
+	trie, err := BuildDnsmasq(strings.NewReader(conf))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, trie.Size())
+	assert.True(t, trie.MatchDomainSuffix("www.b.example.org"))
+	assert.False(t, trie.MatchDomainSuffix("example.org"))
+
+	_, err = ParseDnsmasq(strings.NewReader("address=/example.com\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line 1")
+	}
+	_, err = ParseDnsmasq(strings.NewReader("address=//0.0.0.0\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package sutrie
+
+import "sort"
+
+// InvertedIndex maps each distinct value of a parallel value slice to the leaves holding it,
+// so keys can be enumerated by value without scanning all leaves.
+// It is meant for values of small cardinality, like categories or actions.
+type InvertedIndex[V comparable] struct {
+	trie     *SuccinctTrie
+	postings map[V][]uint32
+}
+
+// NewInvertedIndex indexes values, where values[i] is the value of the leaf whose LeafIndex is i.
+// It panics if len(values) is not the size of the trie.
+func NewInvertedIndex[V comparable](t *SuccinctTrie, values []V) *InvertedIndex[V] {
+	if len(values) != t.Size() {
+		panic("sutrie: number of values does not match number of keys")
+	}
+
+	x := &InvertedIndex[V]{trie: t, postings: make(map[V][]uint32)}
+	for i, v := range values {
+		x.postings[v] = append(x.postings[v], uint32(i))
+	}
+	return x
+}
+
+// Count returns the number of keys with value v.
+func (x *InvertedIndex[V]) Count(v V) int {
+	return len(x.postings[v])
+}
+
+// LeavesWithValue returns the ascending leaf indexes of the keys with value v.
+// The returned slice is owned by the index and must not be modified.
+func (x *InvertedIndex[V]) LeavesWithValue(v V) []uint32 {
+	return x.postings[v]
+}
+
+// KeysWithValue returns the keys with value v, in lexicographic order unless changed by opts.
+func (x *InvertedIndex[V]) KeysWithValue(v V, opts ...IterOption) []string {
+	leaves := x.postings[v]
+	keys := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		keys[i] = x.trie.KeyAt(int(leaf))
+	}
+
+	o := newIterOrder(opts)
+	sort.Slice(keys, func(i, j int) bool { return o.less(keys[i], keys[j]) })
+	return keys
+}
+
+// Values returns the distinct values in the index, in no particular order.
+func (x *InvertedIndex[V]) Values() []V {
+	values := make([]V, 0, len(x.postings))
+	for v := range x.postings {
+		values = append(values, v)
+	}
+	return values
+}
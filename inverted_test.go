@@ -0,0 +1,36 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvertedIndex(t *testing.T) {
+	categories := map[string]int{
+		"ads.example.com":   7,
+		"track.example.com": 7,
+		"news.example.org":  1,
+		"mail.example.net":  2,
+	}
+
+	var dict []string
+	for key := range categories {
+		dict = append(dict, key)
+	}
+	trie := BuildSuccinctTrie(dict)
+
+	values := make([]int, trie.Size())
+	for key, category := range categories {
+		values[trie.Root().Search(key).LeafIndex()] = category
+	}
+
+	x := NewInvertedIndex(trie, values)
+	assert.ElementsMatch(t, []string{"ads.example.com", "track.example.com"}, x.KeysWithValue(7))
+	assert.Equal(t, []string{"news.example.org"}, x.KeysWithValue(1))
+	assert.Empty(t, x.KeysWithValue(3))
+	assert.Equal(t, 2, x.Count(7))
+	assert.ElementsMatch(t, []int{1, 2, 7}, x.Values())
+
+	assert.Panics(t, func() { NewInvertedIndex(trie, values[1:]) })
+}
@@ -0,0 +1,93 @@
+package sutrie
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// IPTrie is a set of IP prefixes answering longest-prefix-match queries, like a routing table.
+// Every prefix is stored as a key of one byte for the address family followed by one byte per bit of the prefix.
+type IPTrie struct {
+	trie *SuccinctTrie
+}
+
+// BuildIPTrie constructs an IPTrie of cidrs, in CIDR notation like "10.0.0.0/8" or "2001:db8::/32".
+// A bare address is a prefix of its full length, and the host bits of a prefix are ignored.
+// IPv4-mapped IPv6 prefixes are unmapped.
+func BuildIPTrie(cidrs []string) (*IPTrie, error) {
+	keys := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		var p netip.Prefix
+		var err error
+		if strings.Contains(cidr, "/") {
+			p, err = netip.ParsePrefix(cidr)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(cidr); err == nil {
+				p = netip.PrefixFrom(addr, addr.BitLen())
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sutrie: invalid CIDR %q: %w", cidr, err)
+		}
+		keys = append(keys, ipKey(unmapPrefix(p)))
+	}
+	return &IPTrie{BuildSuccinctTrie(keys)}, nil
+}
+
+// unmapPrefix returns p with an IPv4-mapped IPv6 address converted to IPv4.
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if !p.Addr().Is4In6() {
+		return p
+	}
+	return netip.PrefixFrom(p.Addr().Unmap(), max(p.Bits()-96, 0))
+}
+
+// ipKey returns the key of p masked.
+func ipKey(p netip.Prefix) string {
+	addr := p.Addr().AsSlice()
+	key := make([]byte, 1+p.Bits())
+	key[0] = byte(len(addr))
+	for i := 0; i < p.Bits(); i++ {
+		key[1+i] = addr[i>>3] >> (7 - i&7) & 1
+	}
+	return string(key)
+}
+
+// Size returns the number of distinct prefixes.
+func (t *IPTrie) Size() int {
+	return t.trie.Size()
+}
+
+// Lookup returns the longest prefix containing ip, ok being false if there is none.
+func (t *IPTrie) Lookup(ip netip.Addr) (prefix netip.Prefix, ok bool) {
+	ip = ip.Unmap()
+	if !ip.IsValid() {
+		return netip.Prefix{}, false
+	}
+
+	addr := ip.AsSlice()
+	n := t.trie.Root().Next(byte(len(addr)))
+	bits := -1
+	if n.leaf {
+		bits = 0
+	}
+	for i := 0; i < ip.BitLen() && n.Exists(); i++ {
+		if n = n.Next(addr[i>>3] >> (7 - i&7) & 1); n.leaf {
+			bits = i + 1
+		}
+	}
+
+	if bits < 0 {
+		return netip.Prefix{}, false
+	}
+	prefix, _ = ip.Prefix(bits)
+	return prefix, true
+}
+
+// Contains reports whether ip is in any of the prefixes.
+func (t *IPTrie) Contains(ip netip.Addr) bool {
+	_, ok := t.Lookup(ip)
+	return ok
+}
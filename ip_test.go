@@ -0,0 +1,47 @@
+package sutrie
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPTrie(t *testing.T) {
+	trie, err := BuildIPTrie([]string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3", "192.168.1.77/24", "2001:db8::/32", "::ffff:172.16.0.0/108"})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, trie.Size())
+
+	for ip, want := range map[string]string{
+		"10.2.3.4":        "10.0.0.0/8",
+		"10.1.9.9":        "10.1.0.0/16",
+		"10.1.2.3":        "10.1.2.3/32",
+		"::ffff:10.1.2.3": "10.1.2.3/32",
+		"192.168.1.1":     "192.168.1.0/24",
+		"172.16.200.1":    "172.16.0.0/12",
+		"2001:db8:1::1":   "2001:db8::/32",
+		"11.0.0.1":        "",
+		"2001:db9::1":     "",
+		"::a00:1":         "",
+		"192.168.2.1":     "",
+	} {
+		prefix, ok := trie.Lookup(netip.MustParseAddr(ip))
+		assert.Equal(t, want != "", ok, ip)
+		if ok {
+			assert.Equal(t, netip.MustParsePrefix(want), prefix, ip)
+		}
+	}
+	assert.False(t, trie.Contains(netip.Addr{}))
+
+	all, err := BuildIPTrie([]string{"0.0.0.0/0"})
+	assert.NoError(t, err)
+	prefix, ok := all.Lookup(netip.MustParseAddr("1.2.3.4"))
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParsePrefix("0.0.0.0/0"), prefix)
+	assert.False(t, all.Contains(netip.MustParseAddr("::1")))
+
+	_, err = BuildIPTrie([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = BuildIPTrie([]string{"not an ip"})
+	assert.Error(t, err)
+}
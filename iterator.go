@@ -0,0 +1,164 @@
+package sutrie
+
+import "strings"
+
+// Iterator is a stateful iterator over the keys of a trie in byte-lexicographic order, like LevelDB iterators,
+// for merge-joins with other sorted sources without materializing the keys. It is positioned between two keys,
+// Next returning the one after and Prev the one before, and steps from a key to the next one in amortized
+// constant time. Keys are yielded as stored, that is reversed for tries built WithReversedKeys.
+// An Iterator is not safe for concurrent use.
+type Iterator struct {
+	prefix string // the key of the first node of path
+	path   []Node // the nodes of key, from the root or the node of prefix
+	key    []byte
+	before bool // whether the position is before the key of the top of path in preorder, or after it
+}
+
+// Iterator returns an iterator positioned before the first key of the trie.
+func (t *SuccinctTrie) Iterator() *Iterator {
+	it := &Iterator{}
+	it.reset(t.Root())
+	return it
+}
+
+// PrefixIterator returns an iterator over the keys of the trie starting with prefix, positioned before the first one.
+// With SeekToLast and Prev, it yields the greatest completions of prefix first.
+func (t *SuccinctTrie) PrefixIterator(prefix string) *Iterator {
+	it := &Iterator{prefix: prefix}
+	it.reset(t.Root().Search(prefix))
+	return it
+}
+
+func (it *Iterator) reset(root Node) {
+	it.path = append(it.path[:0], root)
+	it.key = append(it.key[:0], it.prefix...)
+	it.before = true
+}
+
+func (it *Iterator) top() Node {
+	return it.path[len(it.path)-1]
+}
+
+func (it *Iterator) push(n Node) {
+	it.path = append(it.path, n)
+	it.key = append(it.key, n.trie.label(n.index))
+}
+
+func (it *Iterator) pop() Node {
+	n := it.top()
+	it.path = it.path[:len(it.path)-1]
+	it.key = it.key[:len(it.key)-1]
+	return n
+}
+
+// Seek positions the iterator before the smallest key greater than or equal to key,
+// and so after the largest key less than key.
+func (it *Iterator) Seek(key string) {
+	it.reset(it.path[0])
+	if !it.path[0].Exists() {
+		return
+	}
+
+	if !strings.HasPrefix(key, it.prefix) {
+		if key > it.prefix {
+			it.SeekToLast()
+		}
+		return
+	}
+	for i := len(it.prefix); i < len(key); i++ {
+		n := it.top()
+		if next := n.Next(key[i]); next.Exists() {
+			it.push(next)
+			continue
+		}
+
+		// the keys under n preceding the missing child are less than key, the others greater
+		if k := n.lowerBound(key[i], false); k < n.afterLastChild {
+			it.push(n.next(k))
+		} else {
+			it.pushLast()
+			it.before = false
+		}
+		return
+	}
+}
+
+// SeekToLast positions the iterator after the last key.
+func (it *Iterator) SeekToLast() {
+	it.reset(it.path[0])
+	it.pushLast()
+	it.before = false
+}
+
+// pushLast moves the iterator down to the last node in preorder under the top of its path.
+func (it *Iterator) pushLast() {
+	for n := it.top(); n.firstChild < n.afterLastChild; n = it.top() {
+		it.push(n.next(n.afterLastChild - 1))
+	}
+}
+
+// Next returns the key after the position of the iterator and moves past it, ok is false at the end.
+func (it *Iterator) Next() (key string, ok bool) {
+	for {
+		if it.before {
+			it.before = false
+			if it.top().leaf {
+				return string(it.key), true
+			}
+		}
+		if !it.advance() {
+			return "", false
+		}
+		it.before = true
+	}
+}
+
+// advance moves the iterator to the next node in preorder, or reports false and stays after the last one.
+func (it *Iterator) advance() bool {
+	if n := it.top(); n.firstChild < n.afterLastChild {
+		it.push(n.next(n.firstChild))
+		return true
+	}
+
+	for len(it.path) > 1 {
+		child := it.pop()
+		if parent := it.top(); child.index+1 < parent.afterLastChild {
+			it.push(parent.next(child.index + 1))
+			return true
+		}
+	}
+
+	// back to the last node, the path being restored from the root
+	it.pushLast()
+	return false
+}
+
+// Prev returns the key before the position of the iterator and moves before it, ok is false at the beginning.
+func (it *Iterator) Prev() (key string, ok bool) {
+	for {
+		if !it.before {
+			it.before = true
+			if it.top().leaf {
+				return string(it.key), true
+			}
+		}
+		if !it.retreat() {
+			return "", false
+		}
+		it.before = false
+	}
+}
+
+// retreat moves the iterator to the previous node in preorder, or reports false at the root.
+func (it *Iterator) retreat() bool {
+	if len(it.path) == 1 {
+		return false
+	}
+
+	child := it.pop()
+	if parent := it.top(); child.index > parent.firstChild {
+		it.push(parent.next(child.index - 1))
+		it.pushLast()
+	}
+	return true
+}
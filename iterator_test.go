@@ -0,0 +1,142 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterator(t *testing.T) {
+	keys := []string{"a", "ab", "abc", "b", "bcd", "bce", "x", "中文"}
+	trie := BuildSuccinctTrie(append([]string(nil), keys...))
+
+	var got []string
+	it := trie.Iterator()
+	for key, ok := it.Next(); ok; key, ok = it.Next() {
+		got = append(got, key)
+	}
+	assert.Equal(t, keys, got)
+	_, ok := it.Next()
+	assert.False(t, ok)
+
+	// and backwards from the end
+	got = got[:0]
+	for key, ok := it.Prev(); ok; key, ok = it.Prev() {
+		got = append(got, key)
+	}
+	assert.Equal(t, trie.Keys(Reverse()), got)
+	_, ok = it.Prev()
+	assert.False(t, ok)
+
+	for _, c := range []struct {
+		seek, next, prev string
+	}{
+		{"", "a", ""},
+		{"a", "a", ""},
+		{"aa", "ab", "a"},
+		{"abc", "abc", "ab"},
+		{"abcd", "b", "abc"},
+		{"bc", "bcd", "b"},
+		{"bcz", "x", "bce"},
+		{"c", "x", "bce"},
+		{"y", "中文", "x"},
+		{"\xff", "", "中文"},
+	} {
+		it.Seek(c.seek)
+		next, _ := it.Next()
+		assert.Equal(t, c.next, next, c.seek)
+		it.Seek(c.seek)
+		prev, _ := it.Prev()
+		assert.Equal(t, c.prev, prev, c.seek)
+	}
+
+	// Prev after Next returns the same key
+	it.Seek("b")
+	it.Next()
+	key, _ := it.Next()
+	assert.Equal(t, "bcd", key)
+	key, _ = it.Prev()
+	assert.Equal(t, "bcd", key)
+	key, _ = it.Prev()
+	assert.Equal(t, "b", key)
+
+	_, ok = BuildSuccinctTrie(nil).Iterator().Next()
+	assert.False(t, ok)
+}
+
+func TestIteratorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var keys []string
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, fmt.Sprintf("%x", r.Intn(1<<20)))
+	}
+	trie := BuildSuccinctTrie(append([]string(nil), keys...))
+	sorted := trie.Keys()
+
+	it := trie.Iterator()
+	for i := 0; i < 200; i++ {
+		seek := fmt.Sprintf("%x", r.Intn(1<<20))
+		j := sort.SearchStrings(sorted, seek)
+
+		it.Seek(seek)
+		for k := j; k < len(sorted) && k < j+5; k++ {
+			key, ok := it.Next()
+			assert.True(t, ok)
+			assert.Equal(t, sorted[k], key)
+		}
+		it.Seek(seek)
+		for k := j - 1; k >= 0 && k > j-5; k-- {
+			key, ok := it.Prev()
+			assert.True(t, ok)
+			assert.Equal(t, sorted[k], key)
+		}
+	}
+}
+
+func TestPrefixIterator(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"app", "app/v1", "app/v10", "app/v2", "apple", "b", "app/"})
+
+	// the greatest version first
+	it := trie.PrefixIterator("app/v")
+	it.SeekToLast()
+	var got []string
+	for key, ok := it.Prev(); ok; key, ok = it.Prev() {
+		got = append(got, key)
+	}
+	assert.Equal(t, []string{"app/v2", "app/v10", "app/v1"}, got)
+
+	got = got[:0]
+	for key, ok := it.Next(); ok; key, ok = it.Next() {
+		got = append(got, key)
+	}
+	assert.Equal(t, []string{"app/v1", "app/v10", "app/v2"}, got)
+
+	it = trie.PrefixIterator("app")
+	it.Seek("app/v10")
+	key, _ := it.Next()
+	assert.Equal(t, "app/v10", key)
+	it.Seek("a")
+	key, _ = it.Next()
+	assert.Equal(t, "app", key)
+	it.Seek("apq")
+	_, ok := it.Next()
+	assert.False(t, ok)
+	key, _ = it.Prev()
+	assert.Equal(t, "apple", key)
+
+	it = trie.PrefixIterator("c")
+	it.Seek("c")
+	_, ok = it.Next()
+	assert.False(t, ok)
+	it.SeekToLast()
+	_, ok = it.Prev()
+	assert.False(t, ok)
+
+	it = trie.Iterator()
+	it.SeekToLast()
+	key, _ = it.Prev()
+	assert.Equal(t, "b", key)
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package sutrie
+
+import (
+	"fmt"
+	"iter"
+)
+
+// BuildFromIterators builds the trie of the keys of its, each one yielding keys in ascending byte order.
+// The iterators are merged lazily and their duplicates dropped, every key going straight into the levels
+// of the trie as BuildExternal does, so that sorted feeds are combined without being collected and sorted again.
+// It fails if an iterator is not sorted. The options choosing how the trie is encoded are supported, like
+// WithEliasFanoLeaves, WithAlphabetRemap, WithLabelRuns or WithDispatchTable, but not those changing or
+// validating the keys, nor those taking data by key.
+func BuildFromIterators(its []iter.Seq[string], opts ...Option) (*SuccinctTrie, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		if opt == nil {
+			return nil, fmt.Errorf("%w: nil option", ErrInvalidOption)
+		}
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	if o.reversed || o.fold != foldNone || o.normalize != nil || len(o.validators) > 0 || o.truncated ||
+		o.multiset || o.onDuplicate != nil || o.values != nil || o.score != nil {
+		return nil, fmt.Errorf("%w: BuildFromIterators only supports the options encoding the trie", ErrInvalidOption)
+	}
+	if o.packLabels && o.labelRuns {
+		return nil, fmt.Errorf("%w: label runs cannot be packed", ErrInvalidOption)
+	}
+
+	sources := make([]func() (string, bool), len(its))
+	for i, it := range its {
+		next, stop := iter.Pull(it)
+		defer stop()
+		sources[i] = next
+	}
+
+	var b extBuilder
+	b.level(0).bitmap.appendBit(true)
+	err := mergeKeys(sources, func(key string) error {
+		if err := b.add(key); err != nil {
+			return err
+		}
+		if b.nodes > int64(maxNodes) {
+			return ErrTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := b.trie()
+	t.encode(&o)
+	return t, nil
+}
@@ -0,0 +1,91 @@
+//go:build go1.23
+
+package sutrie
+
+import (
+	"iter"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFromIterators(t *testing.T) {
+	dict := domainKeys(3000)
+	want := BuildSuccinctTrie(append([]string{""}, dict...))
+
+	var feeds [][]string
+	for _, part := range [][]string{dict[:1500], dict[1000:], dict[2900:], {}, {""}} {
+		keys := append([]string(nil), part...)
+		sort.Strings(keys)
+		feeds = append(feeds, keys)
+	}
+	seqs := func() []iter.Seq[string] {
+		var seqs []iter.Seq[string]
+		for _, feed := range feeds {
+			seqs = append(seqs, slices.Values(feed))
+		}
+		return seqs
+	}
+	trie, err := BuildFromIterators(seqs())
+	assert.NoError(t, err)
+	assert.True(t, want.Equal(trie))
+	assert.Equal(t, want.Keys(), trie.Keys())
+	assert.True(t, trie.Contains(""))
+	assert.True(t, trie.Contains(dict[0]))
+
+	// an unsorted iterator stops the merge, and the others are stopped
+	stopped := false
+	_, err = BuildFromIterators([]iter.Seq[string]{slices.Values([]string{"a", "c"}), func(yield func(string) bool) {
+		defer func() { stopped = true }()
+		for _, key := range []string{"b", "a", "d"} {
+			if !yield(key) {
+				return
+			}
+		}
+	}})
+	assert.Error(t, err)
+	assert.True(t, stopped)
+
+	empty, err := BuildFromIterators(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, empty.Size())
+	assert.True(t, BuildSuccinctTrie(nil).Equal(empty))
+	assert.NoError(t, empty.Validate())
+
+	// the options encoding the trie are applied, not those changing the keys
+	for _, opts := range [][]Option{
+		{WithEliasFanoLeaves()},
+		{WithAlphabetRemap(), WithPackedLabels()},
+		{WithLabelRuns(), WithDispatchTable(2)},
+	} {
+		encoded, err := BuildFromIterators(seqs(), opts...)
+		assert.NoError(t, err)
+		assert.NoError(t, encoded.Validate())
+		assert.Equal(t, BuildSuccinctTrie(append([]string{""}, dict...), opts...).encoding(), encoded.encoding())
+		assert.Equal(t, want.Keys(), encoded.Keys())
+	}
+	for _, opt := range []Option{WithReversedKeys(), WithCaseFolding(), WithSuffixTruncation(8), WithMultiplicities(), nil} {
+		_, err := BuildFromIterators(seqs(), opt)
+		assert.ErrorIs(t, err, ErrInvalidOption)
+	}
+
+	defer func(n int) { maxNodes = n }(maxNodes)
+	maxNodes = 100
+	_, err = BuildFromIterators(seqs())
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestKeysSeqRange(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"a", "ab", "b", "bc", "c"})
+	var keys []string
+	for key := range trie.KeysSeq() {
+		if key == "bc" {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"a", "ab", "b"}, keys)
+	assert.Equal(t, []string{"ab", "bc"}, slices.Collect(trie.Root().GlobSeq("?*?")))
+}
@@ -0,0 +1,111 @@
+package sutrie
+
+import "encoding/binary"
+
+// WithPackedLabels keeps the labels bit-packed in memory as codes of their alphabet, see WithAlphabetRemap,
+// which it implies: 6 bits per node for domain names instead of 8. The label of a node is still read in
+// constant time, a shift and a mask, but the children of sparse nodes are found by binary search over
+// the codes instead of the vectorized strings.IndexByte. Labels that are all different bytes cannot be packed.
+func WithPackedLabels() Option {
+	return func(o *buildOptions) error {
+		o.remap, o.packLabels = true, true
+		return nil
+	}
+}
+
+// packNodes replaces the labels by their codes, width bits each from the least significant bit of packed[0],
+// so that the code of node k is at bit k*width. The code of the dummy label of the root is 0.
+func (t *SuccinctTrie) packNodes() {
+	if t.alphabet == nil || t.packed != nil {
+		return
+	}
+
+	width := codeWidth(t.alphabet)
+	packed := make([]uint64, (len(t.nodes)*width+63)>>6)
+	for k := 1; k < len(t.nodes); k++ {
+		c, bit := uint64(t.codes[t.nodes[k]]-1), k*width
+		packed[bit>>6] |= c << (bit & 63)
+		if bit&63+width > 64 {
+			packed[bit>>6+1] |= c >> (64 - bit&63)
+		}
+	}
+	t.packed, t.width, t.count, t.nodes = packed, width, len(t.nodes), ""
+}
+
+// numNodes returns the number of nodes, the root included.
+func (t *SuccinctTrie) numNodes() int {
+	if t.packed != nil || t.runs.Len() > 0 {
+		return t.count
+	}
+	return len(t.nodes)
+}
+
+// code returns the code of the label of node k, whose labels must be packed.
+func (t *SuccinctTrie) code(k int32) int {
+	bit := int(k) * t.width
+	word := t.packed[bit>>6] >> (bit & 63)
+	if bit&63+t.width > 64 {
+		word |= t.packed[bit>>6+1] << (64 - bit&63)
+	}
+	return int(word & (1<<t.width - 1))
+}
+
+// label returns the label of node k.
+func (t *SuccinctTrie) label(k int32) byte {
+	if t.packed == nil {
+		if t.runs.Len() > 0 {
+			return t.runLabel(k)
+		}
+		return t.nodes[k]
+	}
+	if k == 0 {
+		return 0
+	}
+	return t.alphabet[t.code(k)]
+}
+
+// labels returns the labels of the nodes in [l, r), copied out if they are packed or a run.
+func (t *SuccinctTrie) labels(l, r int32) string {
+	if t.packed == nil && t.runs.Len() == 0 {
+		return t.nodes[l:r]
+	}
+	b := make([]byte, r-l)
+	for k := l; k < r; k++ {
+		b[k-l] = t.label(k)
+	}
+	return string(b)
+}
+
+// searchPacked returns the index of the node labeled b among the sorted labels of the nodes in [l, r), or -1.
+func (t *SuccinctTrie) searchPacked(l, r int32, b byte) int32 {
+	c := int(t.codes[b]) - 1
+	if c < 0 {
+		return -1
+	}
+	for l < r {
+		m := int32(uint32(l+r) >> 1)
+		switch code := t.code(m); {
+		case code < c:
+			l = m + 1
+		case code > c:
+			r = m
+		default:
+			return m
+		}
+	}
+	return -1
+}
+
+// serializedLabels returns the labels packed by packLabels, which is the layout of packed labels in memory.
+func (t *SuccinctTrie) serializedLabels() []byte {
+	if t.packed == nil {
+		return packLabels(t.nodes, t.alphabet)
+	}
+
+	b := binary.AppendUvarint(nil, uint64(t.count))
+	n := len(b) + (t.count*t.width+7)/8
+	for _, word := range t.packed {
+		b = binary.LittleEndian.AppendUint64(b, word)
+	}
+	return b[:n]
+}
@@ -0,0 +1,104 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func domainKeys(n int) []string {
+	rnd := rand.New(rand.NewSource(1))
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789-."
+	var dict []string
+	for i := 0; i < n; i++ {
+		key := make([]byte, 3+rnd.Intn(12))
+		for j := range key {
+			key[j] = chars[rnd.Intn(len(chars))]
+		}
+		dict = append(dict, string(key)+".com")
+	}
+	return dict
+}
+
+func TestPackedLabels(t *testing.T) {
+	dict := domainKeys(5000)
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithPackedLabels())
+
+	assert.Empty(t, trie.nodes)
+	assert.Equal(t, 6, trie.width)
+	assert.Equal(t, plain.numNodes(), trie.numNodes())
+	assert.Equal(t, plain.nodes, trie.labels(0, int32(trie.numNodes())))
+	assert.True(t, plain.Equal(trie))
+	assert.True(t, trie.Equal(plain))
+	assert.Equal(t, plain.Hash(), trie.Hash())
+	assert.Less(t, trie.MemStats().Labels, plain.MemStats().Labels*4/5)
+
+	check := func(trie *SuccinctTrie) {
+		assert.Equal(t, plain.Keys(), trie.Keys())
+		assert.Equal(t, plain.Keys(Reverse()), trie.Keys(Reverse()))
+		for _, key := range dict[:500] {
+			n := trie.Root().Search(key)
+			assert.True(t, n.Leaf(), key)
+			assert.Equal(t, key, n.Key())
+			assert.Equal(t, plain.Root().Search(key[:2]).Children(), trie.Root().Search(key[:2]).Children())
+			assert.Equal(t, len(key), trie.Root().SearchPrefix(key+"/x"))
+			assert.False(t, trie.Root().Search(key[:2]+"_").Exists())
+
+			for _, probe := range []string{key[:3], key + "-", key[:len(key)-1] + "z"} {
+				c1, ok1 := plain.Ceiling(probe)
+				c2, ok2 := trie.Ceiling(probe)
+				assert.Equal(t, ok1, ok2)
+				assert.Equal(t, c1, c2)
+				f1, ok1 := plain.Floor(probe)
+				f2, ok2 := trie.Floor(probe)
+				assert.Equal(t, ok1, ok2)
+				assert.Equal(t, f1, f2)
+			}
+		}
+	}
+	check(trie)
+	check(trie.Clone())
+
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		assert.Equal(t, trie.packed, loaded.packed)
+		check(&loaded)
+	}
+
+	// the packed layout is the serialized one
+	remapped := BuildSuccinctTrie(append([]string(nil), dict...), WithAlphabetRemap())
+	assert.Nil(t, remapped.packed)
+	assert.Equal(t, remapped.serializedLabels(), trie.serializedLabels())
+
+	// other options see the labels through the same accessors
+	folded := BuildSuccinctTrie([]string{"Hat", "IS", "it"}, WithCaseFolding(), WithPackedLabels(), WithDispatchTable(2))
+	assert.NotNil(t, folded.packed)
+	assert.True(t, folded.Root().Search("HAT").Leaf())
+	assert.True(t, folded.Root().Search("iT").Leaf())
+	assert.Equal(t, []string{"hat", "is", "it"}, folded.Keys())
+
+	assert.Nil(t, BuildSuccinctTrie(nil, WithPackedLabels()).packed)
+}
+
+func BenchmarkPackedLabels(b *testing.B) {
+	dict := domainKeys(200000)
+	for _, opts := range [][]Option{nil, {WithPackedLabels()}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		b.Run(fmt.Sprint("labels-", trie.MemStats().Labels), func(b *testing.B) {
+			root := trie.Root()
+			for i := 0; i < b.N; i++ {
+				root.Search(dict[i%len(dict)])
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package sutrie
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// Child lookup uses one of three layouts. Like in SuRF, the top levels of the trie, which are branchy
+// and visited by every lookup, are encoded LOUDS-dense: every node has a 256-bit bitmap of its labels,
+// so the child is found with popcounts. As many levels are dense as fit in 1/denseRatio of the sparse encoding.
+// Below them, nodes with at least denseFanout children are dense as well, and the labels of the others are
+// searched with strings.IndexByte, which is vectorized. It beats a word-by-word (SWAR) scan and binary search
+// at any fanout, and comes close to dense bitmaps, see BenchmarkIndexByte.
+//
+// Regardless of the layout, a node whose labels form a contiguous run of bytes (like '0'-'9')
+// is recognized by its first and last label and the child is computed arithmetically.
+// The labels of a run are still stored so that the label of any node is a single load,
+// unless the trie is built WithLabelRuns, which keeps the first one of every run, see searchRuns.
+//
+// Built WithDispatchTable, the nodes of the first levels bypass all of them, see initDispatch.
+const (
+	denseFanout = 64
+	denseRatio  = 16
+)
+
+// initLayout builds the label bitmaps of dense nodes.
+// The dense bitset marks the first child of every dense node, its rank is the number of the bitmap.
+// Nodes of the top levels are the ones whose first child is before denseLimit.
+func (t *SuccinctTrie) initLayout() {
+	// starts[d] is the index of the first node of depth d, branching[d] the number of nodes having children
+	starts := []int32{0, 1}
+	var branching []int
+	var i int32
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		if i == starts[len(starts)-1] {
+			starts = append(starts, firstChild)
+		}
+		if depth := len(starts) - 2; firstChild < afterLastChild {
+			for len(branching) <= depth {
+				branching = append(branching, 0)
+			}
+			branching[depth]++
+		}
+		i++
+	})
+
+	// the bits of the sparse encoding are about 8 for the label and 2 for the bitmap per node
+	levels, size := 0, 0
+	bitmap := 64 * t.denseWords()
+	for levels < len(branching) && size+bitmap*branching[levels] <= 10*t.numNodes()/denseRatio {
+		size += bitmap * branching[levels]
+		levels++
+	}
+	t.denseLimit = starts[levels+1]
+	if levels == 0 {
+		t.denseLimit = 0
+	}
+
+	t.dense.Reset(0)
+	t.denseBits = t.denseBits[:0]
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		if firstChild >= afterLastChild || firstChild >= t.denseLimit && afterLastChild-firstChild < denseFanout {
+			return
+		}
+
+		t.dense.Set(int(firstChild), true)
+		var labels [4]uint64
+		for k := firstChild; k < afterLastChild; k++ {
+			c, _ := t.denseCode(t.label(k))
+			labels[c>>6] |= 1 << (c & 63)
+		}
+		t.denseBits = append(t.denseBits, labels[:t.denseWords()]...)
+	})
+
+	t.dense.Init()
+	t.initDispatch()
+}
+
+// forEachNode calls fn with the child range of every node in level order.
+func (t *SuccinctTrie) forEachNode(fn func(firstChild, afterLastChild int32)) {
+	// position 0 is the zero bit of the root
+	var zeros int32 = 1
+	start := int32(-1)
+	for i, n := 0, (t.bitmap.Len()+63)>>6; i < n; i++ {
+		word := t.bitmap.Word(i)
+		for j := 0; j < 64; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			if word&(uint64(1)<<j) == 0 {
+				zeros++
+				continue
+			}
+			if start >= 0 {
+				fn(start, zeros)
+			}
+			start = zeros
+		}
+	}
+}
+
+func (t *SuccinctTrie) indexByte(l, r int32, b byte) int32 {
+	if l >= r {
+		return -1
+	}
+	if t.fold != foldNone {
+		b = lowerASCII(b)
+	}
+
+	n := r - l
+	if t.runs.Len() > 0 {
+		i, j := t.runs.Rank0(int(l)), t.runs.Rank0(int(r))
+		if j-i == 1 {
+			if d := int32(b) - int32(t.nodes[i]); d >= 0 && d < n {
+				return l + d
+			}
+			return -1
+		}
+		if l < t.denseLimit || n >= denseFanout {
+			return t.indexByteDense(l, b)
+		}
+		return t.searchRuns(l, r, i, j, b)
+	}
+
+	if first := t.label(l); int32(t.label(r-1)-first) == n-1 {
+		if d := int32(b) - int32(first); d >= 0 && d < n {
+			return l + d
+		}
+		return -1
+	}
+
+	if l < t.denseLimit || n >= denseFanout {
+		return t.indexByteDense(l, b)
+	}
+	if t.packed != nil {
+		return t.searchPacked(l, r, b)
+	}
+	if k := strings.IndexByte(t.nodes[l:r], b); k >= 0 {
+		return l + int32(k)
+	}
+	return -1
+}
+
+// indexByteDense looks b up in the label bitmap of the dense node whose first child is l.
+func (t *SuccinctTrie) indexByteDense(l int32, b byte) int32 {
+	c, ok := t.denseCode(b)
+	if !ok {
+		return -1
+	}
+	words := t.denseWords()
+	d := t.dense.Rank1(int(l)) * words
+	labels := t.denseBits[d : d+words : d+words]
+	word := labels[c>>6]
+	if word&(1<<(c&63)) == 0 {
+		return -1
+	}
+
+	k := l + int32(bits.OnesCount64(word&(1<<(c&63)-1)))
+	for _, word := range labels[:c>>6] {
+		k += int32(bits.OnesCount64(word))
+	}
+	return k
+}
@@ -0,0 +1,187 @@
+package sutrie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeLayouts(t *testing.T) {
+	var dict []string
+	for _, fanout := range []int{1, 7, 8, 9, 16, 17, 40, 63, 64, 100, 256} {
+		prefix := string(rune('A' + len(dict)%26))
+		for i := 0; i < fanout; i++ {
+			dict = append(dict, prefix+string([]byte{byte(i * 255 / max(1, fanout-1))}))
+		}
+		dict = append(dict, string([]byte{byte(fanout)}))
+	}
+
+	exists := make(map[string]bool)
+	for _, key := range dict {
+		exists[key] = true
+	}
+
+	trie := BuildSuccinctTrie(dict)
+	root := trie.Root()
+	for _, key := range dict {
+		assert.True(t, root.Search(key).Leaf(), "%q", key)
+	}
+
+	for _, prefix := range []string{"", "A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K"} {
+		node := root.Search(prefix)
+		for b := 0; b < 256; b++ {
+			key := prefix + string([]byte{byte(b)})
+			assert.Equal(t, exists[key], node.Next(byte(b)).Leaf(), "%q", key)
+		}
+	}
+
+	assert.Greater(t, len(trie.denseBits), 0)
+}
+
+func TestIndexByteSparse(t *testing.T) {
+	trie := &SuccinctTrie{nodes: "\x00\x01\x7f\x80\x81\xfeabcdefghij"}
+
+	for k := int32(0); k < int32(len(trie.nodes)); k++ {
+		assert.Equal(t, k, trie.indexByte(0, int32(len(trie.nodes)), trie.nodes[k]))
+	}
+	assert.Equal(t, int32(-1), trie.indexByte(0, int32(len(trie.nodes)), 'z'))
+	assert.Equal(t, int32(-1), trie.indexByte(7, int32(len(trie.nodes)), 'a'))
+}
+
+func TestLabelRuns(t *testing.T) {
+	var dict []string
+	for i := 0; i < 1000; i++ {
+		dict = append(dict, fmt.Sprintf("%03d", i))
+	}
+	dict = append(dict, "a", "b", "c", "x")
+
+	root := BuildSuccinctTrie(dict).Root()
+	for _, key := range dict {
+		assert.True(t, root.Search(key).Leaf(), key)
+	}
+	for _, key := range []string{"/", ":", "d", "w", "y", "1000", "0a"} {
+		assert.False(t, root.Search(key).Leaf(), key)
+	}
+
+	node := root.Search("4")
+	assert.Equal(t, "0123456789", node.Children())
+	assert.Equal(t, "45", node.Next('5').Key())
+	assert.False(t, node.Next('/').Exists())
+	assert.False(t, node.Next(':').Exists())
+}
+
+func TestDenseLevels(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	exists := make(map[string]bool)
+	var dict []string
+	for i := 0; i < 20000; i++ {
+		key := make([]byte, 3+rnd.Intn(8))
+		for j := range key {
+			key[j] = byte('0' + rnd.Intn(40))
+		}
+		dict = append(dict, string(key))
+		exists[string(key)] = true
+	}
+
+	trie := BuildSuccinctTrie(dict)
+	assert.Greater(t, trie.denseLimit, int32(1))
+
+	root := trie.Root()
+	// the root and the 40 nodes of depth 1 are dense, the 1600 of depth 2 are not
+	for _, prefix := range []string{"", dict[0][:1], dict[1][:1], dict[2][:2]} {
+		node := root.Search(prefix)
+		assert.Equal(t, len(prefix) < 2, node.firstChild < trie.denseLimit)
+		for b := 0; b < 256; b++ {
+			next := node.Next(byte(b))
+			assert.Equal(t, strings.IndexByte(node.Children(), byte(b)) >= 0, next.Exists())
+			if next.Exists() {
+				assert.Equal(t, prefix+string([]byte{byte(b)}), next.Key())
+			}
+		}
+	}
+	for _, key := range dict {
+		assert.True(t, root.Search(key).Leaf())
+		assert.Equal(t, exists[key[:len(key)-1]], root.Search(key[:len(key)-1]).Leaf())
+	}
+}
+
+// BenchmarkIndexByte compares the ways of finding a label among the children of a node by fanout.
+func BenchmarkIndexByte(b *testing.B) {
+	for _, n := range []int{2, 4, 8, 16, 32, 64, 128, 192, 256} {
+		labels := make([]byte, n)
+		for i := range labels {
+			labels[i] = byte(i * 256 / n)
+		}
+		nodes := "\x00" + string(labels) + "\xff\xff\xff\xff\xff\xff\xff\xff"
+		t := &SuccinctTrie{nodes: nodes}
+		l, r := int32(1), int32(1+n)
+
+		t.dense.Set(int(l), true)
+		t.dense.Init()
+		t.denseBits = make([]uint64, 4)
+		for _, c := range labels {
+			t.denseBits[c>>6] |= 1 << (c & 63)
+		}
+
+		queries := make([]byte, 64)
+		for i := range queries {
+			queries[i] = labels[i*7%n]
+		}
+		b.Run(fmt.Sprintf("SWAR/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				indexByteSWAR(t.nodes, l, r, queries[i&63])
+			}
+		})
+		b.Run(fmt.Sprintf("binary/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				indexByteBinary(t.nodes, l, r, queries[i&63])
+			}
+		})
+		b.Run(fmt.Sprintf("IndexByte/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				strings.IndexByte(t.nodes[l:r], queries[i&63])
+			}
+		})
+		b.Run(fmt.Sprintf("dense/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				t.indexByteDense(l, queries[i&63])
+			}
+		})
+	}
+}
+
+// indexByteSWAR compares eight labels at a time, the ones after r being masked out.
+func indexByteSWAR(nodes string, l, r int32, b byte) int32 {
+	const lsb8, msb8 = 0x0101010101010101, 0x8080808080808080
+	pattern := uint64(b) * lsb8
+	for ; l < r && int(l)+8 <= len(nodes); l += 8 {
+		x := binary.LittleEndian.Uint64([]byte(nodes[l:l+8])) ^ pattern
+		m := (x - lsb8) &^ x & msb8
+		if n := r - l; n < 8 {
+			m &= 1<<(n<<3) - 1
+		}
+		if m != 0 {
+			return l + int32(bits.TrailingZeros64(m)>>3)
+		}
+	}
+	for ; l < r; l++ {
+		if nodes[l] == b {
+			return l
+		}
+	}
+	return -1
+}
+
+func indexByteBinary(nodes string, l, r int32, b byte) int32 {
+	k := l + int32(sort.Search(int(r-l), func(i int) bool { return nodes[l+int32(i)] >= b }))
+	if k < r && nodes[k] == b {
+		return k
+	}
+	return -1
+}
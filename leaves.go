@@ -0,0 +1,50 @@
+package sutrie
+
+import "github.com/nobekanai/sutrie/bitvec"
+
+// WithEliasFanoLeaves stores the leaves bitmap Elias-Fano coded, in about 2+log2(nodes/leaves) bits per leaf
+// instead of 1.25 bits per node. It pays off when leaves are sparse, that is when keys share long prefixes,
+// at the cost of slower leaf checks. Queries are not affected otherwise.
+func WithEliasFanoLeaves() Option {
+	return func(o *buildOptions) error {
+		o.eliasFanoLeaves = true
+		return nil
+	}
+}
+
+// compressLeaves replaces the leaves bitmap by its Elias-Fano coding.
+func (t *SuccinctTrie) compressLeaves() {
+	positions := make([]int, 0, t.leaves.Ones())
+	for p := t.leaves.NextOne(0); p >= 0; p = t.leaves.NextOne(p + 1) {
+		positions = append(positions, p)
+	}
+
+	t.sparseLeaves = bitvec.NewEliasFano(positions, t.numNodes())
+	t.leaves = bitvec.Vector{}
+}
+
+// isLeaf reports whether the node at index is a leaf.
+func (t *SuccinctTrie) isLeaf(index int32) bool {
+	if t.sparseLeaves != nil {
+		return t.sparseLeaves.Get(int(index))
+	}
+	return t.leaves.Get(int(index))
+}
+
+// leavesBefore returns the number of leaves before index in level order.
+func (t *SuccinctTrie) leavesBefore(index int32) int {
+	t.Warmup()
+	if t.sparseLeaves != nil {
+		return t.sparseLeaves.Rank1(int(index))
+	}
+	return t.leaves.Rank1(int(index))
+}
+
+// selectLeaf returns the index of the kth leaf in level order.
+func (t *SuccinctTrie) selectLeaf(k int) int32 {
+	t.Warmup()
+	if t.sparseLeaves != nil {
+		return int32(t.sparseLeaves.Select1(k))
+	}
+	return int32(t.leaves.Select1(k))
+}
@@ -0,0 +1,64 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEliasFanoLeaves(t *testing.T) {
+	var dict []string
+	for i := 0; i < 3000; i++ {
+		dict = append(dict, fmt.Sprintf("https://example.com/some/long/path/%d/index.html", i*7919))
+	}
+
+	plain := BuildSuccinctTrie(dict)
+	trie := BuildSuccinctTrie(dict, WithEliasFanoLeaves())
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	loaded := &SuccinctTrie{}
+	assert.NoError(t, loaded.Unmarshal(&buf))
+
+	var plainBuf bytes.Buffer
+	assert.NoError(t, plain.Marshal(&plainBuf))
+	assert.Less(t, buf.Len(), plainBuf.Len())
+
+	for _, trie := range []*SuccinctTrie{trie, loaded} {
+		assert.Equal(t, plain.Keys(), trie.Keys())
+		for _, key := range dict {
+			i := plain.Root().Search(key).LeafIndex()
+			assert.Equal(t, i, trie.Root().Search(key).LeafIndex())
+			assert.Equal(t, key, trie.KeyAt(i))
+		}
+		assert.Equal(t, len(dict[0]), trie.Root().SearchPrefix(dict[0]+"#top"))
+		assert.False(t, trie.Root().Search(dict[0][:10]).Leaf())
+
+		var a, b bytes.Buffer
+		assert.NoError(t, plain.WriteLeavesRoaring(&a))
+		assert.NoError(t, trie.WriteLeavesRoaring(&b))
+		assert.Equal(t, a.Bytes(), b.Bytes())
+	}
+}
+
+// TestDenseLeaves checks the select samples of a leaves bitmap of all ones, more than one per 128 bits.
+func TestDenseLeaves(t *testing.T) {
+	long := strings.Repeat("abcdefgh", 1024)
+	var dict []string
+	for i := 0; i <= len(long); i++ {
+		dict = append(dict, long[:i])
+	}
+
+	for _, opts := range [][]Option{nil, {WithEliasFanoLeaves()}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		assert.Equal(t, len(dict), trie.Size())
+		for i := 0; i < len(dict); i += 97 {
+			assert.Equal(t, dict[i], trie.KeyAt(i))
+			assert.Equal(t, i, trie.Root().Search(dict[i]).LeafIndex())
+		}
+		assert.Equal(t, long, trie.KeyAt(len(dict)-1))
+	}
+}
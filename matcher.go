@@ -0,0 +1,51 @@
+package sutrie
+
+// Matcher combines a trie of block rules with a trie of exceptions to them, as found in blocklists.
+// Rules are keys matching the keys they are a prefix of, or a suffix of if the trie was built WithReversedKeys,
+// and the longest matching rule wins, the exception if both are as long.
+// A nil trie has no rule.
+type Matcher struct {
+	Block, Allow *SuccinctTrie
+}
+
+// NewMatcher builds the tries of the block rules and their exceptions with opts, see Build.
+func NewMatcher(block, allow []string, opts ...Option) (Matcher, error) {
+	b, err := Build(block, opts...)
+	if err != nil {
+		return Matcher{}, err
+	}
+	a, err := Build(allow, opts...)
+	if err != nil {
+		return Matcher{}, err
+	}
+	return Matcher{Block: b, Allow: a}, nil
+}
+
+// Match reports whether key is blocked, that is whether its longest matching block rule
+// is longer than its longest matching exception.
+func (m Matcher) Match(key string) bool {
+	block := longestRule(m.Block, key)
+	return block > 0 && block > longestRule(m.Allow, key)
+}
+
+// longestRule returns the length of the longest rule of t matching key, 0 if there is none.
+func longestRule(t *SuccinctTrie, key string) int {
+	if t == nil {
+		return 0
+	}
+	if !t.reversed {
+		return t.Root().SearchPrefix(key)
+	}
+
+	longest := 0
+	n := t.Root()
+	for i := len(key) - 1; i >= 0; i-- {
+		if n = n.Next(key[i]); !n.Exists() {
+			break
+		}
+		if n.leaf {
+			longest = len(key) - i
+		}
+	}
+	return longest
+}
@@ -0,0 +1,33 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	m := Matcher{
+		Block: BuildSuccinctTrie([]string{"/ads/", "/tracker", "/public/ads/banner"}),
+		Allow: BuildSuccinctTrie([]string{"/ads/allowed/", "/tracker"}),
+	}
+	assert.True(t, m.Match("/ads/x.js"))
+	assert.False(t, m.Match("/ads/allowed/x.js"))
+	assert.False(t, m.Match("/tracker.js")) // as long as the block rule, the exception wins
+	assert.False(t, m.Match("/index.html"))
+	assert.True(t, m.Match("/public/ads/banner.png"))
+
+	domains, err := NewMatcher([]string{".example.com", ".ads.net"}, []string{".good.ads.net"}, WithReversedKeys())
+	assert.NoError(t, err)
+	assert.True(t, domains.Match("www.example.com"))
+	assert.True(t, domains.Match("x.ads.net"))
+	assert.False(t, domains.Match("x.good.ads.net"))
+	assert.False(t, domains.Match("example.org"))
+
+	assert.False(t, Matcher{}.Match("/ads/"))
+	assert.True(t, Matcher{Block: m.Block}.Match("/ads/"))
+	assert.False(t, Matcher{Allow: m.Allow}.Match("/ads/"))
+
+	_, err = NewMatcher([]string{"a"}, nil)
+	assert.ErrorIs(t, err, ErrNilInput)
+}
@@ -0,0 +1,59 @@
+package sutrie
+
+// MemStats is the memory used by a trie in bytes, by component.
+type MemStats struct {
+	// Bitmap is the LOUDS bitmap of the tree shape, BitmapIndex its rank/select index
+	Bitmap, BitmapIndex int
+	// Leaves is the bitmap of the leaves, or its Elias-Fano coding with its index, LeavesIndex its rank/select index
+	Leaves, LeavesIndex int
+	// Labels is the label string, one byte per node, or its codes, see WithPackedLabels,
+	// and the bitmap of the runs with its index, see WithLabelRuns
+	Labels int
+	// Dense is the label bitmaps of the LOUDS-dense nodes with their index
+	Dense int
+	// Dispatch is the dispatch table of the first levels, see WithDispatchTable
+	Dispatch int
+	// Suffixes is the suffix hashes, see WithSuffixTruncation
+	Suffixes int
+	// Counts is the occurrence counts, see WithMultiplicities
+	Counts int
+	// Values is the values of the keys, see WithUint8Values, and Scores their scores, see WithScores
+	Values, Scores int
+}
+
+// Total returns the total memory used.
+func (s MemStats) Total() int {
+	return s.Bitmap + s.BitmapIndex + s.Leaves + s.LeavesIndex + s.Labels + s.Dense + s.Dispatch + s.Suffixes + s.Counts + s.Values + s.Scores
+}
+
+// MemStats returns the memory used by the trie, not counting the few fixed-size fields of its struct.
+func (t *SuccinctTrie) MemStats() MemStats {
+	t.Warmup()
+	s := MemStats{
+		Bitmap:      8 * words(t.bitmap.Len()),
+		BitmapIndex: t.bitmap.IndexBytes(),
+		Labels:      len(t.nodes) + 8*len(t.packed) + 8*words(t.runs.Len()) + t.runs.IndexBytes(),
+		Dense:       8*(len(t.denseBits)+words(t.dense.Len())) + t.dense.IndexBytes(),
+		Dispatch:    4 * len(t.dispatch),
+		Suffixes:    8 * len(t.suffixes),
+		Counts:      8 * len(t.counts.words),
+		Values:      8 * len(t.values.words),
+		Scores:      8 * len(t.scores.words),
+	}
+	if t.sparseLeaves != nil {
+		s.Leaves = t.sparseLeaves.SizeInBytes()
+	} else {
+		s.Leaves, s.LeavesIndex = 8*words(t.leaves.Len()), t.leaves.IndexBytes()
+	}
+	return s
+}
+
+// SizeInBytes returns the total memory used by the trie, see MemStats.
+func (t *SuccinctTrie) SizeInBytes() int {
+	return t.MemStats().Total()
+}
+
+// words returns the number of words holding n bits.
+func words(n int) int {
+	return (n + 63) >> 6
+}
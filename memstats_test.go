@@ -0,0 +1,32 @@
+package sutrie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemStats(t *testing.T) {
+	var dict []string
+	for i := 0; i < 100000; i++ {
+		dict = append(dict, fmt.Sprintf("key:%08d", i*7))
+	}
+
+	trie := BuildSuccinctTrie(append([]string(nil), dict...))
+	s := trie.MemStats()
+	assert.Equal(t, len(trie.nodes), s.Labels)
+	assert.InDelta(t, 2*len(trie.nodes)/8, s.Bitmap, 8)
+	assert.InDelta(t, len(trie.nodes)/8, s.Leaves, 8)
+	assert.NotZero(t, s.BitmapIndex)
+	assert.NotZero(t, s.LeavesIndex)
+	assert.Zero(t, s.Suffixes)
+	assert.Equal(t, s.Total(), trie.SizeInBytes())
+
+	sparse := BuildSuccinctTrie(append([]string(nil), dict...), WithEliasFanoLeaves())
+	assert.Equal(t, sparse.sparseLeaves.SizeInBytes(), sparse.MemStats().Leaves)
+	assert.Zero(t, sparse.MemStats().LeavesIndex)
+
+	filter := BuildSuccinctTrie(append([]string(nil), dict...), WithSuffixTruncation(8)).MemStats()
+	assert.Equal(t, len(dict), filter.Suffixes) // 8 bits per key
+}
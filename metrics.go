@@ -0,0 +1,100 @@
+package sutrie
+
+import (
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// QueryStats counts the queries of an instrumented trie, see Instrument. Its counters are atomic,
+// so that they can be read while the trie is queried, for example by a Prometheus collector.
+type QueryStats struct {
+	// Lookups is the number of Contains and SearchPrefix queries, those of batches included, Hits the number
+	// of them finding the key or, for SearchPrefix, a prefix of it, and Misses the others
+	Lookups, Hits, Misses atomic.Uint64
+
+	// Depth is the total number of bytes of the keys of lookups descended in the trie
+	Depth atomic.Uint64
+
+	// Batches is the number of SearchPrefixBatch and ContainsBatch queries and BatchKeys the total number of their keys
+	Batches, BatchKeys atomic.Uint64
+}
+
+// AvgDepth returns the average number of bytes descended by a lookup, 0 if there were none.
+func (s *QueryStats) AvgDepth() float64 {
+	return ratio(s.Depth.Load(), s.Lookups.Load())
+}
+
+// AvgBatchSize returns the average number of keys of a batch, 0 if there were none.
+func (s *QueryStats) AvgBatchSize() float64 {
+	return ratio(s.BatchKeys.Load(), s.Batches.Load())
+}
+
+func ratio(a, b uint64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Instrument makes the trie count its Contains, SearchPrefix and batch queries in stats, or stops counting if stats
+// is nil. Instrumented batches are not interleaved. Like SetNormalizer, it must not be called while the trie is queried.
+func (t *SuccinctTrie) Instrument(stats *QueryStats) {
+	t.stats = stats
+}
+
+func (s *QueryStats) record(hit bool, depth int) {
+	s.Lookups.Add(1)
+	if hit {
+		s.Hits.Add(1)
+	} else {
+		s.Misses.Add(1)
+	}
+	s.Depth.Add(uint64(depth))
+}
+
+func (s *QueryStats) recordBatch(keys int) {
+	s.Batches.Add(1)
+	s.BatchKeys.Add(uint64(keys))
+}
+
+// contains reports whether the normalized key is in t, counting the query if t is instrumented.
+func contains[K string | []byte](t *SuccinctTrie, key K) bool {
+	if t.stats == nil {
+		return search(t.Root(), key).Leaf()
+	}
+	n, depth, _ := descendKey(t.Root(), key)
+	t.stats.record(n.Leaf(), depth)
+	return n.Leaf()
+}
+
+// longestPrefix is SearchPrefix of the normalized key, counting the query if t is instrumented.
+func longestPrefix[K string | []byte](t *SuccinctTrie, key K) int {
+	root := t.Root()
+	if t.stats == nil {
+		return searchPrefix(root, key)
+	}
+	_, depth, lastUnmatch := descendKey(root, key)
+	t.stats.record(lastUnmatch > 0 || root.leaf, depth)
+	return lastUnmatch
+}
+
+// descendKey is search and searchPrefix in one, also returning the number of bytes of key descended.
+func descendKey[K string | []byte](n Node, key K) (last Node, depth, lastUnmatch int) {
+	for depth < len(key) {
+		var next Node
+		size := 1
+		if key[depth] >= utf8.RuneSelf && n.trie.fold == foldUnicode {
+			next, size = nextFolded(n, key, depth)
+		} else {
+			next = n.Next(key[depth])
+		}
+		if !next.Exists() {
+			return next, depth, lastUnmatch
+		}
+		n, depth = next, depth+size
+		if n.leaf {
+			lastUnmatch = depth
+		}
+	}
+	return n, depth, lastUnmatch
+}
@@ -0,0 +1,41 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"ab", "abcd", "x"}, WithNormalizer(bytes.TrimSpace))
+	var stats QueryStats
+	trie.Instrument(&stats)
+
+	assert.True(t, trie.Contains(" abcd"))        // hit, depth 4
+	assert.False(t, trie.Contains("abc"))         // miss, depth 3
+	assert.False(t, trie.Contains("y"))           // miss, depth 0
+	assert.Equal(t, 2, trie.SearchPrefix("abcz")) // hit, depth 3
+	assert.Equal(t, 0, trie.SearchPrefix("a"))    // miss, depth 1
+	assert.Equal(t, uint64(5), stats.Lookups.Load())
+	assert.Equal(t, uint64(2), stats.Hits.Load())
+	assert.Equal(t, uint64(3), stats.Misses.Load())
+	assert.Equal(t, 11.0/5, stats.AvgDepth())
+	assert.Equal(t, 0.0, stats.AvgBatchSize())
+
+	keys := []string{"ab", "abcd", "xy", "z"}
+	contained := make([]bool, len(keys))
+	trie.ContainsBatch(keys, contained, Interleave(4))
+	assert.Equal(t, []bool{true, true, false, false}, contained)
+	prefixes := make([]int, len(keys))
+	trie.SearchPrefixBatch(keys[:2], prefixes, Parallel(2))
+	assert.Equal(t, []int{2, 4, 0, 0}, prefixes)
+	assert.Equal(t, uint64(11), stats.Lookups.Load())
+	assert.Equal(t, uint64(6), stats.Hits.Load())
+	assert.Equal(t, uint64(2), stats.Batches.Load())
+	assert.Equal(t, 3.0, stats.AvgBatchSize())
+
+	trie.Instrument(nil)
+	assert.True(t, trie.Contains("x"))
+	assert.Equal(t, uint64(11), stats.Lookups.Load())
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package sutrie
+
+const raceEnabled = false
@@ -0,0 +1,65 @@
+package sutrie
+
+import (
+	"slices"
+	"sync"
+)
+
+// WithNormalizer applies fn to every key at build time and to the key of every query of the trie and its Set,
+// for example to strip the trailing dot of domains or to percent-decode URLs. fn may modify its argument in place
+// and return it, so that queries do not allocate. The lengths returned by SearchPrefix are in the normalized key.
+// Node methods, traversing the keys as stored, do not normalize. The normalizer is not marshaled,
+// see SetNormalizer.
+func WithNormalizer(fn func(key []byte) []byte) Option {
+	return func(o *buildOptions) error {
+		o.normalize = fn
+		return nil
+	}
+}
+
+// SetNormalizer sets the normalizer applied to queries, see WithNormalizer. It is meant to restore it after Unmarshal,
+// and must not be called while the trie is queried.
+func (t *SuccinctTrie) SetNormalizer(fn func(key []byte) []byte) {
+	t.normalize = fn
+}
+
+func normalizeKeys(dict []string, fn func(key []byte) []byte) []string {
+	ret := make([]string, len(dict))
+	for i, key := range dict {
+		ret[i] = string(fn([]byte(key)))
+	}
+	return ret
+}
+
+var normBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// query returns key as Build stores it, normalized and reversed, in buf, a buffer of normBuffers to put back
+// when done with the key. Letters are folded by the search, except the runes of a reversed key, folded first.
+// It is for the tries whose rewritesQueries is true.
+func query[K string | []byte](t *SuccinctTrie, key K) (q []byte, buf *[]byte) {
+	buf = normBuffers.Get().(*[]byte)
+	*buf = append((*buf)[:0], key...)
+	q = *buf
+	if t.normalize != nil {
+		q = t.normalize(q)
+	}
+	if t.reversed {
+		if t.fold == foldUnicode {
+			q = append(q[:0], foldKey(string(q), t.fold)...)
+		}
+		slices.Reverse(q)
+	}
+	return q, buf
+}
+
+// rewritesQueries reports whether the keys of queries must go through query before the search.
+func (t *SuccinctTrie) rewritesQueries() bool {
+	return t.normalize != nil || t.reversed
+}
+
+// normalized returns key normalized by t in buf, a buffer of normBuffers to put back when done with the key.
+func normalized[K string | []byte](t *SuccinctTrie, key K) (norm []byte, buf *[]byte) {
+	buf = normBuffers.Get().(*[]byte)
+	*buf = append((*buf)[:0], key...)
+	return t.normalize(*buf), buf
+}
@@ -0,0 +1,59 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stripDot removes the trailing dot of a fully qualified domain in place.
+func stripDot(key []byte) []byte {
+	return bytes.TrimSuffix(key, []byte("."))
+}
+
+func TestNormalizer(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"example.com.", "golang.org"}, WithNormalizer(stripDot))
+	assert.Equal(t, []string{"example.com", "golang.org"}, trie.Keys())
+	assert.True(t, trie.Contains("example.com."))
+	assert.True(t, trie.Contains("example.com"))
+	assert.True(t, trie.MayContain("golang.org."))
+	assert.Equal(t, 10, trie.SearchPrefix("golang.org."))
+
+	set := trie.Set()
+	assert.True(t, set.Contains("golang.org."))
+	assert.True(t, set.ContainsBytes([]byte("golang.org.")))
+	assert.True(t, set.ContainsPrefixOf("golang.org."))
+	assert.True(t, set.ContainsPrefixOfBytes([]byte("golang.org.")))
+	assert.True(t, set.HasKeysWithPrefix("golang."))
+
+	domains := BuildSuccinctTrie([]string{"example.com."}, WithNormalizer(stripDot), WithReversedKeys())
+	assert.True(t, domains.MatchDomainSuffix("www.example.com."))
+	assert.True(t, domains.MatchDomainSet("example.com."))
+	assert.False(t, domains.MatchDomainSet("badexample.com."))
+
+	if !raceEnabled {
+		assert.Zero(t, testing.AllocsPerRun(100, func() { trie.Contains("example.com.") }))
+		assert.Zero(t, testing.AllocsPerRun(100, func() {
+			domains.MatchDomainSuffix("www.example.com.")
+			domains.MatchDomainSet("www.example.com.")
+		}))
+	}
+
+	keys := []string{"example.com.", "golang.org.", "go"}
+	found, prefixes := make([]bool, len(keys)), make([]int, len(keys))
+	for _, opts := range [][]BatchOption{nil, {Interleave(2)}} {
+		trie.ContainsBatch(keys, found, opts...)
+		assert.Equal(t, []bool{true, true, false}, found)
+		trie.SearchPrefixBatch(keys, prefixes, opts...)
+		assert.Equal(t, []int{11, 10, 0}, prefixes)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	loaded := &SuccinctTrie{}
+	assert.NoError(t, loaded.Unmarshal(&buf))
+	assert.False(t, loaded.Contains("example.com."))
+	loaded.SetNormalizer(stripDot)
+	assert.True(t, loaded.Contains("example.com."))
+}
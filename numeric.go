@@ -0,0 +1,94 @@
+package sutrie
+
+import (
+	"fmt"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+// NumericTrie is a trie of digit strings, like phone number prefixes, MCC/MNC codes or card BIN ranges.
+// It has the bitmaps of a SuccinctTrie but packs its labels in 4 bits instead of 8.
+type NumericTrie struct {
+	bitmap bitvec.Vector
+	leaves bitvec.Vector
+	size   int
+
+	// labels packs the digit of node i in the nibble i&1 of byte i>>1
+	labels []byte
+}
+
+// BuildNumericTrie constructs a NumericTrie of numbers, which must only contain the digits 0 to 9.
+func BuildNumericTrie(numbers []string) (*NumericTrie, error) {
+	for _, number := range numbers {
+		for i := 0; i < len(number); i++ {
+			if number[i]-'0' > 9 {
+				return nil, fmt.Errorf("sutrie: %q is not a number", number)
+			}
+		}
+	}
+
+	t, err := build(append([]string(nil), numbers...), &buildOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]byte, (len(t.nodes)+1)>>1)
+	for i := 1; i < len(t.nodes); i++ {
+		labels[i>>1] |= (t.nodes[i] - '0') << (4 * (i & 1))
+	}
+	return &NumericTrie{bitmap: t.bitmap, leaves: t.leaves, size: t.size, labels: labels}, nil
+}
+
+// Size returns the number of numbers in the trie.
+func (t *NumericTrie) Size() int {
+	return t.size
+}
+
+// child returns the child of node on digit d, or -1.
+func (t *NumericTrie) child(node int, d byte) int {
+	first := t.bitmap.Select1(node) - node
+	after := t.bitmap.Select1(node+1) - node - 1
+	for k := first; k < after; k++ {
+		switch label := t.labels[k>>1] >> (4 * (k & 1)) & 15; {
+		case label == d:
+			return k
+		case label > d:
+			return -1
+		}
+	}
+	return -1
+}
+
+// LongestNumericPrefix returns the length of the longest number of the trie which is a prefix of number,
+// 0 if there is none. It stops at the first byte of number which is not a digit.
+func (t *NumericTrie) LongestNumericPrefix(number string) int {
+	longest, node := 0, 0
+	for i := 0; i < len(number); i++ {
+		d := number[i] - '0'
+		if d > 9 {
+			break
+		}
+		if node = t.child(node, d); node < 0 {
+			break
+		}
+		if t.leaves.Get(node) {
+			longest = i + 1
+		}
+	}
+	return longest
+}
+
+// Contains reports whether number is in the trie.
+func (t *NumericTrie) Contains(number string) bool {
+	node := 0
+	for i := 0; i < len(number); i++ {
+		d := number[i] - '0'
+		if d > 9 {
+			return false
+		}
+		if node = t.child(node, d); node < 0 {
+			return false
+		}
+	}
+	return t.leaves.Get(node)
+}
@@ -0,0 +1,39 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	numbers := []string{"1", "44", "4420", "86", "861", "0"}
+	for i := 0; i < 5000; i++ {
+		numbers = append(numbers, fmt.Sprint(rnd.Intn(10000000)))
+	}
+
+	trie, err := BuildNumericTrie(numbers)
+	assert.NoError(t, err)
+	ref := BuildSuccinctTrie(append([]string(nil), numbers...))
+	assert.Equal(t, ref.Size(), trie.Size())
+	assert.Less(t, len(trie.labels), len(ref.nodes)/2+1)
+
+	queries := append([]string{"", "x", "4420712345", "44x", "0"}, numbers...)
+	for i := 0; i < 5000; i++ {
+		queries = append(queries, fmt.Sprint(rnd.Intn(100000000)))
+	}
+	for _, q := range queries {
+		assert.Equal(t, ref.Root().SearchPrefix(q), trie.LongestNumericPrefix(q), q)
+		assert.Equal(t, ref.Contains(q), trie.Contains(q), q)
+	}
+	assert.Equal(t, 2, trie.LongestNumericPrefix("44-20"))
+
+	_, err = BuildNumericTrie([]string{"12a"})
+	assert.Error(t, err)
+	empty, err := BuildNumericTrie(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, empty.LongestNumericPrefix("123"))
+}
@@ -0,0 +1,34 @@
+package sutrie
+
+// Option configures how a trie is built. Invalid options make Build fail with an error wrapping ErrInvalidOption.
+type Option func(*buildOptions) error
+
+type buildOptions struct {
+	validators []func(key string) error
+	onInvalid  func(key string, err error)
+	dropBad    bool
+	reversed   bool
+
+	onDuplicate func(key string, count int)
+	multiset    bool
+	values      []uint8
+	scores      int
+	scoreBits   uint
+	score       func(i int) uint64
+
+	progress *buildProgress
+	spare    *buildBuffers
+
+	// sorted is set by the builds whose keys are already sorted
+	sorted bool
+
+	eliasFanoLeaves bool
+	dispatchLevels  int
+	remap           bool
+	packLabels      bool
+	labelRuns       bool
+	truncated       bool
+	hashBits        int
+	fold            foldMode
+	normalize       func(key []byte) []byte
+}
@@ -0,0 +1,152 @@
+package sutrie
+
+import "sort"
+
+// Unless told otherwise by an IterOption, every API enumerating keys yields them in ascending byte-lexicographic
+// order, the order of sort.Strings, where a key comes before all keys it is a prefix of.
+// This is part of the API contract, so an enumeration can be merge-joined with any other sorted source.
+//
+// The options below change the order consistently for all enumeration APIs:
+// Reverse yields keys in descending order and Collation orders the bytes of keys by a custom rank.
+
+// IterOption changes the order in which an enumeration API yields keys.
+type IterOption func(*iterOrder)
+
+type iterOrder struct {
+	reverse bool
+	rank    func(b byte) int
+}
+
+// Reverse yields keys in descending order, where a key comes after all keys it is a prefix of.
+// APIs visiting inner nodes (like Walk) still visit a node before its descendants.
+func Reverse() IterOption {
+	return func(o *iterOrder) {
+		o.reverse = true
+	}
+}
+
+// Collation orders keys byte by byte by rank(b) instead of by b, ties broken by byte value.
+func Collation(rank func(b byte) int) IterOption {
+	return func(o *iterOrder) {
+		o.rank = rank
+	}
+}
+
+// FoldCase is a rank for Collation ordering ASCII letters case-insensitively,
+// the upper case letter coming first: "A" < "a" < "B" < "b".
+func FoldCase(b byte) int {
+	if 'A' <= b && b <= 'Z' {
+		return int(b-'A'+'a')<<1 - 1
+	}
+	return int(b) << 1
+}
+
+func newIterOrder(opts []IterOption) iterOrder {
+	var o iterOrder
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// lessByte reports whether child label a is visited before b.
+func (o *iterOrder) lessByte(a, b byte) bool {
+	less := a < b
+	if o.rank != nil {
+		if ra, rb := o.rank(a), o.rank(b); ra != rb {
+			less = ra < rb
+		}
+	}
+	return less != o.reverse && a != b
+}
+
+// less reports whether key a is yielded before b.
+func (o *iterOrder) less(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return o.lessByte(a[i], b[i])
+		}
+	}
+	return (len(a) < len(b)) != o.reverse && len(a) != len(b)
+}
+
+// children calls fn with the index of every child of n in order, until fn returns false.
+func (o *iterOrder) children(n Node, fn func(k int32) bool) bool {
+	if o.rank == nil {
+		if o.reverse {
+			for k := n.afterLastChild - 1; k >= n.firstChild; k-- {
+				if !fn(k) {
+					return false
+				}
+			}
+			return true
+		}
+		for k := n.firstChild; k < n.afterLastChild; k++ {
+			if !fn(k) {
+				return false
+			}
+		}
+		return true
+	}
+
+	ks := make([]int32, 0, n.afterLastChild-n.firstChild)
+	for k := n.firstChild; k < n.afterLastChild; k++ {
+		ks = append(ks, k)
+	}
+	sort.Slice(ks, func(i, j int) bool {
+		return o.lessByte(n.trie.label(ks[i]), n.trie.label(ks[j]))
+	})
+	for _, k := range ks {
+		if !fn(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keys returns all keys under the current node, relative to it.
+func (n Node) Keys(opts ...IterOption) (keys []string) {
+	n.KeysSeq(opts...)(func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
+
+// KeysSeq is like Keys but yields the keys one at a time and stops once yield returns false, so that
+// the first keys of a large subtree are found without enumerating all of them. With Go 1.23 and later,
+// it is an iter.Seq[string] and can be ranged over.
+func (n Node) KeysSeq(opts ...IterOption) func(yield func(key string) bool) {
+	return func(yield func(key string) bool) {
+		if !n.Exists() {
+			return
+		}
+
+		o := newIterOrder(opts)
+		var key []byte
+		var visit func(n Node) bool
+		visit = func(n Node) bool {
+			if n.leaf && !o.reverse && !yield(string(key)) {
+				return false
+			}
+			ok := o.children(n, func(k int32) bool {
+				key = append(key, n.trie.label(k))
+				ok := visit(n.next(k))
+				key = key[:len(key)-1]
+				return ok
+			})
+			return ok && (!n.leaf || !o.reverse || yield(string(key)))
+		}
+		visit(n)
+	}
+}
+
+// Keys returns all keys of the trie.
+func (t *SuccinctTrie) Keys(opts ...IterOption) []string {
+	return t.Root().Keys(opts...)
+}
+
+// KeysSeq yields all keys of the trie, see Node.KeysSeq.
+func (t *SuccinctTrie) KeysSeq(opts ...IterOption) func(yield func(key string) bool) {
+	return t.Root().KeysSeq(opts...)
+}
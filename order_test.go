@@ -0,0 +1,92 @@
+package sutrie
+
+import (
+	mrand "math/rand"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterationOrder(t *testing.T) {
+	const l = 1000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(1 + mrand.Intn(4))
+	}
+	dict = append(dict, "a", "ab", "abc", "b")
+	trie := BuildSuccinctTrie(dict)
+
+	sorted := make([]string, 0, len(dict))
+	for i, key := range dict {
+		if i == 0 || key != dict[i-1] {
+			sorted = append(sorted, key)
+		}
+	}
+	assert.True(t, sort.StringsAreSorted(sorted))
+	assert.Equal(t, sorted, trie.Keys())
+	assert.Equal(t, sorted, trie.Root().Glob("*"))
+
+	var walked []string
+	_ = trie.Walk(func(key string, node Node) error {
+		if node.Leaf() {
+			walked = append(walked, key)
+		}
+		return nil
+	})
+	assert.Equal(t, sorted, walked)
+
+	reversed := make([]string, len(sorted))
+	for i, key := range sorted {
+		reversed[len(sorted)-1-i] = key
+	}
+	assert.Equal(t, reversed, trie.Keys(Reverse()))
+	assert.Equal(t, reversed, trie.Root().Glob("*", Reverse()))
+}
+
+func TestCollation(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"B", "a", "b", "C", "ab", "Ab"})
+
+	assert.Equal(t, []string{"Ab", "B", "C", "a", "ab", "b"}, trie.Keys())
+	assert.Equal(t, []string{"Ab", "a", "ab", "B", "b", "C"}, trie.Keys(Collation(FoldCase)))
+	assert.Equal(t, []string{"C", "b", "B", "ab", "a", "Ab"}, trie.Keys(Collation(FoldCase), Reverse()))
+	assert.Equal(t, []string{"Ab", "ab"}, trie.Root().Glob("?b*", Collation(FoldCase)))
+	assert.Equal(t, []string{"a", "B", "b", "C"}, trie.Root().Glob("?", Collation(FoldCase)))
+
+	x := NewInvertedIndex(trie, make([]int, trie.Size()))
+	assert.Equal(t, trie.Keys(), x.KeysWithValue(0))
+	assert.Equal(t, trie.Keys(Collation(FoldCase), Reverse()), x.KeysWithValue(0, Collation(FoldCase), Reverse()))
+}
+
+func TestKeysSeq(t *testing.T) {
+	dict := append(domainKeys(3000), "a", "ab", "abc", "go", "good", "gone", "home")
+	root := BuildSuccinctTrie(append([]string(nil), dict...)).Root()
+
+	collect := func(seq func(yield func(string) bool), limit int) (keys []string) {
+		seq(func(key string) bool {
+			keys = append(keys, key)
+			return len(keys) < limit
+		})
+		return
+	}
+	re := regexp.MustCompile(`[a-g].*\.com`)
+	for _, opts := range [][]IterOption{nil, {Reverse()}, {Collation(FoldCase)}} {
+		for name, c := range map[string]struct {
+			seq  func(yield func(string) bool)
+			keys []string
+		}{
+			"keys":   {root.KeysSeq(opts...), root.Keys(opts...)},
+			"glob":   {root.GlobSeq("*.com", opts...), root.Glob("*.com", opts...)},
+			"regexp": {root.MatchRegexpSeq(re, opts...), root.MatchRegexp(re, opts...)},
+			"t9":     {root.T9Seq("4663", opts...), root.T9("4663", opts...)},
+		} {
+			assert.Equal(t, c.keys, collect(c.seq, len(c.keys)+1), name)
+			for _, limit := range []int{1, 2, len(c.keys) / 2} {
+				assert.Equal(t, c.keys[:limit], collect(c.seq, limit), name)
+			}
+		}
+	}
+	assert.Equal(t, BuildSuccinctTrie(dict).Keys(), collect(BuildSuccinctTrie(dict).KeysSeq(), len(dict)))
+	assert.Empty(t, collect(root.Search("zzzz").KeysSeq(), 1))
+}
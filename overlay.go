@@ -0,0 +1,168 @@
+package sutrie
+
+import "sync"
+
+// Overlay is a mutable set of keys on top of an immutable trie: keys added or removed since the trie was built
+// are kept in small sets, which are checked first at query time, until Compact merges them into a fresh trie.
+// It is safe for concurrent use.
+//
+// Keys are reversed like the keys of the trie if it was built WithReversedKeys, except for SearchPrefix,
+// which sees keys as stored like Node.SearchPrefix.
+type Overlay struct {
+	mu   sync.RWMutex
+	base *SuccinctTrie
+
+	// added are keys not in base, removed keys of base, both as stored
+	added, removed map[string]struct{}
+}
+
+var _ Searcher = (*Overlay)(nil)
+
+// NewOverlay returns an overlay on base, which must not be built WithSuffixTruncation.
+func NewOverlay(base *SuccinctTrie) *Overlay {
+	if base.truncated {
+		panic("sutrie: cannot overlay a truncated trie")
+	}
+	return &Overlay{base: base, added: make(map[string]struct{}), removed: make(map[string]struct{})}
+}
+
+// stored returns key as stored in the base trie, that is folded and reversed.
+func (o *Overlay) stored(key string) string {
+	if o.base.fold != foldNone {
+		key = foldKey(key, o.base.fold)
+	}
+	if o.base.reversed {
+		return reverse(key)
+	}
+	return key
+}
+
+// Add adds key to the set.
+func (o *Overlay) Add(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key = o.stored(key)
+	if o.base.Root().Search(key).Leaf() {
+		delete(o.removed, key)
+	} else {
+		o.added[key] = struct{}{}
+	}
+}
+
+// Remove removes key from the set.
+func (o *Overlay) Remove(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key = o.stored(key)
+	if o.base.Root().Search(key).Leaf() {
+		o.removed[key] = struct{}{}
+	} else {
+		delete(o.added, key)
+	}
+}
+
+// Contains reports whether key is in the set.
+func (o *Overlay) Contains(key string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.contains(o.stored(key))
+}
+
+func (o *Overlay) contains(key string) bool {
+	if _, ok := o.added[key]; ok {
+		return true
+	}
+	if _, ok := o.removed[key]; ok {
+		return false
+	}
+	return o.base.Root().Search(key).Leaf()
+}
+
+// SearchPrefix returns the length of the longest key of the set which is a prefix of key, 0 if there is none.
+func (o *Overlay) SearchPrefix(key string) (lastUnmatch int) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if len(o.added) == 0 && len(o.removed) == 0 {
+		return o.base.Root().SearchPrefix(key)
+	}
+	for i := len(key); i > 0; i-- {
+		if o.contains(key[:i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// Size returns the number of keys in the set.
+func (o *Overlay) Size() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.base.Size() + len(o.added) - len(o.removed)
+}
+
+// Pending returns the number of keys added or removed since the last compaction.
+func (o *Overlay) Pending() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.added) + len(o.removed)
+}
+
+// Base returns the trie the overlay was last compacted into.
+func (o *Overlay) Base() *SuccinctTrie {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.base
+}
+
+// Compact merges the added and removed keys into a fresh trie, which becomes the base of the overlay and is returned.
+// The trie is built like by Union and Difference, with no option but the case folding and WithReversedKeys of
+// the base, its keys being stored folded. If they return an error, Compact returns it and leaves the overlay unchanged.
+// Queries and updates wait for it to complete.
+func (o *Overlay) Compact() (*SuccinctTrie, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.added) == 0 && len(o.removed) == 0 {
+		return o.base, nil
+	}
+	base := o.base
+	if len(o.removed) > 0 {
+		removed, err := o.trieOf(o.removed)
+		if err == nil {
+			base, err = Difference(base, removed)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(o.added) > 0 {
+		added, err := o.trieOf(o.added)
+		if err == nil {
+			base, err = Union(base, added)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	base.fold = o.base.fold
+	o.base = base
+	o.added = make(map[string]struct{})
+	o.removed = make(map[string]struct{})
+	return base, nil
+}
+
+// trieOf builds the trie of keys as stored.
+func (o *Overlay) trieOf(keys map[string]struct{}) (*SuccinctTrie, error) {
+	dict := make([]string, 0, len(keys))
+	for key := range keys {
+		dict = append(dict, key)
+	}
+	t, err := Build(dict)
+	if err != nil {
+		return nil, err
+	}
+	t.reversed = o.base.reversed
+	return t, nil
+}
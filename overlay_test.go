@@ -0,0 +1,112 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlay(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	want := make(map[string]bool)
+	var dict []string
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("%x", rnd.Intn(5000))
+		dict = append(dict, key)
+		want[key] = true
+	}
+
+	for _, opts := range [][]Option{nil, {WithReversedKeys()}} {
+		o := NewOverlay(BuildSuccinctTrie(append([]string(nil), dict...), opts...))
+		set := make(map[string]bool)
+		for key := range want {
+			set[key] = true
+		}
+
+		check := func() {
+			assert.Equal(t, len(set), o.Size())
+			for i := 0; i < 5000; i++ {
+				key := fmt.Sprintf("%x", i)
+				assert.Equal(t, set[key], o.Contains(key), key)
+			}
+		}
+
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("%x", rnd.Intn(5000))
+				if rnd.Intn(2) == 0 {
+					o.Add(key)
+					set[key] = true
+				} else {
+					o.Remove(key)
+					delete(set, key)
+				}
+			}
+			check()
+			assert.NotZero(t, o.Pending())
+
+			base, err := o.Compact()
+			assert.NoError(t, err)
+			assert.Zero(t, o.Pending())
+			assert.Same(t, base, o.Base())
+			assert.Equal(t, len(opts) > 0, base.Reversed())
+			check()
+		}
+
+		if len(opts) == 0 {
+			var keys []string
+			for key := range set {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			assert.Equal(t, keys, o.Base().Keys())
+		}
+	}
+
+	o := NewOverlay(BuildSuccinctTrie([]string{"hat", "hatter"}))
+	o.Add("hatt")
+	o.Remove("hatter")
+	o.Add("")
+	assert.Equal(t, 4, o.SearchPrefix("hattx"))
+	assert.Equal(t, 3, o.SearchPrefix("hats"))
+	o.Remove("hat")
+	o.Remove("hatt")
+	assert.Equal(t, 0, o.SearchPrefix("hatter"))
+	assert.True(t, o.Contains(""))
+	assert.Equal(t, 1, o.Size())
+	assert.Equal(t, []string{""}, mustTrie(o.Compact()).Keys())
+	o.Remove("")
+	assert.Equal(t, 0, o.Size())
+	assert.Equal(t, 0, mustTrie(o.Compact()).Size())
+
+	defer func(n int) { maxNodes = n }(maxNodes)
+	maxNodes = 5
+	o.Add("hat")
+	o.Add("is")
+	_, err := o.Compact()
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Equal(t, 2, o.Pending())
+	assert.True(t, o.Contains("is"))
+}
+
+func TestOverlayFolded(t *testing.T) {
+	for _, opt := range []Option{WithCaseFolding(), WithUnicodeCaseFolding()} {
+		o := NewOverlay(BuildSuccinctTrie([]string{"foo", "bar"}, opt))
+		o.Add("BAZ")
+		o.Remove("BAR")
+		assert.True(t, o.Contains("baz"))
+		assert.False(t, o.Contains("bar"))
+
+		trie, err := o.Compact()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, trie.Size())
+		for _, key := range []string{"foo", "FOO", "baz", "Baz"} {
+			assert.True(t, o.Contains(key), key)
+			assert.True(t, trie.Contains(key), key)
+		}
+		assert.False(t, o.Contains("BAR"))
+	}
+}
@@ -0,0 +1,39 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParent(t *testing.T) {
+	dict := []string{"hat", "is", "it", "a", "中文", "abc", "abd"}
+	trie := BuildSuccinctTrie(dict)
+	root := trie.Root()
+
+	assert.False(t, root.Parent().Exists())
+	assert.Equal(t, "", root.Key())
+
+	for _, key := range dict {
+		node := root.Search(key)
+		assert.Equal(t, key, node.Key())
+
+		for i := len(key) - 1; i >= 0; i-- {
+			assert.Equal(t, key[i], node.Label())
+			node = node.Parent()
+			assert.Equal(t, root.Search(key[:i]), node)
+		}
+		assert.False(t, node.Parent().Exists())
+	}
+}
+
+func TestCursorBack(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a"})
+
+	c := trie.Cursor()
+	assert.False(t, c.Back())
+	assert.True(t, c.Search("hat"))
+	assert.True(t, c.Back())
+	assert.Equal(t, "ha", c.Key())
+	assert.Equal(t, trie.Root().Search("ha"), c.Node())
+}
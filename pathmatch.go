@@ -0,0 +1,47 @@
+package sutrie
+
+import (
+	"errors"
+	"path"
+)
+
+// PathMatcher finds the longest of a set of path prefixes matching a path at a "/" boundary,
+// for example a prefix "/var/log" matches "/var/log" and "/var/log/app/x.log" but not "/var/logs".
+type PathMatcher struct {
+	trie *SuccinctTrie
+}
+
+// NewPathMatcher builds a PathMatcher of prefixes with opts, see Build. Prefixes are cleaned with path.Clean,
+// so that "/var/log/" is "/var/log", and "/" matches every absolute path.
+func NewPathMatcher(prefixes []string, opts ...Option) (*PathMatcher, error) {
+	dict := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		if p == "" {
+			return nil, errors.New("sutrie: empty path prefix")
+		}
+		dict[i] = path.Clean(p)
+	}
+
+	t, err := Build(dict, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PathMatcher{trie: t}, nil
+}
+
+// Trie returns the underlying trie, whose keys are the cleaned prefixes.
+func (m *PathMatcher) Trie() *SuccinctTrie {
+	return m.trie
+}
+
+// LongestMatchingPrefix returns the longest prefix matching p, that is equal to p or followed in p by "/",
+// and whether there is one. p is expected to be clean, see path.Clean.
+func (m *PathMatcher) LongestMatchingPrefix(p string) (prefix string, ok bool) {
+	n := m.trie.Root().SearchPrefixDelim(p, '/')
+	return p[:n], n > 0
+}
+
+// Match reports whether a prefix matches p, see LongestMatchingPrefix.
+func (m *PathMatcher) Match(p string) bool {
+	return m.trie.Root().SearchPrefixDelim(p, '/') > 0
+}
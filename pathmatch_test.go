@@ -0,0 +1,47 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathMatcher(t *testing.T) {
+	m, err := NewPathMatcher([]string{"/var/log", "/var/log/app/", "/etc", "/srv/www/./data", "relative/dir"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, m.Trie().Size())
+
+	for p, want := range map[string]string{
+		"/var/log":             "/var/log",
+		"/var/log/":            "/var/log",
+		"/var/log/syslog":      "/var/log",
+		"/var/log/app":         "/var/log/app",
+		"/var/log/app/x.log":   "/var/log/app",
+		"/var/log/application": "/var/log",
+		"/var/logs/x":          "",
+		"/var":                 "",
+		"/etc/passwd":          "/etc",
+		"/etcetera":            "",
+		"/srv/www/data/index":  "/srv/www/data",
+		"relative/dir/file":    "relative/dir",
+		"relative/directory/x": "",
+		"":                     "",
+	} {
+		prefix, ok := m.LongestMatchingPrefix(p)
+		assert.Equal(t, want, prefix, p)
+		assert.Equal(t, want != "", ok, p)
+		assert.Equal(t, want != "", m.Match(p), p)
+	}
+
+	root, err := NewPathMatcher([]string{"/", "/home"})
+	assert.NoError(t, err)
+	prefix, ok := root.LongestMatchingPrefix("/usr/bin")
+	assert.True(t, ok)
+	assert.Equal(t, "/", prefix)
+	prefix, _ = root.LongestMatchingPrefix("/home/user")
+	assert.Equal(t, "/home", prefix)
+	assert.False(t, root.Match("usr"))
+
+	_, err = NewPathMatcher([]string{"/a", ""})
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package sutrie
+
+import "errors"
+
+// ErrPrefixNotFound is returned when a prefix does not correspond to any path in the trie.
+var ErrPrefixNotFound = errors.New("sutrie: prefix not found")
+
+// PinnedNode is a handle to the node of a validated prefix.
+// Queries on it are relative to the prefix, so the prefix is walked only once.
+type PinnedNode struct {
+	node   Node
+	prefix string
+}
+
+// Pin walks the prefix once and returns a handle caching the reached node.
+// It returns ErrPrefixNotFound if no key in the trie starts with prefix.
+func (t *SuccinctTrie) Pin(prefix string) (PinnedNode, error) {
+	node := t.Root().Search(prefix)
+	if !node.Exists() {
+		return PinnedNode{}, ErrPrefixNotFound
+	}
+
+	return PinnedNode{node: node, prefix: prefix}, nil
+}
+
+// Prefix returns the prefix the node was pinned at.
+func (p PinnedNode) Prefix() string {
+	return p.prefix
+}
+
+// Node returns the cached node of the pinned prefix.
+func (p PinnedNode) Node() Node {
+	return p.node
+}
+
+// Search returns the node of prefix+rest, the node may be a null node.
+func (p PinnedNode) Search(rest string) Node {
+	return p.node.Search(rest)
+}
+
+// SearchPrefix behaves like Node.SearchPrefix on prefix+rest,
+// the returned value counts the bytes of the pinned prefix as well.
+// It returns 0 if neither prefix+rest nor any of its prefixes longer than or equal to the pinned prefix is in the trie.
+func (p PinnedNode) SearchPrefix(rest string) (lastUnmatch int) {
+	if p.node.Leaf() {
+		lastUnmatch = len(p.prefix)
+	}
+	if n := p.node.SearchPrefix(rest); n > 0 {
+		lastUnmatch = len(p.prefix) + n
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPin(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"acme/a", "acme/b", "acme/bc", "acme", "other/a"})
+
+	pinned, err := trie.Pin("acme/")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/", pinned.Prefix())
+
+	assert.True(t, pinned.Search("a").Leaf())
+	assert.True(t, pinned.Search("bc").Leaf())
+	assert.False(t, pinned.Search("c").Exists())
+
+	assert.Equal(t, 7, pinned.SearchPrefix("bcd"))
+	assert.Equal(t, 0, pinned.SearchPrefix("x"))
+
+	pinned, err = trie.Pin("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, pinned.SearchPrefix("x"))
+
+	_, err = trie.Pin("nope")
+	assert.ErrorIs(t, err, ErrPrefixNotFound)
+}
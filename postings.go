@@ -0,0 +1,124 @@
+package sutrie
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+// PostingTrie is a trie mapping every key to a list of uint32 IDs, like the term dictionary of an inverted index
+// mapping terms to the documents containing them. The lists are sorted, delta-compressed as varints and
+// concatenated by leaf index, their offsets being Elias-Fano coded, so that a list of close IDs takes about
+// a byte per ID.
+type PostingTrie struct {
+	trie *SuccinctTrie
+
+	// the list of leaf i is data[offsets.Select1(i)-i:offsets.Select1(i+1)-i-1]
+	data    []byte
+	offsets *bitvec.EliasFano
+}
+
+// BuildPostingTrie constructs a PostingTrie mapping dict[i] to the IDs of postings[i], the trie being built with
+// opts like by Build. The IDs of a key given twice are merged, and duplicate IDs dropped. It fails if the lengths
+// of dict and postings differ, or with WithSuffixTruncation, which loses the keys the lists belong to.
+func BuildPostingTrie(dict []string, postings [][]uint32, opts ...Option) (*PostingTrie, error) {
+	if len(dict) != len(postings) {
+		return nil, errors.New("sutrie: number of posting lists does not match number of keys")
+	}
+
+	var o buildOptions
+	for _, opt := range opts {
+		if opt != nil {
+			_ = opt(&o)
+		}
+	}
+	if o.truncated {
+		return nil, fmt.Errorf("%w: posting trie keys cannot be truncated", ErrInvalidOption)
+	}
+
+	t, err := Build(copyKeys(dict), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([][]uint32, t.size)
+	for i, key := range dict {
+		if leaf := t.lookup(key).LeafIndex(); leaf >= 0 {
+			lists[leaf] = append(lists[leaf], postings[i]...)
+		}
+	}
+
+	p := &PostingTrie{trie: t}
+	positions := make([]int, t.size)
+	for i, list := range lists {
+		positions[i] = len(p.data) + i
+		sort.Slice(list, func(a, b int) bool { return list[a] < list[b] })
+		var prev uint32
+		for j, id := range list {
+			if j > 0 && id == prev {
+				continue
+			}
+			if j == 0 {
+				p.data = binary.AppendUvarint(p.data, uint64(id))
+			} else {
+				p.data = binary.AppendUvarint(p.data, uint64(id-prev))
+			}
+			prev = id
+		}
+	}
+	p.offsets = bitvec.NewEliasFano(positions, len(p.data)+t.size)
+	return p, nil
+}
+
+// Trie returns the underlying trie.
+func (p *PostingTrie) Trie() *SuccinctTrie {
+	return p.trie
+}
+
+// Size returns the number of keys.
+func (p *PostingTrie) Size() int {
+	return p.trie.Size()
+}
+
+// SizeInBytes returns the memory used by the lists and their offsets, not counting the trie.
+func (p *PostingTrie) SizeInBytes() int {
+	return len(p.data) + p.offsets.SizeInBytes()
+}
+
+// Get returns the IDs of key in ascending order, and whether key is in the trie.
+func (p *PostingTrie) Get(key string) ([]uint32, bool) {
+	leaf := p.trie.lookup(key).LeafIndex()
+	if leaf < 0 {
+		return nil, false
+	}
+	return p.AppendLeaf(nil, leaf), true
+}
+
+// AppendLeaf appends the IDs of the key whose LeafIndex is leaf to dst and returns it, so that the lists of
+// the keys enumerated by Walk or of a prefix can be read without allocating. It panics if leaf is out of range.
+func (p *PostingTrie) AppendLeaf(dst []uint32, leaf int) []uint32 {
+	if leaf < 0 || leaf >= p.trie.size {
+		panic("sutrie: leaf index out of range")
+	}
+	start := p.offsets.Select1(leaf) - leaf
+	end := len(p.data)
+	if leaf+1 < p.trie.size {
+		end = p.offsets.Select1(leaf+1) - leaf - 1
+	}
+
+	var id uint32
+	for i := start; i < end; {
+		delta, n := binary.Uvarint(p.data[i:end])
+		if i > start {
+			id += uint32(delta)
+		} else {
+			id = uint32(delta)
+		}
+		dst = append(dst, id)
+		i += n
+	}
+	return dst
+}
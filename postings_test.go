@@ -0,0 +1,77 @@
+package sutrie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostingTrie(t *testing.T) {
+	dict := []string{"go", "gopher", "rust", "go", "zig"}
+	postings := [][]uint32{{7, 3, 3}, {3}, {}, {1 << 31, 2}, {0}}
+	p, err := BuildPostingTrie(dict, postings)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, p.Size())
+
+	for key, want := range map[string][]uint32{"go": {2, 3, 7, 1 << 31}, "gopher": {3}, "rust": nil, "zig": {0}} {
+		ids, ok := p.Get(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want, ids, key)
+	}
+	_, ok := p.Get("gop")
+	assert.False(t, ok)
+
+	var ids []uint32
+	p.Trie().Walk(func(key string, n Node) error {
+		if n.Leaf() {
+			ids = p.AppendLeaf(ids, n.LeafIndex())
+		}
+		return nil
+	})
+	assert.Equal(t, []uint32{2, 3, 7, 1 << 31, 3, 0}, ids)
+	assert.Panics(t, func() { p.AppendLeaf(nil, 4) })
+
+	reversed, err := BuildPostingTrie([]string{"ab", "ba"}, [][]uint32{{1}, {2}}, WithReversedKeys())
+	assert.NoError(t, err)
+	ids, _ = reversed.Get("ab")
+	assert.Equal(t, []uint32{1}, ids)
+
+	_, err = BuildPostingTrie([]string{"a"}, nil)
+	assert.Error(t, err)
+	_, err = BuildPostingTrie([]string{"a"}, [][]uint32{{1}}, WithSuffixTruncation(8))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	empty, err := BuildPostingTrie([]string{}, [][]uint32{})
+	assert.NoError(t, err)
+	_, ok = empty.Get("")
+	assert.False(t, ok)
+}
+
+func TestPostingTrieSize(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	dict := domainKeys(2000)
+	postings := make([][]uint32, len(dict))
+	total := 0
+	for i := range postings {
+		id := uint32(rnd.Intn(100))
+		for j := rnd.Intn(50); j > 0; j-- {
+			id += 1 + uint32(rnd.Intn(100))
+			postings[i] = append(postings[i], id)
+		}
+		total += len(postings[i])
+	}
+	p, err := BuildPostingTrie(dict, postings)
+	assert.NoError(t, err)
+	for i, key := range dict[:100] {
+		ids, _ := p.Get(key)
+		want := append([]uint32{}, postings[i]...)
+		sort.Slice(want, func(a, b int) bool { return want[a] < want[b] })
+		if len(want) == 0 {
+			want = nil
+		}
+		assert.Equal(t, want, ids)
+	}
+	// deltas under 128 take a byte, offsets about one per key
+	assert.Less(t, p.SizeInBytes(), total+2*len(dict))
+}
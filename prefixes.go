@@ -0,0 +1,90 @@
+package sutrie
+
+import "unicode/utf8"
+
+// PrefixMatches returns the lengths of all keys under the current node which are prefixes of key, in increasing order,
+// so that every rule matching a key is found in a single pass. SearchPrefix returns the last of them.
+// The empty key, if it is a key under the current node, is a match of length 0.
+func (cur Node) PrefixMatches(key string) []int {
+	return appendPrefixMatches(nil, cur, key)
+}
+
+// PrefixMatchesBytes is like PrefixMatches but takes a byte slice, saving callers a conversion to string.
+func (cur Node) PrefixMatchesBytes(key []byte) []int {
+	return appendPrefixMatches(nil, cur, key)
+}
+
+// AppendPrefixMatches is like PrefixMatches but appends the lengths to dst and returns the extended slice.
+func (cur Node) AppendPrefixMatches(dst []int, key string) []int {
+	return appendPrefixMatches(dst, cur, key)
+}
+
+func appendPrefixMatches[K string | []byte](dst []int, cur Node, key K) []int {
+	// the empty key is a prefix of every key, as for ShortestPrefix
+	if cur.Leaf() {
+		dst = append(dst, 0)
+	}
+	for i := 0; i < len(key) && cur.Exists(); i++ {
+		if key[i] >= utf8.RuneSelf && cur.trie.fold == foldUnicode {
+			var size int
+			cur, size = nextFolded(cur, key, i)
+			i += size - 1
+		} else {
+			cur = cur.Next(key[i])
+		}
+
+		if cur.leaf {
+			dst = append(dst, i+1)
+		}
+	}
+	return dst
+}
+
+// HasPrefix reports whether a key of the trie starts with prefix, that is whether the subtree reached by prefix
+// is not empty, whether or not prefix itself is a key. Like Contains, it normalizes and reverses prefix.
+// Tries built WithSuffixTruncation only know the prefixes of keys up to where they are cut.
+func (t *SuccinctTrie) HasPrefix(prefix string) bool {
+	if t.rewritesQueries() {
+		q, buf := query(t, prefix)
+		defer normBuffers.Put(buf)
+		return t.Root().SearchBytes(q).Exists()
+	}
+	return t.Root().Search(prefix).Exists()
+}
+
+// ShortestPrefix returns the length of the shortest key of the trie which is a prefix of key, and whether there is
+// one, the first rule to match where SearchPrefix returns the last. The empty key, if in the trie, is a match of
+// length 0. Like Contains, it normalizes and reverses key.
+func (t *SuccinctTrie) ShortestPrefix(key string) (int, bool) {
+	if t.rewritesQueries() {
+		q, buf := query(t, key)
+		defer normBuffers.Put(buf)
+		return shortestPrefix(t.Root(), q)
+	}
+	return shortestPrefix(t.Root(), key)
+}
+
+// ShortestPrefix is like SuccinctTrie.ShortestPrefix for the keys under the current node, without normalization.
+func (cur Node) ShortestPrefix(key string) (int, bool) {
+	return shortestPrefix(cur, key)
+}
+
+func shortestPrefix[K string | []byte](cur Node, key K) (int, bool) {
+	if cur.leaf {
+		return 0, true
+	}
+	for i := 0; i < len(key) && cur.Exists(); i++ {
+		if key[i] >= utf8.RuneSelf && cur.trie.fold == foldUnicode {
+			var size int
+			cur, size = nextFolded(cur, key, i)
+			i += size - 1
+		} else {
+			cur = cur.Next(key[i])
+		}
+
+		if cur.leaf {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
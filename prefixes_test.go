@@ -0,0 +1,72 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixMatches(t *testing.T) {
+	root := BuildSuccinctTrie([]string{"/", "/api", "/api/v1", "/apiary", "/static"}).Root()
+
+	assert.Equal(t, []int{1, 4, 7}, root.PrefixMatches("/api/v1/users"))
+	assert.Equal(t, []int{1, 4}, root.PrefixMatchesBytes([]byte("/api/v2")))
+	assert.Equal(t, []int{1}, root.PrefixMatches("/index"))
+	assert.Empty(t, root.PrefixMatches("api"))
+	assert.Empty(t, root.PrefixMatches(""))
+	assert.Equal(t, []int{0, 1, 4}, root.AppendPrefixMatches([]int{0}, "/apiar"))
+
+	for _, key := range []string{"/api/v1/users", "/apiary", "/x", "x"} {
+		matches := root.PrefixMatches(key)
+		if len(matches) == 0 {
+			assert.Equal(t, 0, root.SearchPrefix(key))
+		} else {
+			assert.Equal(t, matches[len(matches)-1], root.SearchPrefix(key))
+		}
+	}
+
+	folded := BuildSuccinctTrie([]string{"σ", "σοφ"}, WithUnicodeCaseFolding()).Root()
+	assert.Equal(t, []int{2, 6}, folded.PrefixMatches("ΣΟΦΙΑ"))
+}
+
+func TestHasPrefix(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"/api/v1/", "/api/v2/users", "/static"})
+	for prefix, want := range map[string]bool{
+		"": true, "/": true, "/api": true, "/api/v1/": true, "/api/v2/u": true, "/api/v3": false,
+		"/static/": false, "api": false,
+	} {
+		assert.Equal(t, want, trie.HasPrefix(prefix), prefix)
+	}
+	assert.False(t, BuildSuccinctTrie(nil).HasPrefix("a"))
+	assert.True(t, BuildSuccinctTrie(nil).HasPrefix(""))
+
+	folded := BuildSuccinctTrie([]string{"Example.com"}, WithCaseFolding(), WithNormalizer(bytes.TrimSpace))
+	assert.True(t, folded.HasPrefix(" EXAMPLE"))
+	assert.False(t, folded.HasPrefix("examples"))
+}
+
+func TestShortestPrefix(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"10.", "10.1.", "10.1.2.3", "192.168."})
+	for key, want := range map[string]int{
+		"10.1.2.3": 3, "10.1.9.9": 3, "10.": 3, "192.168.0.1": 8, "192.": -1, "1": -1, "": -1,
+	} {
+		n, ok := trie.ShortestPrefix(key)
+		assert.Equal(t, want >= 0, ok, key)
+		if ok {
+			assert.Equal(t, want, n, key)
+			assert.Equal(t, trie.Root().PrefixMatches(key)[0], n, key)
+		}
+	}
+	n, ok := trie.Root().Next('1').ShortestPrefix("0.1.")
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+
+	n, ok = BuildSuccinctTrie([]string{"", "a"}).ShortestPrefix("ab")
+	assert.True(t, ok)
+	assert.Equal(t, 0, n)
+
+	n, ok = BuildSuccinctTrie([]string{"Ab", "abc"}, WithCaseFolding(), WithNormalizer(bytes.TrimSpace)).ShortestPrefix(" ABCD")
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,34 @@
+package sutrie
+
+import "fmt"
+
+// Profile is a point on the space/time curve of the encoding of a trie, see WithProfile.
+type Profile int
+
+const (
+	// Balanced is the default encoding: LOUDS-dense top levels over a sparse encoding.
+	Balanced Profile = iota
+	// SpeedOptimized adds a dispatch table to the first two levels, at up to 257 KiB, see WithDispatchTable.
+	SpeedOptimized
+	// SizeOptimized stores the leaves Elias-Fano coded and the labels remapped to their alphabet,
+	// see WithEliasFanoLeaves and WithAlphabetRemap.
+	SizeOptimized
+)
+
+// WithProfile selects the encoding choices of profile, so that they need not be set one by one.
+// Options following it override its choices, like WithProfile(SpeedOptimized), WithDispatchTable(1).
+func WithProfile(profile Profile) Option {
+	return func(o *buildOptions) error {
+		switch profile {
+		case Balanced:
+			o.dispatchLevels, o.eliasFanoLeaves, o.remap = 0, false, false
+		case SpeedOptimized:
+			o.dispatchLevels, o.eliasFanoLeaves, o.remap = 2, false, false
+		case SizeOptimized:
+			o.dispatchLevels, o.eliasFanoLeaves, o.remap = 0, true, true
+		default:
+			return fmt.Errorf("%w: unknown profile %d", ErrInvalidOption, profile)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package sutrie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProfile(t *testing.T) {
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, fmt.Sprintf("/usr/share/doc/pkg%d/README", i*7))
+	}
+
+	balanced := BuildSuccinctTrie(append([]string(nil), dict...), WithProfile(Balanced))
+	speed := BuildSuccinctTrie(append([]string(nil), dict...), WithProfile(SpeedOptimized))
+	size := BuildSuccinctTrie(append([]string(nil), dict...), WithProfile(SizeOptimized))
+	assert.True(t, balanced.Equal(speed))
+	assert.True(t, balanced.Equal(size))
+
+	assert.Less(t, size.SizeInBytes(), balanced.SizeInBytes())
+	assert.Greater(t, speed.SizeInBytes(), balanced.SizeInBytes())
+	assert.Equal(t, 2, speed.dispatchLevels)
+	assert.NotNil(t, size.sparseLeaves)
+	assert.NotNil(t, size.alphabet)
+
+	// later options override the profile
+	trie := BuildSuccinctTrie([]string{"a"}, WithProfile(SpeedOptimized), WithDispatchTable(1))
+	assert.Equal(t, 1, trie.dispatchLevels)
+	trie = BuildSuccinctTrie([]string{"a"}, WithEliasFanoLeaves(), WithProfile(Balanced))
+	assert.Nil(t, trie.sparseLeaves)
+
+	_, err := Build([]string{"a"}, WithProfile(Profile(42)))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
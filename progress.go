@@ -0,0 +1,47 @@
+package sutrie
+
+import "context"
+
+// BuildSuccinctTrieCtx is like Build, but it gives up with ctx.Err() once ctx is done, so that servers can cancel
+// superseded rebuilds. If progress is not nil, it is called from the building goroutine with the number of nodes
+// built and their total, 0 of them once the keys are sorted, then every 64Ki keys of every level, and total
+// of them at the end. The keys are sorted first, which is not interrupted.
+func BuildSuccinctTrieCtx(ctx context.Context, dict []string, progress func(done, total int), opts ...Option) (*SuccinctTrie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *buildOptions) error {
+		o.progress = &buildProgress{ctx, progress}
+		return nil
+	})
+	return Build(dict, opts...)
+}
+
+// progressWords is the mask of the words of keys between progress reports, 1024 words of 64 keys.
+const progressWords = 1<<10 - 1
+
+// buildProgress is the context of a build and its progress callback, see BuildSuccinctTrieCtx.
+// A nil *buildProgress is a build that cannot be canceled.
+type buildProgress struct {
+	ctx      context.Context
+	progress func(done, total int)
+}
+
+// err returns the error of the context.
+func (p *buildProgress) err() error {
+	if p == nil {
+		return nil
+	}
+	return p.ctx.Err()
+}
+
+// report reports done nodes built out of total and returns the error of the context.
+func (p *buildProgress) report(done, total int) error {
+	if p == nil {
+		return nil
+	}
+	if p.progress != nil {
+		p.progress(done, total)
+	}
+	return p.ctx.Err()
+}
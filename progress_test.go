@@ -0,0 +1,48 @@
+package sutrie
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSuccinctTrieCtx(t *testing.T) {
+	dict := domainKeys(100000)
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	var reports [][2]int
+	trie, err := BuildSuccinctTrieCtx(context.Background(), append([]string(nil), dict...), func(done, total int) {
+		reports = append(reports, [2]int{done, total})
+	}, WithReversedKeys())
+	assert.NoError(t, err)
+	assert.True(t, trie.reversed)
+	assert.Equal(t, plain.Size(), trie.Size())
+
+	total := trie.numNodes()
+	assert.Greater(t, len(reports), 10)
+	assert.Equal(t, [2]int{0, total}, reports[0])
+	assert.Equal(t, [2]int{total, total}, reports[len(reports)-1])
+	for i := 1; i < len(reports); i++ {
+		assert.LessOrEqual(t, reports[i-1][0], reports[i][0])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err = BuildSuccinctTrieCtx(ctx, append([]string(nil), dict...), func(done, total int) {
+		if calls++; done > total/2 {
+			cancel()
+		}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, calls, len(reports))
+
+	_, err = BuildSuccinctTrieCtx(ctx, []string{"a"}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	trie, err = BuildSuccinctTrieCtx(context.Background(), []string{"a", "b"}, nil, WithSuffixTruncation(8))
+	assert.NoError(t, err)
+	assert.True(t, trie.MayContain("a"))
+	_, err = BuildSuccinctTrieCtx(context.Background(), nil, nil)
+	assert.ErrorIs(t, err, ErrNilInput)
+}
@@ -0,0 +1,82 @@
+package sutrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildPublicSuffixList builds a trie of rules in the format of the Public Suffix List (https://publicsuffix.org/list/):
+// a rule is a domain like "com", a wildcard "*.example.com" matching exactly one more label,
+// or an exception "!foo.example.com" overriding a wildcard. Blank lines and "//" comments are skipped.
+// The trie is built WithReversedKeys, query it with MatchDomain.
+func BuildPublicSuffixList(rules []string, opts ...Option) (*SuccinctTrie, error) {
+	dict := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || strings.HasPrefix(rule, "//") {
+			continue
+		}
+		if err := validateRule(rule); err != nil {
+			return nil, err
+		}
+		dict = append(dict, strings.ToLower(rule))
+	}
+
+	return Build(dict, append(opts, WithReversedKeys())...)
+}
+
+func validateRule(rule string) error {
+	labels := strings.Split(strings.TrimPrefix(rule, "!"), ".")
+	for i, label := range labels {
+		if label == "" || strings.ContainsAny(label, "!") || strings.Contains(label, "*") && (label != "*" || i != 0) {
+			return fmt.Errorf("sutrie: invalid public suffix rule %q", rule)
+		}
+	}
+	if rule[0] == '!' && (len(labels) < 2 || labels[0] == "*") {
+		return fmt.Errorf("sutrie: invalid public suffix rule %q", rule)
+	}
+	return nil
+}
+
+// MatchDomain returns the public suffix of host according to the rules of the trie, with the semantics of
+// the Public Suffix List: the matching rule with the most labels prevails, unless an exception rule matches,
+// in which case the public suffix is the exception without its leftmost label. ok is false if no rule matches.
+// The rules are usually built with BuildPublicSuffixList, host must be in lower case.
+func (t *SuccinctTrie) MatchDomain(host string) (suffix string, ok bool) {
+	matched := -1
+	for i := len(host); i > 0; {
+		// host[j:] is the domain made of one more label
+		j := strings.LastIndexByte(host[:i-1], '.') + 1
+		if i < len(host) && host[i] != '.' || j == i {
+			break
+		}
+
+		if t.hasRule("!", host[j:]) {
+			return host[strings.IndexByte(host[j:], '.')+j+1:], true
+		}
+		if t.hasRule("", host[j:]) || t.hasRule("*", host[i:]) {
+			matched = j
+		}
+		i = j - 1
+	}
+
+	if matched < 0 {
+		return "", false
+	}
+	return host[matched:], true
+}
+
+// hasRule reports whether the trie contains p+s.
+func (t *SuccinctTrie) hasRule(p, s string) bool {
+	n := t.Root()
+	if t.reversed {
+		for i := len(s) - 1; i >= 0 && n.Exists(); i-- {
+			n = n.Next(s[i])
+		}
+		for i := len(p) - 1; i >= 0 && n.Exists(); i-- {
+			n = n.Next(p[i])
+		}
+		return n.Leaf()
+	}
+	return n.Search(p).Search(s).Leaf()
+}
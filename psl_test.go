@@ -0,0 +1,54 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchDomain(t *testing.T) {
+	rules := []string{
+		"// comment",
+		"com",
+		"*.jp",
+		"*.hokkaido.jp",
+		"*.tokyo.jp",
+		"!pref.hokkaido.jp",
+		"!metro.tokyo.jp",
+		"",
+		"co.uk",
+		"uk",
+	}
+	trie, err := BuildPublicSuffixList(rules)
+	assert.NoError(t, err)
+
+	plain := BuildSuccinctTrie([]string{"com", "*.jp", "*.hokkaido.jp", "*.tokyo.jp", "!pref.hokkaido.jp", "!metro.tokyo.jp", "co.uk", "uk"})
+
+	for host, want := range map[string]string{
+		"com":                "com",
+		"example.com":        "com",
+		"b.example.com":      "com",
+		"jp":                 "",
+		"kyoto.jp":           "kyoto.jp",
+		"foo.kyoto.jp":       "kyoto.jp",
+		"a.pref.hokkaido.jp": "hokkaido.jp",
+		"pref.hokkaido.jp":   "hokkaido.jp",
+		"foo.hokkaido.jp":    "foo.hokkaido.jp",
+		"metro.tokyo.jp":     "tokyo.jp",
+		"foo.bar.tokyo.jp":   "bar.tokyo.jp",
+		"bbc.co.uk":          "co.uk",
+		"example.org":        "",
+		"":                   "",
+	} {
+		for _, trie := range []*SuccinctTrie{trie, plain} {
+			suffix, ok := trie.MatchDomain(host)
+			assert.Equal(t, want != "", ok, host)
+			assert.Equal(t, want, suffix, host)
+		}
+	}
+
+	for _, rule := range []string{"a.*.com", "!com", "**.com", "a..com", "!*.com"} {
+		_, err := BuildPublicSuffixList([]string{rule})
+		assert.Error(t, err, rule)
+	}
+}
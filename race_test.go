@@ -0,0 +1,7 @@
+//go:build race
+
+package sutrie
+
+// raceEnabled is true if the race detector is on, which drops items of sync.Pool at random, so that
+// pooled buffers allocate.
+const raceEnabled = true
@@ -0,0 +1,212 @@
+package sutrie
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"unicode/utf8"
+)
+
+// MatchRegexp returns all keys under the current node which re matches entirely, as if it were enclosed in ^(?:...)$,
+// in lexicographic order unless changed by opts. The returned keys are relative to the current node.
+// The trie is descended along with the automaton of re, pruning the branches it cannot match,
+// which is much faster than testing every key when re starts with literals or constrains the alphabet.
+// Keys are read as UTF-8, every byte of an invalid sequence being U+FFFD as for re.
+func (n Node) MatchRegexp(re *regexp.Regexp, opts ...IterOption) []string {
+	return n.MatchProg(compileRegexp(re), opts...)
+}
+
+// MatchRegexpSeq is like MatchRegexp but yields the keys one at a time, see KeysSeq.
+func (n Node) MatchRegexpSeq(re *regexp.Regexp, opts ...IterOption) func(yield func(key string) bool) {
+	return n.MatchProgSeq(compileRegexp(re), opts...)
+}
+
+func compileRegexp(re *regexp.Regexp) *syntax.Prog {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		panic("sutrie: cannot parse " + re.String() + ": " + err.Error())
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		panic("sutrie: cannot compile " + re.String() + ": " + err.Error())
+	}
+	return prog
+}
+
+// MatchProg is like MatchRegexp but takes the compiled program of a regular expression, whose matches must be
+// entire keys. Its captures are ignored.
+func (n Node) MatchProg(prog *syntax.Prog, opts ...IterOption) (keys []string) {
+	n.MatchProgSeq(prog, opts...)(func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
+
+// MatchProgSeq is like MatchProg but yields the keys one at a time, see KeysSeq.
+func (n Node) MatchProgSeq(prog *syntax.Prog, opts ...IterOption) func(yield func(key string) bool) {
+	return func(yield func(key string) bool) {
+		if !n.Exists() {
+			return
+		}
+
+		m := progMatcher{prog: prog, order: newIterOrder(opts), on: make([]bool, len(prog.Inst))}
+		m.walk(n, progState{pcs: []uint32{uint32(prog.Start)}, prev: -1}, func(key []byte) bool {
+			return yield(string(key))
+		})
+	}
+}
+
+type progMatcher struct {
+	prog  *syntax.Prog
+	order iterOrder
+	key   []byte
+	on    []bool // the instructions of the closure being computed
+}
+
+// progState is the state of the automaton after a key, the instructions it is at before following
+// the empty transitions, which depend on the next rune, the last rune and the bytes of an incomplete one.
+type progState struct {
+	pcs     []uint32
+	prev    rune
+	pending []byte
+}
+
+// closure appends to dst the instructions of pcs and those reached by their empty transitions between
+// the runes before and after, -1 being the ends of the key. If loose, every empty-width assertion holds.
+func (m *progMatcher) closure(dst, pcs []uint32, before, after rune, loose bool) []uint32 {
+	start := len(dst)
+	var add func(pc uint32)
+	add = func(pc uint32) {
+		if m.on[pc] {
+			return
+		}
+		m.on[pc] = true
+		dst = append(dst, pc)
+		switch i := &m.prog.Inst[pc]; i.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			add(i.Out)
+			add(i.Arg)
+		case syntax.InstCapture, syntax.InstNop:
+			add(i.Out)
+		case syntax.InstEmptyWidth:
+			if loose || i.MatchEmptyWidth(before, after) {
+				add(i.Out)
+			}
+		}
+	}
+	for _, pc := range pcs {
+		add(pc)
+	}
+	for _, pc := range dst[start:] {
+		m.on[pc] = false
+	}
+	return dst
+}
+
+// step returns the instructions after r.
+func (m *progMatcher) step(pcs []uint32, prev, r rune) []uint32 {
+	var next []uint32
+	for _, pc := range m.closure(nil, pcs, prev, r, false) {
+		i := &m.prog.Inst[pc]
+		var ok bool
+		switch i.Op {
+		case syntax.InstRune, syntax.InstRune1:
+			ok = i.MatchRune(r)
+		case syntax.InstRuneAny:
+			ok = true
+		case syntax.InstRuneAnyNotNL:
+			ok = r != '\n'
+		}
+		if ok && !m.on[i.Out] {
+			m.on[i.Out] = true
+			next = append(next, i.Out)
+		}
+	}
+	for _, pc := range next {
+		m.on[pc] = false
+	}
+	return next
+}
+
+// feed returns the state after b, which is empty if no key with its bytes so far can match.
+func (m *progMatcher) feed(s progState, b byte) progState {
+	pending := append(s.pending[:len(s.pending):len(s.pending)], b)
+	for len(pending) > 0 && len(s.pcs) > 0 && utf8.FullRune(pending) {
+		r, size := utf8.DecodeRune(pending)
+		s.pcs, s.prev = m.step(s.pcs, s.prev, r), r
+		pending = pending[size:]
+	}
+	s.pending = pending
+	return s
+}
+
+// accepts reports whether s matches at the end of a key.
+func (m *progMatcher) accepts(s progState) bool {
+	// every byte of an incomplete rune is an invalid one
+	for range s.pending {
+		s.pcs, s.prev = m.step(s.pcs, s.prev, utf8.RuneError), utf8.RuneError
+	}
+	for _, pc := range m.closure(nil, s.pcs, s.prev, -1, false) {
+		if m.prog.Inst[pc].Op == syntax.InstMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// literals returns the bytes a transition can happen on from s in iteration order, ok is false unless
+// every instruction consuming the next rune is an ASCII literal.
+func (m *progMatcher) literals(s progState) (lits []byte, ok bool) {
+	if len(s.pending) > 0 {
+		return nil, false
+	}
+	for _, pc := range m.closure(nil, s.pcs, s.prev, 0, true) {
+		switch i := &m.prog.Inst[pc]; i.Op {
+		case syntax.InstRune1:
+			if i.Rune[0] >= utf8.RuneSelf || syntax.Flags(i.Arg)&syntax.FoldCase != 0 {
+				return nil, false
+			}
+			lits = append(lits, byte(i.Rune[0]))
+		case syntax.InstRune, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			return nil, false
+		}
+	}
+	sort.Slice(lits, func(i, j int) bool { return m.order.lessByte(lits[i], lits[j]) })
+	return lits, true
+}
+
+// walk calls emit with the keys matching from n until it returns false, in which case walk does too.
+func (m *progMatcher) walk(n Node, s progState, emit func([]byte) bool) bool {
+	accept := n.Leaf() && m.accepts(s)
+	if accept && !m.order.reverse && !emit(m.key) {
+		return false
+	}
+
+	visit := func(k int32) bool {
+		b := n.trie.label(k)
+		if next := m.feed(s, b); len(next.pcs) > 0 {
+			m.key = append(m.key, b)
+			ok := m.walk(n.next(k), next, emit)
+			m.key = m.key[:len(m.key)-1]
+			return ok
+		}
+		return true
+	}
+
+	ok := true
+	if lits, literal := m.literals(s); literal {
+		for i, b := range lits {
+			if i > 0 && lits[i-1] == b {
+				continue
+			}
+			if k := n.child(b); k != -1 && !visit(k) {
+				ok = false
+				break
+			}
+		}
+	} else {
+		ok = m.order.children(n, visit)
+	}
+	return ok && (!accept || !m.order.reverse || emit(m.key))
+}
@@ -0,0 +1,59 @@
+package sutrie
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchRegexp(t *testing.T) {
+	dict := append(domainKeys(2000), "", "a", "ab", "abc", "a b", "A.B", "héllo", "hello", "h\xffllo", "h\xe2llo",
+		"foo\nbar", "x1", "x22", "日本語")
+	sort.Strings(dict)
+	root := BuildSuccinctTrie(append([]string(nil), dict...)).Root()
+
+	for _, expr := range []string{
+		``, `a`, `a.*`, `.*\.com`, `[a-c]+`, `(?i)a\.b`, `a\b.*`, `.*\bb`, `^ab$`, `h.llo`, `h\pLllo`,
+		`(foo|bar)\..*`, `.*\.(com|org)`, `x\d+`, `x\d{2}`, `(?s)foo.bar`, `foo.bar`, `(?m)foo$\nbar`, `\p{Han}+`,
+		`[^a-z].*`, `.{3}`, `a|ab|abc`, `.*(?:ab|cd).*`,
+	} {
+		re := regexp.MustCompile(expr)
+		full := regexp.MustCompile(`^(?:` + expr + `)$`)
+		var want []string
+		for _, key := range dict {
+			if full.MatchString(key) && (len(want) == 0 || want[len(want)-1] != key) {
+				want = append(want, key)
+			}
+		}
+		assert.Equal(t, want, root.MatchRegexp(re), expr)
+	}
+
+	re := regexp.MustCompile(`a.*`)
+	keys := root.MatchRegexp(re, Reverse())
+	assert.True(t, sort.IsSorted(sort.Reverse(sort.StringSlice(keys))))
+	sub := BuildSuccinctTrie([]string{"a", "a b", "ab", "abc", "b"}).Root().Search("a")
+	assert.Equal(t, []string{"", " b", "b", "bc"}, sub.MatchRegexp(regexp.MustCompile(`.*`)))
+	assert.Equal(t, []string{"b"}, sub.MatchRegexp(regexp.MustCompile(`\bb`)))
+	assert.Nil(t, root.Search("zzzz").MatchRegexp(re))
+}
+
+func BenchmarkMatchRegexp(b *testing.B) {
+	root := BuildSuccinctTrie(domainKeys(200000)).Root()
+	re := regexp.MustCompile(`ab[a-z]*\.(com|org)`)
+	b.Run("trie", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			root.MatchRegexp(re)
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		full := regexp.MustCompile(`^(?:` + re.String() + `)$`)
+		keys := root.Keys()
+		for i := 0; i < b.N; i++ {
+			for _, key := range keys {
+				full.MatchString(key)
+			}
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package sutrie
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Reloadable holds a trie which can be replaced while it is queried, for long-running services
+// reloading their dictionary. Queries load the current trie atomically and never block,
+// a query running during a swap completes on the trie it started with.
+// The zero value holds no trie and must not be queried, use NewReloadable.
+type Reloadable struct {
+	trie atomic.Pointer[SuccinctTrie]
+}
+
+var _ Searcher = (*Reloadable)(nil)
+
+// NewReloadable returns a Reloadable holding t.
+func NewReloadable(t *SuccinctTrie) *Reloadable {
+	r := &Reloadable{}
+	r.Store(t)
+	return r
+}
+
+// Load returns the current trie, which can be used for several queries on the same version of the dictionary.
+func (r *Reloadable) Load() *SuccinctTrie {
+	return r.trie.Load()
+}
+
+// Store replaces the current trie by t.
+func (r *Reloadable) Store(t *SuccinctTrie) {
+	r.trie.Store(t)
+}
+
+// Reload replaces the current trie by the one unmarshaled from reader, see SuccinctTrie.Unmarshal.
+// The current trie is kept if it fails. The new trie is warmed up before it is stored, see SuccinctTrie.Warmup.
+func (r *Reloadable) Reload(reader io.Reader) error {
+	t := &SuccinctTrie{}
+	if err := t.Unmarshal(reader); err != nil {
+		return err
+	}
+	t.Warmup()
+	r.Store(t)
+	return nil
+}
+
+// Rebuild replaces the current trie by one built from dict, see Build. The current trie is kept if it fails.
+func (r *Reloadable) Rebuild(dict []string, opts ...Option) error {
+	t, err := Build(dict, opts...)
+	if err != nil {
+		return err
+	}
+	r.Store(t)
+	return nil
+}
+
+// Root returns the root of the current trie.
+func (r *Reloadable) Root() Node {
+	return r.Load().Root()
+}
+
+// Size returns the number of keys of the current trie.
+func (r *Reloadable) Size() int {
+	return r.Load().Size()
+}
+
+// Contains reports whether key is in the current trie.
+func (r *Reloadable) Contains(key string) bool {
+	return r.Load().Contains(key)
+}
+
+// SearchPrefix is SearchPrefix of the current trie.
+func (r *Reloadable) SearchPrefix(key string) int {
+	return r.Load().SearchPrefix(key)
+}
+
+// MayContain is MayContain of the current trie.
+func (r *Reloadable) MayContain(key string) bool {
+	return r.Load().MayContain(key)
+}
+
+// MatchDomainSuffix is MatchDomainSuffix of the current trie.
+func (r *Reloadable) MatchDomainSuffix(host string) bool {
+	return r.Load().MatchDomainSuffix(host)
+}
@@ -0,0 +1,55 @@
+package sutrie
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadable(t *testing.T) {
+	r := NewReloadable(BuildSuccinctTrie([]string{"a.com", "b.com"}))
+	assert.True(t, r.Contains("a.com"))
+	assert.Equal(t, 2, r.Size())
+
+	assert.NoError(t, r.Rebuild([]string{"c.com"}, WithReversedKeys()))
+	assert.False(t, r.Contains("a.com"))
+	assert.True(t, r.MatchDomainSuffix("www.c.com"))
+
+	assert.ErrorIs(t, r.Rebuild(nil), ErrNilInput)
+	assert.True(t, r.MatchDomainSuffix("www.c.com"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, BuildSuccinctTrie([]string{"hat", "is"}).Marshal(&buf))
+	assert.NoError(t, r.Reload(&buf))
+	assert.Equal(t, 3, r.SearchPrefix("hatt"))
+	assert.Error(t, r.Reload(strings.NewReader("garbage")))
+	assert.True(t, r.Contains("is"))
+
+	// readers see either version in full while it is swapped
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				trie := r.Load()
+				assert.True(t, trie.Contains("is") != trie.Contains("was"))
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		r.Store(BuildSuccinctTrie([]string{"was"}))
+		r.Store(BuildSuccinctTrie([]string{"is"}))
+	}
+	close(done)
+	wg.Wait()
+}
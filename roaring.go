@@ -0,0 +1,216 @@
+package sutrie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// Cookies of the portable Roaring bitmap serialization format,
+// see https://github.com/RoaringBitmap/RoaringFormatSpec
+const (
+	roaringSerialCookieNoRun = 12346
+	roaringSerialCookie      = 12347
+	roaringArrayMaxSize      = 4096
+	roaringBitmapWords       = 1024
+)
+
+// ErrInvalidRoaring is returned when the input is not a valid portable Roaring bitmap.
+var ErrInvalidRoaring = errors.New("sutrie: invalid roaring bitmap")
+
+// leafRank returns the rank of the leaf of key among all leaves.
+func (t *SuccinctTrie) leafRank(key string) (int32, bool) {
+	if i := t.Root().Search(key).LeafIndex(); i >= 0 {
+		return int32(i), true
+	}
+	return 0, false
+}
+
+// LeafRanks returns the sorted and deduplicated leaf ranks of the keys that are in the trie.
+// Keys that are not in the trie are ignored.
+func (t *SuccinctTrie) LeafRanks(keys []string) []uint32 {
+	ranks := make([]uint32, 0, len(keys))
+	for _, key := range keys {
+		if r, ok := t.leafRank(key); ok {
+			ranks = append(ranks, uint32(r))
+		}
+	}
+
+	sort.Slice(ranks, func(i, j int) bool { return ranks[i] < ranks[j] })
+	j := 0
+	for i := range ranks {
+		if i == 0 || ranks[i] != ranks[j-1] {
+			ranks[j] = ranks[i]
+			j++
+		}
+	}
+	return ranks[:j]
+}
+
+// WriteRoaring writes the leaf ranks of keys as a portable Roaring bitmap,
+// so that key subsets can be combined by systems speaking Roaring without exchanging strings.
+func (t *SuccinctTrie) WriteRoaring(w io.Writer, keys []string) error {
+	return writeRoaring(w, t.LeafRanks(keys))
+}
+
+// WriteLeavesRoaring writes the raw leaves bitset, that is the positions of leaf nodes in level order,
+// as a portable Roaring bitmap.
+func (t *SuccinctTrie) WriteLeavesRoaring(w io.Writer) error {
+	values := make([]uint32, t.size)
+	for k := range values {
+		values[k] = uint32(t.selectLeaf(k))
+	}
+	return writeRoaring(w, values)
+}
+
+// ReadRoaring reads a portable Roaring bitmap and returns its values in ascending order.
+func ReadRoaring(r io.Reader) ([]uint32, error) {
+	br := bufio.NewReader(r)
+	read := func(data any) error {
+		if err := binary.Read(br, binary.LittleEndian, data); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ErrInvalidRoaring
+			}
+			return err
+		}
+		return nil
+	}
+
+	var cookie uint32
+	if err := read(&cookie); err != nil {
+		return nil, err
+	}
+
+	var size uint32
+	var runs []byte
+	switch {
+	case cookie == roaringSerialCookieNoRun:
+		if err := read(&size); err != nil {
+			return nil, err
+		}
+	case cookie&0xffff == roaringSerialCookie:
+		size = cookie>>16 + 1
+		runs = make([]byte, (size+7)/8)
+		if err := read(runs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidRoaring
+	}
+
+	header := make([]uint16, 2*size)
+	if err := read(header); err != nil {
+		return nil, err
+	}
+
+	if runs == nil || size >= 4 {
+		offsets := make([]uint32, size)
+		if err := read(offsets); err != nil {
+			return nil, err
+		}
+	}
+
+	var values []uint32
+	for i := uint32(0); i < size; i++ {
+		high := uint32(header[2*i]) << 16
+		card := int(header[2*i+1]) + 1
+
+		switch {
+		case runs != nil && runs[i/8]&(1<<(i%8)) != 0:
+			var n uint16
+			if err := read(&n); err != nil {
+				return nil, err
+			}
+			pairs := make([]uint16, 2*int(n))
+			if err := read(pairs); err != nil {
+				return nil, err
+			}
+			for j := 0; j < len(pairs); j += 2 {
+				for v := uint32(pairs[j]); v <= uint32(pairs[j])+uint32(pairs[j+1]); v++ {
+					values = append(values, high|v)
+				}
+			}
+		case card <= roaringArrayMaxSize:
+			array := make([]uint16, card)
+			if err := read(array); err != nil {
+				return nil, err
+			}
+			for _, v := range array {
+				values = append(values, high|uint32(v))
+			}
+		default:
+			words := make([]uint64, roaringBitmapWords)
+			if err := read(words); err != nil {
+				return nil, err
+			}
+			for j, word := range words {
+				for ; word != 0; word &= word - 1 {
+					values = append(values, high|uint32(j<<6+bits.TrailingZeros64(word)))
+				}
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// writeRoaring writes sorted distinct values as a portable Roaring bitmap without run containers.
+func writeRoaring(w io.Writer, values []uint32) error {
+	type container struct {
+		key    uint16
+		values []uint32
+	}
+
+	var containers []container
+	for i := 0; i < len(values); {
+		j := i
+		for j < len(values) && values[j]>>16 == values[i]>>16 {
+			j++
+		}
+		containers = append(containers, container{uint16(values[i] >> 16), values[i:j]})
+		i = j
+	}
+
+	bw := bufio.NewWriter(w)
+	write := func(data any) {
+		// errors are sticky in bufio.Writer and reported by Flush
+		_ = binary.Write(bw, binary.LittleEndian, data)
+	}
+
+	write(uint32(roaringSerialCookieNoRun))
+	write(uint32(len(containers)))
+	for _, c := range containers {
+		write([]uint16{c.key, uint16(len(c.values) - 1)})
+	}
+
+	offset := uint32(8 + 8*len(containers))
+	for _, c := range containers {
+		write(offset)
+		if len(c.values) <= roaringArrayMaxSize {
+			offset += uint32(2 * len(c.values))
+		} else {
+			offset += 8 * roaringBitmapWords
+		}
+	}
+
+	for _, c := range containers {
+		if len(c.values) <= roaringArrayMaxSize {
+			array := make([]uint16, len(c.values))
+			for i, v := range c.values {
+				array[i] = uint16(v)
+			}
+			write(array)
+		} else {
+			words := make([]uint64, roaringBitmapWords)
+			for _, v := range c.values {
+				words[v&0xffff>>6] |= uint64(1) << (v & 63)
+			}
+			write(words)
+		}
+	}
+
+	return bw.Flush()
+}
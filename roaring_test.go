@@ -0,0 +1,68 @@
+package sutrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRoaring(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a"})
+
+	assert.Equal(t, []uint32{0, 2, 3}, trie.LeafRanks([]string{"it", "a", "hat", "it", "missing"}))
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.WriteRoaring(&buf, []string{"it", "hat"}))
+	assert.Equal(t, []byte{
+		0x3a, 0x30, 0, 0, 1, 0, 0, 0, // cookie, container count
+		0, 0, 1, 0, // key 0, cardinality 2
+		16, 0, 0, 0, // offset
+		2, 0, 3, 0, // values
+	}, buf.Bytes())
+
+	values, err := ReadRoaring(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2, 3}, values)
+
+	buf.Reset()
+	assert.NoError(t, trie.WriteLeavesRoaring(&buf))
+	values, err = ReadRoaring(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 5, 6, 7}, values)
+}
+
+func TestRoaringContainers(t *testing.T) {
+	var values []uint32
+	for i := uint32(0); i < 10000; i += 2 {
+		values = append(values, i)
+	}
+	values = append(values, 1<<16|7, 5<<16|1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeRoaring(&buf, values))
+
+	decoded, err := ReadRoaring(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, values, decoded)
+
+	_, err = ReadRoaring(bytes.NewReader([]byte{1, 2, 3, 4}))
+	assert.ErrorIs(t, err, ErrInvalidRoaring)
+}
+
+func TestReadRoaringRunContainer(t *testing.T) {
+	var buf bytes.Buffer
+	for _, v := range []any{
+		uint32(roaringSerialCookie), // one container
+		uint8(1),                    // run bitmap
+		[]uint16{2, 4},              // key 2, cardinality 5
+		uint16(2), []uint16{10, 2, 20, 1},
+	} {
+		assert.NoError(t, binary.Write(&buf, binary.LittleEndian, v))
+	}
+
+	values, err := ReadRoaring(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2<<16 | 10, 2<<16 | 11, 2<<16 | 12, 2<<16 | 20, 2<<16 | 21}, values)
+}
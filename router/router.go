@@ -0,0 +1,224 @@
+// Package router dispatches HTTP requests over a static route table compiled into a succinct trie.
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/nobekanai/sutrie"
+)
+
+// Labels standing for the segments of a pattern which are parameters in the keys of the trie,
+// they are not allowed in paths.
+const (
+	paramLabel    = '\x01'
+	wildcardLabel = '\x02'
+)
+
+// Route is a route of a Router. Pattern is a path whose segments may be parameters ":name" matching any
+// non-empty segment, and whose last segment may be a wildcard "*name" matching the rest of the path,
+// like "/users/:id/files/*path".
+type Route struct {
+	Method  string
+	Pattern string
+	Handler http.Handler
+}
+
+// Param is a parameter of a pattern with the value it matched.
+type Param struct {
+	Key, Value string
+}
+
+// Params are the parameters of the route of a request, in the order of the pattern.
+type Params []Param
+
+// ByName returns the value of the parameter name, or "" if there is none.
+func (ps Params) ByName(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+type paramsKey struct{}
+
+// ParamsFromContext returns the parameters of the route stored in the context of a request by Router.
+func ParamsFromContext(ctx context.Context) Params {
+	ps, _ := ctx.Value(paramsKey{}).(Params)
+	return ps
+}
+
+// Router is an http.Handler dispatching requests to the handler of the route matching their method and path.
+// Static segments take precedence over parameters, which take precedence over wildcards.
+// It is read-only, the routes being fixed by New.
+type Router struct {
+	// NotFound handles the requests matching no route, http.NotFound if nil
+	NotFound http.Handler
+
+	trie    *sutrie.SuccinctTrie
+	routes  []compiled // indexed by Node.LeafIndex
+	methods []string
+}
+
+type compiled struct {
+	handler http.Handler
+	names   []string
+}
+
+// New compiles routes into a Router. It fails if a pattern is invalid or two routes have the same method
+// and pattern, parameter names aside.
+func New(routes []Route) (*Router, error) {
+	keys := make([]string, len(routes))
+	names := make([][]string, len(routes))
+	methods := make(map[string]bool)
+	for i, r := range routes {
+		if r.Method == "" || strings.ContainsAny(r.Method, " /") || r.Handler == nil {
+			return nil, fmt.Errorf("router: invalid route %s %s", r.Method, r.Pattern)
+		}
+		pattern, err := compilePattern(r.Pattern, &names[i])
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = r.Method + " " + pattern
+		methods[r.Method] = true
+	}
+
+	trie, err := sutrie.Build(append([]string{}, keys...))
+	if err != nil {
+		return nil, err
+	}
+	if trie.Size() != len(keys) {
+		return nil, fmt.Errorf("router: conflicting routes")
+	}
+
+	rt := &Router{trie: trie, routes: make([]compiled, len(routes))}
+	for i, key := range keys {
+		rt.routes[trie.Root().Search(key).LeafIndex()] = compiled{routes[i].Handler, names[i]}
+	}
+	for m := range methods {
+		rt.methods = append(rt.methods, m)
+	}
+	sort.Strings(rt.methods)
+	return rt, nil
+}
+
+// compilePattern returns the key of pattern, its parameters being replaced with paramLabel or wildcardLabel,
+// and appends their names to names.
+func compilePattern(pattern string, names *[]string) (string, error) {
+	if !strings.HasPrefix(pattern, "/") || strings.ContainsAny(pattern, string([]byte{paramLabel, wildcardLabel})) {
+		return "", fmt.Errorf("router: invalid pattern %q", pattern)
+	}
+
+	segments := strings.Split(pattern[1:], "/")
+	for i, seg := range segments {
+		if seg == "" || seg[0] != ':' && seg[0] != '*' {
+			continue
+		}
+		if len(seg) == 1 || seg[0] == '*' && i != len(segments)-1 {
+			return "", fmt.Errorf("router: invalid pattern %q", pattern)
+		}
+
+		*names = append(*names, seg[1:])
+		segments[i] = string(paramLabel)
+		if seg[0] == '*' {
+			segments[i] = string(wildcardLabel)
+		}
+	}
+	return "/" + strings.Join(segments, "/"), nil
+}
+
+// Lookup returns the handler of the route matching method and path with the values of its parameters,
+// ok is false if there is none.
+func (rt *Router) Lookup(method, path string) (h http.Handler, ps Params, ok bool) {
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, string([]byte{paramLabel, wildcardLabel})) {
+		return nil, nil, false
+	}
+
+	n := rt.trie.Root().Search(method).Next(' ').Next('/')
+	var values []string
+	leaf, ok := match(n, path[1:], &values)
+	if !ok {
+		return nil, nil, false
+	}
+
+	r := rt.routes[leaf]
+	ps = make(Params, len(r.names))
+	for i, name := range r.names {
+		ps[i] = Param{name, values[i]}
+	}
+	return r.handler, ps, true
+}
+
+// match matches path, the rest of a path starting at a segment, from n, appending the values of
+// the parameters to values. It returns the LeafIndex of the route matched.
+func match(n sutrie.Node, path string, values *[]string) (int, bool) {
+	if !n.Exists() {
+		return 0, false
+	}
+
+	seg, rest := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		seg, rest = path[:i], path[i:]
+	}
+	// next matches the rest of the path from m, the node reached by the segment
+	next := func(m sutrie.Node) (int, bool) {
+		if rest == "" {
+			return m.LeafIndex(), m.Leaf()
+		}
+		return match(m.Next('/'), rest[1:], values)
+	}
+
+	if m := n.Search(seg); m.Exists() {
+		if leaf, ok := next(m); ok {
+			return leaf, true
+		}
+	}
+	if m := n.Next(paramLabel); m.Exists() && seg != "" {
+		*values = append(*values, seg)
+		if leaf, ok := next(m); ok {
+			return leaf, true
+		}
+		*values = (*values)[:len(*values)-1]
+	}
+	if m := n.Next(wildcardLabel); m.Leaf() {
+		*values = append(*values, path)
+		return m.LeafIndex(), true
+	}
+	return 0, false
+}
+
+// ServeHTTP dispatches req to the handler of its route, with the parameters in its context,
+// see ParamsFromContext. Requests matching a route for other methods only get a 405 Method Not Allowed.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, ps, ok := rt.Lookup(req.Method, req.URL.Path)
+	if ok {
+		if len(ps) > 0 {
+			req = req.WithContext(context.WithValue(req.Context(), paramsKey{}, ps))
+		}
+		h.ServeHTTP(w, req)
+		return
+	}
+
+	var allowed []string
+	for _, m := range rt.methods {
+		if _, _, ok := rt.Lookup(m, req.URL.Path); ok {
+			allowed = append(allowed, m)
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if rt.NotFound != nil {
+		rt.NotFound.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, name)
+			for _, p := range ParamsFromContext(r.Context()) {
+				fmt.Fprintf(w, " %s=%s", p.Key, p.Value)
+			}
+		})
+	}
+
+	rt, err := New([]Route{
+		{"GET", "/", handler("index")},
+		{"GET", "/users", handler("users")},
+		{"POST", "/users", handler("create")},
+		{"GET", "/users/me", handler("me")},
+		{"GET", "/users/:id", handler("user")},
+		{"GET", "/users/:id/files/*path", handler("file")},
+		{"DELETE", "/users/:id", handler("delete")},
+		{"GET", "/static/*file", handler("static")},
+		{"GET", "/:lang/docs", handler("docs")},
+	})
+	assert.NoError(t, err)
+
+	for _, c := range []struct{ method, path, body string }{
+		{"GET", "/", "index"},
+		{"GET", "/users", "users"},
+		{"POST", "/users", "create"},
+		{"GET", "/users/me", "me"},
+		{"GET", "/users/42", "user id=42"},
+		{"DELETE", "/users/42", "delete id=42"},
+		{"GET", "/users/42/files/a/b.txt", "file id=42 path=a/b.txt"},
+		{"GET", "/users/me/files/x", "file id=me path=x"},
+		{"GET", "/static/", "static file="},
+		{"GET", "/static/css/site.css", "static file=css/site.css"},
+		{"GET", "/en/docs", "docs lang=en"},
+		{"GET", "/users/docs", "user id=docs"}, // the static segment is preferred
+	} {
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, c.path)
+		assert.Equal(t, c.body, w.Body.String(), c.path)
+	}
+
+	for _, path := range []string{"/users/", "/users/42/files", "/nope", "/en/docs/x", "/users/\x01"} {
+		_, _, ok := rt.Lookup("GET", path)
+		assert.False(t, ok, path)
+	}
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("PUT", "/users/42", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "DELETE, GET", w.Header().Get("Allow"))
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/nope", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	rt.NotFound = handler("custom")
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/nope", nil))
+	assert.Equal(t, "custom", w.Body.String())
+
+	for _, routes := range [][]Route{
+		{{"GET", "users", handler("x")}},
+		{{"GET", "/files/*path/x", handler("x")}},
+		{{"GET", "/users/:", handler("x")}},
+		{{"", "/", handler("x")}},
+		{{"GET", "/", nil}},
+		{{"GET", "/users/:id", handler("x")}, {"GET", "/users/:name", handler("y")}},
+	} {
+		_, err := New(routes)
+		assert.Error(t, err, routes[0].Pattern)
+	}
+}
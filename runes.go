@@ -0,0 +1,71 @@
+package sutrie
+
+import "unicode/utf8"
+
+// RuneChild is a descendant of a node reached by the UTF-8 encoding of a rune, see Node.RuneChildren.
+type RuneChild struct {
+	Rune rune
+	Node Node
+}
+
+// NextRune returns the node reached from the current node by the UTF-8 encoding of r, which may be a null node.
+// With WithUnicodeCaseFolding, r is folded.
+func (n Node) NextRune(r rune) Node {
+	if r < utf8.RuneSelf {
+		return n.Next(byte(r))
+	}
+	if n.trie.fold == foldUnicode {
+		r = foldRune(r)
+	}
+
+	var buf [utf8.UTFMax]byte
+	size := utf8.EncodeRune(buf[:], r)
+	for i := 0; i < size && n.Exists(); i++ {
+		n = n.Next(buf[i])
+	}
+	return n
+}
+
+// SearchRunes is like Search but follows runes, it is NextRune for every rune of s.
+func (n Node) SearchRunes(s []rune) Node {
+	for i := 0; i < len(s) && n.Exists(); i++ {
+		n = n.NextRune(s[i])
+	}
+	return n
+}
+
+// RuneChildren appends the descendants of the current node one rune away to buf in rune order
+// and returns the extended buffer, so the trie can be walked character by character.
+// Bytes not forming valid UTF-8 yield utf8.RuneError and the node where they end,
+// as does a key ending within a rune.
+func (n Node) RuneChildren(buf []RuneChild) []RuneChild {
+	if !n.Exists() {
+		return buf
+	}
+
+	var seq [utf8.UTFMax]byte
+	return n.runeChildren(buf, seq[:0])
+}
+
+// runeChildren appends the descendants completing the runes starting with prefix.
+func (n Node) runeChildren(buf []RuneChild, prefix []byte) []RuneChild {
+	for k := n.firstChild; k < n.afterLastChild; k++ {
+		child := n.next(k)
+		seq := append(prefix, n.trie.label(k))
+
+		if !utf8.FullRune(seq) {
+			if child.leaf {
+				buf = append(buf, RuneChild{utf8.RuneError, child})
+			}
+			buf = child.runeChildren(buf, seq)
+			continue
+		}
+
+		r, size := utf8.DecodeRune(seq)
+		if size != len(seq) {
+			r = utf8.RuneError
+		}
+		buf = append(buf, RuneChild{r, child})
+	}
+	return buf
+}
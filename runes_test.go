@@ -0,0 +1,41 @@
+package sutrie
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunes(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"中文", "中国", "中国人", "日本", "a", "ab", "\xe4\xb8", "\xff"})
+	root := trie.Root()
+
+	zh := root.NextRune('中')
+	assert.True(t, zh.Exists())
+	assert.True(t, zh.NextRune('文').Leaf())
+	assert.True(t, root.SearchRunes([]rune("中国人")).Leaf())
+	assert.False(t, root.SearchRunes([]rune("中日")).Exists())
+	assert.True(t, root.NextRune('a').Leaf())
+
+	var runes []rune
+	for _, c := range zh.RuneChildren(nil) {
+		runes = append(runes, c.Rune)
+		assert.True(t, c.Node.Leaf())
+	}
+	assert.Equal(t, []rune("国文"), runes)
+
+	// "\xe4\xb8" is a key ending within 中, "\xff" is not UTF-8
+	runes = runes[:0]
+	var leaves []bool
+	for _, c := range root.RuneChildren(nil) {
+		runes = append(runes, c.Rune)
+		leaves = append(leaves, c.Node.Leaf())
+	}
+	assert.Equal(t, []rune{'a', utf8.RuneError, '中', '日', utf8.RuneError}, runes)
+	assert.Equal(t, []bool{true, true, false, false, true}, leaves)
+
+	folded := BuildSuccinctTrie([]string{"σοφια"}, WithUnicodeCaseFolding())
+	assert.True(t, folded.Root().SearchRunes([]rune("ΣΟΦΙΑ")).Leaf())
+	assert.Empty(t, Node{}.RuneChildren(nil))
+}
@@ -0,0 +1,92 @@
+package sutrie
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+// WithLabelRuns stores the labels of the children of a node which form contiguous runs of bytes, like '0'-'9'
+// and 'a'-'f', as their first ones: the labels continuing a run are dropped and a bitmap marks their nodes,
+// so numeric and hex keys take about 2 bits per node for their labels instead of 8. The label of a node
+// is read with a rank, and a select if it is in a run, the child of a run is still computed arithmetically.
+// It cannot be combined with WithPackedLabels.
+func WithLabelRuns() Option {
+	return func(o *buildOptions) error {
+		o.labelRuns = true
+		return nil
+	}
+}
+
+// encodeRuns drops from nodes the label of every node which is the label of its previous sibling plus one,
+// marking the nodes dropped in runs.
+func (t *SuccinctTrie) encodeRuns() {
+	n := len(t.nodes)
+	labels := make([]byte, 0, n)
+	labels = append(labels, t.nodes[0])
+	t.runs.Reset(n)
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		for k := firstChild; k < afterLastChild; k++ {
+			if k > firstChild && t.nodes[k] == t.nodes[k-1]+1 {
+				t.runs.Set(int(k), true)
+			} else {
+				labels = append(labels, t.nodes[k])
+			}
+		}
+	})
+	t.nodes, t.count = string(labels), n
+	t.runs.Init()
+}
+
+// setRuns sets the bitmap of the nodes dropped from nodes, whose words are read from a serialized trie.
+func (t *SuccinctTrie) setRuns(words []uint64) {
+	t.runs = bitvec.Vector{}
+	if len(words) == 0 {
+		return
+	}
+	ones := 0
+	for _, word := range words {
+		ones += bits.OnesCount64(word)
+	}
+	t.runs = *bitvec.WrapWords(words, len(words)<<6)
+	// built now rather than with the other indexes, as label needs it and Validate reads the labels first
+	t.runs.Init()
+	t.count = len(t.nodes) + ones
+}
+
+// runLabel returns the label of node k of a trie built WithLabelRuns.
+func (t *SuccinctTrie) runLabel(k int32) byte {
+	kept := t.runs.Rank0(int(k))
+	if !t.runs.Get(int(k)) {
+		return t.nodes[kept]
+	}
+	// the label of the first node of the run is the last one kept before k
+	first := t.runs.Select0(kept - 1)
+	return t.nodes[kept-1] + byte(int(k)-first)
+}
+
+// searchRuns returns the index of the node labeled b among the children [l, r) of a node of a trie built
+// WithLabelRuns, whose labels kept in nodes, the first ones of their runs, are [i, j), or -1.
+func (t *SuccinctTrie) searchRuns(l, r int32, i, j int, b byte) int32 {
+	if j-i == int(r-l) {
+		if k := strings.IndexByte(t.nodes[i:j], b); k >= 0 {
+			return l + int32(k)
+		}
+		return -1
+	}
+
+	// the last run starting at or before b, which must be long enough
+	s := j - i - 1
+	for s >= 0 && t.nodes[i+s] > b {
+		s--
+	}
+	if s < 0 {
+		return -1
+	}
+	k := int32(t.runs.Select0(i+s)) + int32(b-t.nodes[i+s])
+	if k >= r || t.runs.Rank0(int(k)+1) != i+s+1 {
+		return -1
+	}
+	return k
+}
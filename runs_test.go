@@ -0,0 +1,99 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runKeys returns numeric and hex keys of n numbers with a few holes, whose fanouts are mostly contiguous.
+func runKeys(n int) []string {
+	rnd := rand.New(rand.NewSource(1))
+	var dict []string
+	for i := 0; i < n; i++ {
+		if rnd.Intn(50) > 0 {
+			dict = append(dict, fmt.Sprintf("%05d", i), fmt.Sprintf("%05x", 0x10000+i))
+		}
+	}
+	return dict
+}
+
+func TestLabelRunsEncoding(t *testing.T) {
+	dict := append(runKeys(100000), "a", "b", "c", "x", "\xfe", "\xff")
+
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithLabelRuns())
+
+	assert.Equal(t, plain.numNodes(), trie.numNodes())
+	assert.Less(t, len(trie.nodes), plain.numNodes()/4)
+	assert.Equal(t, plain.labels(0, int32(plain.numNodes())), trie.labels(0, int32(trie.numNodes())))
+	assert.True(t, plain.Equal(trie))
+	assert.Equal(t, plain.Hash(), trie.Hash())
+	assert.Less(t, trie.MemStats().Labels, plain.MemStats().Labels/3)
+	assert.Less(t, trie.SizeInBytes(), plain.SizeInBytes()*2/3)
+	assert.NoError(t, trie.Validate())
+
+	check := func(trie *SuccinctTrie) {
+		assert.Equal(t, plain.Keys(), trie.Keys())
+		for _, key := range dict[:1000] {
+			n := trie.Root().Search(key)
+			assert.True(t, n.Leaf(), key)
+			assert.Equal(t, key, n.Key())
+			assert.Equal(t, plain.Root().Search(key[:2]).Children(), trie.Root().Search(key[:2]).Children())
+			assert.Equal(t, len(key), trie.Root().SearchPrefix(key+"/x"))
+			assert.False(t, trie.Root().Search(key[:2]+"_").Exists())
+		}
+		for _, key := range []string{"\xfe", "\xff", "x", "0123456789"} {
+			assert.Equal(t, plain.Root().Search(key).Leaf(), trie.Root().Search(key).Leaf(), key)
+		}
+	}
+	check(trie)
+	check(trie.Clone())
+
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		assert.NoError(t, loaded.Validate())
+		assert.Equal(t, trie.nodes, loaded.nodes)
+		check(&loaded)
+	}
+
+	// other options see the labels through the same accessors
+	folded := BuildSuccinctTrie([]string{"Hat", "IS", "it", "iu"}, WithCaseFolding(), WithLabelRuns(), WithDispatchTable(2))
+	assert.Equal(t, 3, folded.runs.Ones()) // i after h, t and u after s
+	assert.True(t, folded.Root().Search("HAT").Leaf())
+	assert.True(t, folded.Root().Search("iU").Leaf())
+	assert.Equal(t, []string{"hat", "is", "it", "iu"}, folded.Keys())
+	remapped := BuildSuccinctTrie(append([]string(nil), dict...), WithLabelRuns(), WithAlphabetRemap())
+	check(remapped)
+
+	corrupt := trie.Clone()
+	corrupt.runs.Set(2, false)
+	corrupt.runs.Init()
+	assert.ErrorIs(t, corrupt.Validate(), ErrInvalidTrie)
+
+	_, err := Build([]string{"a"}, WithLabelRuns(), WithPackedLabels())
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	assert.Equal(t, 0, BuildSuccinctTrie(nil, WithLabelRuns()).Size())
+}
+
+func BenchmarkLabelRuns(b *testing.B) {
+	dict := runKeys(100000)
+	for _, opts := range [][]Option{nil, {WithLabelRuns()}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		b.Run(fmt.Sprint("labels-", trie.MemStats().Labels), func(b *testing.B) {
+			root := trie.Root()
+			for i := 0; i < b.N; i++ {
+				root.Search(dict[i%len(dict)])
+			}
+		})
+	}
+}
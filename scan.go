@@ -0,0 +1,32 @@
+package sutrie
+
+// Match is an occurrence of a key in a text, from byte offset Start to End,
+// Leaf being the LeafIndex of the key.
+type Match struct {
+	Start, End int
+	Leaf       int
+}
+
+// ScanText returns every occurrence of every key of the trie in text, overlapping ones included,
+// ordered by start then end. Keys are searched as stored, by descending the trie from every byte of text,
+// in time proportional to the length of text times the length of the longest match.
+func (t *SuccinctTrie) ScanText(text []byte) []Match {
+	return t.AppendMatches(nil, text)
+}
+
+// AppendMatches is like ScanText but appends the matches to dst and returns the extended slice.
+func (t *SuccinctTrie) AppendMatches(dst []Match, text []byte) []Match {
+	root := t.Root()
+	for i := range text {
+		n := root
+		for j := i; j < len(text); j++ {
+			if n = n.Next(text[j]); !n.Exists() {
+				break
+			}
+			if n.leaf {
+				dst = append(dst, Match{i, j + 1, n.LeafIndex()})
+			}
+		}
+	}
+	return dst
+}
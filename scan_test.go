@@ -0,0 +1,23 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanText(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"he", "she", "his", "hers"})
+	text := []byte("ushers and his")
+
+	var got []string
+	for _, m := range trie.ScanText(text) {
+		assert.Equal(t, string(text[m.Start:m.End]), trie.KeyAt(m.Leaf))
+		got = append(got, string(text[m.Start:m.End]))
+	}
+	assert.Equal(t, []string{"she", "he", "hers", "his"}, got)
+
+	assert.Empty(t, trie.ScanText(nil))
+	assert.Empty(t, BuildSuccinctTrie(nil).ScanText(text))
+	assert.Len(t, trie.AppendMatches(make([]Match, 1), []byte("he")), 2)
+}
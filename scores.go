@@ -0,0 +1,104 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+var errInvalidScores = errors.New("sutrie: invalid scores")
+
+// WithScores maps dict[i] to scores[i], a weight like a reputation or a priority, returned by Node.Score and
+// SearchPrefixScore. Scores take 64 bits per key, see WithFloat32Scores. Build fails as with WithUint8Values.
+func WithScores(scores []float64) Option {
+	return func(o *buildOptions) error {
+		o.scores, o.scoreBits = len(scores), 64
+		o.score = func(i int) uint64 { return math.Float64bits(scores[i]) }
+		return nil
+	}
+}
+
+// WithFloat32Scores is like WithScores with float32 scores, which take 32 bits per key.
+func WithFloat32Scores(scores []float32) Option {
+	return func(o *buildOptions) error {
+		o.scores, o.scoreBits = len(scores), 32
+		o.score = func(i int) uint64 { return uint64(math.Float32bits(scores[i])) }
+		return nil
+	}
+}
+
+// setScores stores the scores of the keys of dict by leaf index.
+func (t *SuccinctTrie) setScores(dict []string, o *buildOptions) error {
+	scores, err := t.leafValues(dict, o.score)
+	if err != nil {
+		return err
+	}
+	t.scores = packInts(scores, o.scoreBits)
+	return nil
+}
+
+// score returns the score of the leaf at index.
+func (t *SuccinctTrie) score(index int32) float64 {
+	bits := t.scores.get(t.leavesBefore(index))
+	if t.scores.width == 32 {
+		return float64(math.Float32frombits(uint32(bits)))
+	}
+	return math.Float64frombits(bits)
+}
+
+// Score returns the score of the key of the current node, see WithScores.
+// ok is false if the node is not a leaf or the trie has no scores.
+func (n Node) Score() (score float64, ok bool) {
+	if !n.leaf || n.trie.scores.width == 0 {
+		return 0, false
+	}
+	return n.trie.score(n.index), true
+}
+
+// SearchPrefixScore is like SearchPrefix, but also returns the score of the longest key of the trie which
+// is a prefix of key, so that the weight of the match can decide the action. ok is false if there is no
+// such key or the trie has no scores.
+func (t *SuccinctTrie) SearchPrefixScore(key string) (lastUnmatch int, score float64, ok bool) {
+	if t.rewritesQueries() {
+		q, buf := query(t, key)
+		defer normBuffers.Put(buf)
+		return searchPrefixScore(t.Root(), q)
+	}
+	return searchPrefixScore(t.Root(), key)
+}
+
+func searchPrefixScore[K string | []byte](n Node, key K) (lastUnmatch int, score float64, ok bool) {
+	if n.trie.scores.width == 0 {
+		return searchPrefix(n, key), 0, false
+	}
+
+	match := n
+	for i := 0; i < len(key); i++ {
+		if key[i] >= utf8.RuneSelf && n.trie.fold == foldUnicode {
+			var size int
+			n, size = nextFolded(n, key, i)
+			i += size - 1
+		} else {
+			n = n.Next(key[i])
+		}
+		if !n.Exists() {
+			break
+		}
+		if n.leaf {
+			lastUnmatch, match = i+1, n
+		}
+	}
+	if !match.leaf {
+		return 0, 0, false
+	}
+	return lastUnmatch, match.trie.score(match.index), true
+}
+
+// checkScores returns an error if the scores read for a trie of size keys are not valid.
+func checkScores(scores packedInts, size int) error {
+	if scores.width != 0 && scores.width != 32 && scores.width != 64 || len(scores.words)*64 < size*int(scores.width) {
+		return fmt.Errorf("%w: %d-bit scores", errInvalidScores, scores.width)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScores(t *testing.T) {
+	dict := []string{"10.", "10.1.", "10.1.2.", "192.168.", ""}
+	scores := []float64{0.5, -3, 0.125, 99, 1e-300}
+	trie, err := Build(append([]string(nil), dict...), WithScores(scores))
+	assert.NoError(t, err)
+	assert.Equal(t, 40, trie.MemStats().Scores)
+
+	check := func(trie *SuccinctTrie) {
+		for i, key := range dict {
+			score, ok := trie.Root().Search(key).Score()
+			assert.True(t, ok, key)
+			assert.Equal(t, scores[i], score, key)
+		}
+		_, ok := trie.Root().Search("10").Score()
+		assert.False(t, ok)
+
+		for key, want := range map[string]struct {
+			lastUnmatch int
+			score       float64
+		}{
+			"10.1.2.3": {7, 0.125},
+			"10.1.9.9": {5, -3},
+			"10.9":     {3, 0.5},
+			"172.16.":  {0, 1e-300},
+		} {
+			lastUnmatch, score, ok := trie.SearchPrefixScore(key)
+			assert.True(t, ok, key)
+			assert.Equal(t, want.lastUnmatch, lastUnmatch, key)
+			assert.Equal(t, want.score, score, key)
+			assert.Equal(t, trie.SearchPrefix(key), lastUnmatch, key)
+		}
+	}
+	check(trie)
+	check(trie.Clone())
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		check(&loaded)
+	}
+
+	small := BuildSuccinctTrie([]string{"a", "ab"}, WithFloat32Scores([]float32{1.5, -0.25}))
+	assert.Equal(t, 8, small.MemStats().Scores)
+	lastUnmatch, score, ok := small.SearchPrefixScore("abc")
+	assert.Equal(t, 2, lastUnmatch)
+	assert.Equal(t, -0.25, score)
+	assert.True(t, ok)
+	_, _, ok = small.SearchPrefixScore("b")
+	assert.False(t, ok)
+
+	// the query steps as SearchPrefix does on folded tries
+	folded := BuildSuccinctTrie([]string{"éa", "b"}, WithUnicodeCaseFolding(), WithScores([]float64{2, 3}))
+	for key, want := range map[string]int{"ÉAx": 3, "éAx": 3, "Éb": 0, "Bc": 1} {
+		lastUnmatch, _, ok := folded.SearchPrefixScore(key)
+		assert.Equal(t, want, lastUnmatch, key)
+		assert.Equal(t, folded.SearchPrefix(key), lastUnmatch, key)
+		assert.Equal(t, want > 0, ok, key)
+	}
+	_, score, _ = folded.SearchPrefixScore("ÉA")
+	assert.Equal(t, 2.0, score)
+	asciiFolded := BuildSuccinctTrie([]string{"ab"}, WithCaseFolding(), WithScores([]float64{4}))
+	_, score, ok = asciiFolded.SearchPrefixScore("ABC")
+	assert.True(t, ok)
+	assert.Equal(t, 4.0, score)
+
+	// without scores, the match is still returned
+	lastUnmatch, _, ok = BuildSuccinctTrie([]string{"a"}).SearchPrefixScore("ab")
+	assert.Equal(t, 1, lastUnmatch)
+	assert.False(t, ok)
+
+	_, err = Build([]string{"a", "b"}, WithScores([]float64{1}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, err = Build([]string{"a", "a"}, WithFloat32Scores([]float32{1, 2}))
+	assert.Error(t, err)
+	_, err = Build([]string{}, WithScores(nil))
+	assert.NoError(t, err)
+}
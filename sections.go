@@ -0,0 +1,264 @@
+package sutrie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Section identifies a section of a trie written by MarshalSections.
+type Section uint8
+
+const (
+	sectionEnd Section = iota
+
+	// SectionHeader holds the number of keys and the options of the trie
+	SectionHeader
+	// SectionBitmap is the LOUDS bitmap of the tree shape, as little-endian 64-bit words
+	SectionBitmap
+	// SectionLeaves is the bitmap of the leaves, as little-endian 64-bit words
+	SectionLeaves
+	// SectionEliasFanoLeaves replaces SectionLeaves in tries built WithEliasFanoLeaves
+	SectionEliasFanoLeaves
+	// SectionLabels is the label of every node in level order, the root having a dummy one
+	SectionLabels
+	// SectionSuffixes is the suffix hashes of tries built WithSuffixTruncation, as little-endian 64-bit words
+	SectionSuffixes
+	// SectionDispatch is the number of levels of the dispatch table of tries built WithDispatchTable, as a byte
+	SectionDispatch
+	// SectionAlphabet is the sorted bytes of the labels of tries built WithAlphabetRemap
+	SectionAlphabet
+	// SectionPackedLabels replaces SectionLabels in tries built WithAlphabetRemap, the labels being bit-packed codes
+	SectionPackedLabels
+	// SectionPackLabels is empty, it marks tries built WithPackedLabels, whose labels stay packed once read
+	SectionPackLabels
+	// SectionCounts is the bit width of the occurrence counts of tries built WithMultiplicities, as a byte,
+	// followed by the packed counts as little-endian 64-bit words
+	SectionCounts
+	// SectionValues is the bit width of the values of tries built WithUint8Values, as a byte,
+	// followed by the packed values as little-endian 64-bit words
+	SectionValues
+	// SectionScores is the bit width of the scores of tries built WithScores or WithFloat32Scores, as a byte,
+	// followed by the packed float bits as little-endian 64-bit words
+	SectionScores
+	// SectionLabelRuns is the bitmap of the nodes whose label is dropped from the labels of tries built WithLabelRuns,
+	// as little-endian 64-bit words
+	SectionLabelRuns
+)
+
+// ErrInvalidSections is returned when the input is not a trie written by MarshalSections.
+var ErrInvalidSections = errors.New("sutrie: invalid sectioned trie")
+
+type sectionHeader struct {
+	Size                uint64
+	Reversed, Truncated bool
+	HashBits, Fold      uint8
+}
+
+// sectionChunk is the size of the buffers through which sections are written and read.
+const sectionChunk = 64 << 10
+
+// MarshalSections writes the trie to w as a sequence of sections preceded by their length, written in chunks
+// rather than encoded in memory first, so that very large tries are written and read with bounded memory
+// on top of their own. Unmarshal reads it, and ReadSections reads only some of its sections.
+func (t *SuccinctTrie) MarshalSections(w io.Writer) error {
+	t.Warmup()
+	bw := bufio.NewWriterSize(w, sectionChunk)
+	bw.WriteString(trieMagic)
+	bw.WriteByte(formatSections)
+
+	h := sectionHeader{uint64(t.size), t.reversed, t.truncated, uint8(t.hashBits), uint8(t.fold)}
+	writeSectionHeader(bw, SectionHeader, int64(binary.Size(h)))
+	binary.Write(bw, binary.LittleEndian, h)
+
+	writeWords(bw, SectionBitmap, t.bitmap.Words())
+	if t.sparseLeaves != nil {
+		ef, err := t.sparseLeaves.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		writeSectionHeader(bw, SectionEliasFanoLeaves, int64(len(ef)))
+		bw.Write(ef)
+	} else {
+		writeWords(bw, SectionLeaves, t.leaves.Words())
+	}
+
+	if t.alphabet != nil {
+		writeSectionHeader(bw, SectionAlphabet, int64(len(t.alphabet)))
+		bw.Write(t.alphabet)
+		packed := t.serializedLabels()
+		writeSectionHeader(bw, SectionPackedLabels, int64(len(packed)))
+		bw.Write(packed)
+		if t.packed != nil {
+			writeSectionHeader(bw, SectionPackLabels, 0)
+		}
+	} else {
+		writeSectionHeader(bw, SectionLabels, int64(len(t.nodes)))
+		bw.WriteString(t.nodes)
+	}
+	if t.runs.Len() > 0 {
+		writeWords(bw, SectionLabelRuns, t.runs.Words())
+	}
+	if t.truncated {
+		writeWords(bw, SectionSuffixes, t.suffixes)
+	}
+	if t.dispatchLevels > 0 {
+		writeSectionHeader(bw, SectionDispatch, 1)
+		bw.WriteByte(uint8(t.dispatchLevels))
+	}
+	if t.counts.width > 0 {
+		writePackedInts(bw, SectionCounts, t.counts)
+	}
+	if t.hasValues {
+		writePackedInts(bw, SectionValues, t.values)
+	}
+	if t.scores.width > 0 {
+		writePackedInts(bw, SectionScores, t.scores)
+	}
+
+	writeSectionHeader(bw, sectionEnd, 0)
+	return bw.Flush() // the errors of bw are sticky
+}
+
+func writeSectionHeader(w *bufio.Writer, s Section, length int64) {
+	var h [9]byte
+	h[0] = byte(s)
+	binary.LittleEndian.PutUint64(h[1:], uint64(length))
+	w.Write(h[:])
+}
+
+// writePackedInts writes the width of p as a byte followed by its words.
+func writePackedInts(w *bufio.Writer, s Section, p packedInts) {
+	writeSectionHeader(w, s, 1+8*int64(len(p.words)))
+	w.WriteByte(uint8(p.width))
+	var b [8]byte
+	for _, word := range p.words {
+		binary.LittleEndian.PutUint64(b[:], word)
+		w.Write(b[:])
+	}
+}
+
+func writeWords(w *bufio.Writer, s Section, words []uint64) {
+	writeSectionHeader(w, s, 8*int64(len(words)))
+	var b [8]byte
+	for _, word := range words {
+		binary.LittleEndian.PutUint64(b[:], word)
+		w.Write(b[:])
+	}
+}
+
+// ReadSections reads a trie written by MarshalSections from r, calling fn with every section, its length
+// in bytes and a reader of its content. The part of a section fn does not read is skipped, so that
+// for example the leaves can be left out of an analysis of the shape of the tree.
+// If fn returns an error, ReadSections stops and returns it.
+func ReadSections(r io.Reader, fn func(s Section, length int64, r io.Reader) error) error {
+	magic := make([]byte, len(trieMagic)+1)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != trieMagic+string(rune(formatSections)) {
+		return ErrInvalidSections
+	}
+	return forEachSection(r, fn)
+}
+
+// forEachSection is ReadSections once the magic is read.
+func forEachSection(r io.Reader, fn func(s Section, length int64, r io.Reader) error) error {
+	var h [9]byte
+	for {
+		if _, err := io.ReadFull(r, h[:]); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSections, err)
+		}
+		s, length := Section(h[0]), int64(binary.LittleEndian.Uint64(h[1:]))
+		if s == sectionEnd {
+			return nil
+		}
+		if length < 0 {
+			return ErrInvalidSections
+		}
+
+		lr := &io.LimitedReader{R: r, N: length}
+		if err := fn(s, length, lr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(io.Discard, lr); err != nil {
+			return err
+		}
+		if lr.N > 0 {
+			return fmt.Errorf("%w: truncated section %d", ErrInvalidSections, s)
+		}
+	}
+}
+
+// readSections decodes the sections read from r into w. Sections it does not know are skipped.
+func readSections(r io.Reader, w *wrapSuccinctTrie) error {
+	return forEachSection(r, func(s Section, length int64, r io.Reader) error {
+		var err error
+		switch s {
+		case SectionHeader:
+			var h sectionHeader
+			if err = binary.Read(r, binary.LittleEndian, &h); err == nil {
+				w.Size, w.Reversed, w.Truncated, w.HashBits, w.Fold = int(h.Size), h.Reversed, h.Truncated, int(h.HashBits), h.Fold
+			}
+		case SectionBitmap:
+			w.BitmapBits, err = readWords(r, length)
+		case SectionLeaves:
+			w.LeavesBits, err = readWords(r, length)
+		case SectionEliasFanoLeaves:
+			w.EliasFanoLeaves, err = io.ReadAll(r)
+		case SectionLabels:
+			var b strings.Builder
+			b.Grow(int(min(length, sectionChunk<<10)))
+			_, err = io.Copy(&b, r)
+			w.Nodes = b.String()
+		case SectionSuffixes:
+			w.Suffixes, err = readWords(r, length)
+		case SectionDispatch:
+			err = binary.Read(r, binary.LittleEndian, &w.DispatchLevels)
+		case SectionAlphabet:
+			w.Alphabet, err = io.ReadAll(r)
+		case SectionPackedLabels:
+			w.PackedNodes, err = io.ReadAll(r)
+		case SectionPackLabels:
+			w.PackLabels = true
+		case SectionCounts:
+			if err = binary.Read(r, binary.LittleEndian, &w.CountsWidth); err == nil {
+				w.Counts, err = readWords(r, length-1)
+			}
+		case SectionValues:
+			w.HasValues = true
+			if err = binary.Read(r, binary.LittleEndian, &w.ValuesWidth); err == nil {
+				w.Values, err = readWords(r, length-1)
+			}
+		case SectionScores:
+			if err = binary.Read(r, binary.LittleEndian, &w.ScoreBits); err == nil {
+				w.Scores, err = readWords(r, length-1)
+			}
+		case SectionLabelRuns:
+			w.LabelRuns, err = readWords(r, length)
+		}
+		return err
+	})
+}
+
+// readWords reads the little-endian 64-bit words of a section of length bytes from r.
+func readWords(r io.Reader, length int64) ([]uint64, error) {
+	if length%8 != 0 {
+		return nil, ErrInvalidSections
+	}
+
+	// not trusting length for the allocation, so that a corrupt one fails with io.ErrUnexpectedEOF
+	words := make([]uint64, 0, min(length/8, sectionChunk<<7))
+	buf := make([]byte, sectionChunk)
+	for remaining := length; remaining > 0; {
+		chunk := buf[:min(remaining, sectionChunk)]
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(chunk); i += 8 {
+			words = append(words, binary.LittleEndian.Uint64(chunk[i:]))
+		}
+		remaining -= int64(len(chunk))
+	}
+	return words, nil
+}
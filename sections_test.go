@@ -0,0 +1,75 @@
+package sutrie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalSections(t *testing.T) {
+	var dict []string
+	for i := 0; i < 20000; i++ {
+		dict = append(dict, fmt.Sprintf("Key%d", i*13))
+	}
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithEliasFanoLeaves()},
+		{WithReversedKeys(), WithCaseFolding()},
+		{WithSuffixTruncation(8)},
+	} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+
+		var buf bytes.Buffer
+		assert.NoError(t, trie.MarshalSections(&buf))
+		buf.WriteString("trailer")
+
+		// Unmarshal detects the layout, and reads no further than the trie
+		r := io.MultiReader(&buf)
+		var loaded SuccinctTrie
+		_, err := loaded.ReadFrom(r)
+		assert.NoError(t, err)
+		assert.True(t, trie.Equal(&loaded))
+		assert.Equal(t, trie.Truncated(), loaded.Truncated())
+		assert.Equal(t, trie.FoldsCase(), loaded.FoldsCase())
+		rest, _ := io.ReadAll(r)
+		assert.Equal(t, "trailer", string(rest))
+	}
+}
+
+func TestReadSections(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "中文"})
+	var buf bytes.Buffer
+	assert.NoError(t, trie.MarshalSections(&buf))
+	data := buf.Bytes()
+
+	var sections []Section
+	var labels []byte
+	err := ReadSections(bytes.NewReader(data), func(s Section, length int64, r io.Reader) error {
+		sections = append(sections, s)
+		if s == SectionLabels {
+			labels, _ = io.ReadAll(r)
+		}
+		return nil // the other sections are skipped
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Section{SectionHeader, SectionBitmap, SectionLeaves, SectionLabels}, sections)
+	assert.Equal(t, trie.nodes, string(labels))
+
+	stop := errors.New("stop")
+	err = ReadSections(bytes.NewReader(data), func(s Section, length int64, r io.Reader) error { return stop })
+	assert.Equal(t, stop, err)
+
+	noop := func(Section, int64, io.Reader) error { return nil }
+	assert.ErrorIs(t, ReadSections(bytes.NewReader(data[:len(data)-20]), noop), ErrInvalidSections)
+	assert.ErrorIs(t, ReadSections(bytes.NewReader([]byte("garbage")), noop), ErrInvalidSections)
+	assert.Error(t, new(SuccinctTrie).Unmarshal(bytes.NewReader(data[:len(data)-20])))
+
+	var plain bytes.Buffer
+	assert.NoError(t, trie.Marshal(&plain))
+	assert.ErrorIs(t, ReadSections(&plain, noop), ErrInvalidSections)
+}
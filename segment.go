@@ -0,0 +1,214 @@
+package sutrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+const segmentedMagic = "SUTRIESG"
+
+// segmentedEmpty is set in the number of segments if the empty key is in the trie,
+// whose fold mode is in the bits from segmentedFold.
+const (
+	segmentedEmpty = 1 << 15
+	segmentedFold  = 12
+)
+
+// ErrInvalidSegmented is returned when the input is not a segmented trie.
+var ErrInvalidSegmented = errors.New("sutrie: invalid segmented trie")
+
+var errSegmentedOptions = errors.New("sutrie: cannot segment tries with truncated keys, counts, values or scores")
+
+type segmentEntry struct {
+	Label  uint8
+	Leaf   uint8
+	Offset uint64
+	Length uint64
+}
+
+// MarshalSegmented writes the trie as independently decodable segments, one per child of the root,
+// preceded by an index. See OpenSegmented for loading the segments lazily. The segments are built with the options
+// of the trie, tries with truncated keys or data by key, see WithMultiplicities, WithUint8Values and WithScores,
+// cannot be segmented.
+func (t *SuccinctTrie) MarshalSegmented(w io.Writer) error {
+	if t.truncated || t.counts.width > 0 || t.hasValues || t.scores.width > 0 {
+		return errSegmentedOptions
+	}
+
+	root := t.Root()
+	entries := make([]segmentEntry, root.Size())
+	segments := make([][]byte, root.Size())
+
+	o := t.encoding()
+	offset := uint64(len(segmentedMagic) + 2 + binary.Size(entries))
+	for i := range entries {
+		k := root.firstChild + int32(i)
+		child := root.next(k)
+
+		seg, err := child.Subtrie()
+		if err != nil {
+			return err
+		}
+		seg.fold = t.fold
+		seg.encode(&o)
+		var buf bytes.Buffer
+		if err := seg.Marshal(&buf); err != nil {
+			return err
+		}
+
+		entries[i] = segmentEntry{Label: t.label(k), Offset: offset, Length: uint64(buf.Len())}
+		if child.Leaf() {
+			entries[i].Leaf = 1
+		}
+		segments[i] = buf.Bytes()
+		offset += uint64(buf.Len())
+	}
+
+	if _, err := io.WriteString(w, segmentedMagic); err != nil {
+		return err
+	}
+	n := uint16(len(entries)) | uint16(t.fold)<<segmentedFold
+	if root.leaf {
+		n |= segmentedEmpty
+	}
+	if err := binary.Write(w, binary.LittleEndian, n); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, entries); err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if _, err := w.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SegmentedTrie is a trie written by MarshalSegmented whose segments are decoded on first access.
+// Only the segments of the first bytes actually queried are ever loaded. It is safe for concurrent use.
+type SegmentedTrie struct {
+	r        io.ReaderAt
+	entries  []segmentEntry
+	index    [256]int16
+	segments []segment
+	empty    bool
+	fold     foldMode
+}
+
+type segment struct {
+	once sync.Once
+	trie *SuccinctTrie
+	err  error
+}
+
+// OpenSegmented reads the index of a segmented trie from r, the segments are read from r when needed
+// so r must stay valid as long as the SegmentedTrie is used.
+func OpenSegmented(r io.ReaderAt) (*SegmentedTrie, error) {
+	sr := io.NewSectionReader(r, 0, 1<<63-1)
+
+	magic := make([]byte, len(segmentedMagic))
+	if _, err := io.ReadFull(sr, magic); err != nil || string(magic) != segmentedMagic {
+		return nil, ErrInvalidSegmented
+	}
+
+	var n uint16
+	if err := binary.Read(sr, binary.LittleEndian, &n); err != nil {
+		return nil, ErrInvalidSegmented
+	}
+
+	empty, fold := n&segmentedEmpty != 0, foldMode(n>>segmentedFold&3)
+	n &= 1<<segmentedFold - 1
+	s := &SegmentedTrie{r: r, entries: make([]segmentEntry, n), segments: make([]segment, n), empty: empty, fold: fold}
+	if err := binary.Read(sr, binary.LittleEndian, s.entries); err != nil {
+		return nil, ErrInvalidSegmented
+	}
+
+	for i := range s.index {
+		s.index[i] = -1
+	}
+	for i, e := range s.entries {
+		s.index[e.Label] = int16(i)
+	}
+	return s, nil
+}
+
+// Segment returns the trie of the keys starting with b, with b stripped, loading it if necessary.
+// It returns nil if no key starts with b.
+func (s *SegmentedTrie) Segment(b byte) (*SuccinctTrie, error) {
+	i := s.index[b]
+	if i < 0 {
+		return nil, nil
+	}
+
+	seg, e := &s.segments[i], s.entries[i]
+	seg.once.Do(func() {
+		trie := &SuccinctTrie{}
+		if err := trie.Unmarshal(io.NewSectionReader(s.r, int64(e.Offset), int64(e.Length))); err != nil {
+			seg.err = err
+			return
+		}
+		seg.trie = trie
+	})
+	return seg.trie, seg.err
+}
+
+// Contains reports whether key is in the trie, loading the segment of its first byte if necessary.
+func (s *SegmentedTrie) Contains(key string) (bool, error) {
+	if key == "" {
+		return s.empty, nil
+	}
+
+	first, size := s.first(key)
+	trie, err := s.Segment(first[0])
+	if trie == nil {
+		return false, err
+	}
+	rest := first[1:] + key[size:]
+	if rest == "" {
+		return s.entries[s.index[first[0]]].Leaf == 1, nil
+	}
+	return trie.Root().Search(rest).Leaf(), nil
+}
+
+// SearchPrefix behaves like Node.SearchPrefix from root, loading the segment of the first byte of key if necessary.
+func (s *SegmentedTrie) SearchPrefix(key string) (lastUnmatch int, err error) {
+	if key == "" {
+		return 0, nil
+	}
+
+	first, size := s.first(key)
+	trie, err := s.Segment(first[0])
+	if trie == nil {
+		return 0, err
+	}
+	// the lengths in the key with its first rune folded, a key cannot end within it
+	if s.entries[s.index[first[0]]].Leaf == 1 {
+		lastUnmatch = 1
+	}
+	if n := trie.Root().SearchPrefix(first[1:] + key[size:]); n > 0 {
+		lastUnmatch = n + 1
+	}
+	if lastUnmatch < len(first) {
+		return 0, nil
+	}
+	return lastUnmatch - len(first) + size, nil
+}
+
+// first returns the first byte of key folded like the keys of the trie, or its first rune if it is folded
+// with Unicode folding, and its size in key.
+func (s *SegmentedTrie) first(key string) (folded string, size int) {
+	if s.fold == foldUnicode && key[0] >= utf8.RuneSelf {
+		if r, size := utf8.DecodeRuneInString(key); r != utf8.RuneError || size > 1 {
+			return string(foldRune(r)), size
+		}
+	}
+	if l := lowerASCII(key[0]); s.fold != foldNone && l != key[0] {
+		return string(rune(l)), 1
+	}
+	return key[:1], 1
+}
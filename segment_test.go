@@ -0,0 +1,116 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmented(t *testing.T) {
+	dict := []string{"hat", "is", "it", "a", "abc", "中文"}
+
+	var buf bytes.Buffer
+	assert.NoError(t, BuildSuccinctTrie(dict).MarshalSegmented(&buf))
+
+	s, err := OpenSegmented(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+
+	for _, key := range dict {
+		ok, err := s.Contains(key)
+		assert.NoError(t, err)
+		assert.True(t, ok, key)
+	}
+	for _, key := range []string{"", "h", "ab", "x", "中"} {
+		ok, err := s.Contains(key)
+		assert.NoError(t, err)
+		assert.False(t, ok, key)
+	}
+
+	for key, want := range map[string]int{"hatt": 3, "iss": 2, "ab": 1, "abcd": 3, "ti": 0, "": 0} {
+		n, err := s.SearchPrefix(key)
+		assert.NoError(t, err)
+		assert.Equal(t, want, n, key)
+	}
+
+	loaded := 0
+	for i := range s.segments {
+		if s.segments[i].trie != nil {
+			loaded++
+		}
+	}
+	assert.Equal(t, 4, loaded) // 'a', 'h', 'i' and the first byte of "中"
+
+	s, err = OpenSegmented(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	_, _ = s.Contains("hat")
+	assert.NotNil(t, s.segments[s.index['h']].trie)
+	assert.Nil(t, s.segments[s.index['i']].trie)
+
+	// the empty key is flagged in the index
+	buf.Reset()
+	assert.NoError(t, BuildSuccinctTrie(append(dict, "")).MarshalSegmented(&buf))
+	s, err = OpenSegmented(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	ok, err := s.Contains("")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = s.Contains("hat")
+	assert.True(t, ok)
+
+	_, err = OpenSegmented(bytes.NewReader([]byte("garbage")))
+	assert.ErrorIs(t, err, ErrInvalidSegmented)
+}
+
+func TestSegmentedOptions(t *testing.T) {
+	open := func(trie *SuccinctTrie) *SegmentedTrie {
+		var buf bytes.Buffer
+		assert.NoError(t, trie.MarshalSegmented(&buf))
+		s, err := OpenSegmented(bytes.NewReader(buf.Bytes()))
+		assert.NoError(t, err)
+		return s
+	}
+
+	reversed := open(BuildSuccinctTrie([]string{"example.com", "example.org", "go.dev"}, WithReversedKeys()))
+	seg, err := reversed.Segment('m')
+	assert.NoError(t, err)
+	assert.True(t, seg.Reversed())
+	assert.True(t, seg.Root().Search("oc.elpmaxe").Leaf())
+	ok, _ := reversed.Contains("moc.elpmaxe")
+	assert.True(t, ok)
+	ok, _ = reversed.Contains("example.com")
+	assert.False(t, ok)
+
+	dict := []string{"Hat", "IS", "it", "kelvin", "Ärger"}
+	for _, opts := range [][]Option{{WithCaseFolding()}, {WithUnicodeCaseFolding()}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+		folded := open(trie)
+		for _, key := range []string{"HAT", "hat", "Is", "iT", "KELVIN", "\u212aelvin", "ärger", "ÄRGER", "äRGER", "ha", "x"} {
+			ok, err := folded.Contains(key)
+			assert.NoError(t, err)
+			assert.Equal(t, trie.Contains(key), ok, key)
+
+			for _, suffix := range []string{"", "s", "/x"} {
+				n, err := folded.SearchPrefix(key + suffix)
+				assert.NoError(t, err)
+				assert.Equal(t, trie.SearchPrefix(key+suffix), n, key+suffix)
+			}
+		}
+		seg, _ := folded.Segment('h')
+		assert.True(t, seg.FoldsCase())
+	}
+
+	encoded := open(BuildSuccinctTrie(domainKeys(500), WithEliasFanoLeaves(), WithPackedLabels(), WithDispatchTable(1)))
+	seg, err = encoded.Segment('a')
+	assert.NoError(t, err)
+	assert.NotNil(t, seg.sparseLeaves)
+	assert.NotNil(t, seg.packed)
+	assert.Equal(t, 1, seg.dispatchLevels)
+	runs := open(BuildSuccinctTrie([]string{"a01", "a02", "a03"}, WithLabelRuns()))
+	seg, _ = runs.Segment('a')
+	assert.Equal(t, 2, seg.runs.Ones())
+
+	var buf bytes.Buffer
+	assert.Error(t, BuildSuccinctTrie([]string{"a", "a"}, WithMultiplicities()).MarshalSegmented(&buf))
+	assert.Error(t, BuildSuccinctTrie([]string{"a"}, WithSuffixTruncation(8)).MarshalSegmented(&buf))
+}
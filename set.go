@@ -0,0 +1,81 @@
+package sutrie
+
+// Set is a thin wrapper around a trie answering the common membership questions
+// without dealing with nodes.
+type Set struct {
+	trie *SuccinctTrie
+	root Node
+}
+
+// NewSet builds a set of keys, see BuildSuccinctTrie.
+func NewSet(keys []string, opts ...Option) *Set {
+	return BuildSuccinctTrie(keys, opts...).Set()
+}
+
+// Set returns a set of the keys of the trie.
+func (t *SuccinctTrie) Set() *Set {
+	return &Set{trie: t, root: t.Root()}
+}
+
+// Trie returns the underlying trie.
+func (s *Set) Trie() *SuccinctTrie {
+	return s.trie
+}
+
+// Len returns the number of keys in the set.
+func (s *Set) Len() int {
+	return s.trie.Size()
+}
+
+// Contains reports whether key is in the set.
+func (s *Set) Contains(key string) bool {
+	if s.trie.rewritesQueries() {
+		q, buf := query(s.trie, key)
+		defer normBuffers.Put(buf)
+		return s.root.SearchBytes(q).Leaf()
+	}
+	return s.root.Search(key).Leaf()
+}
+
+// ContainsBytes is like Contains but takes a byte slice.
+func (s *Set) ContainsBytes(key []byte) bool {
+	if s.trie.rewritesQueries() {
+		q, buf := query(s.trie, key)
+		defer normBuffers.Put(buf)
+		key = q
+	}
+	return s.root.SearchBytes(key).Leaf()
+}
+
+// ContainsPrefixOf reports whether the set contains key or any prefix of key.
+func (s *Set) ContainsPrefixOf(key string) bool {
+	if s.trie.rewritesQueries() {
+		q, buf := query(s.trie, key)
+		defer normBuffers.Put(buf)
+		return s.root.Leaf() || s.root.SearchPrefixBytes(q) > 0
+	}
+	return s.root.Leaf() || s.root.SearchPrefix(key) > 0
+}
+
+// ContainsPrefixOfBytes is like ContainsPrefixOf but takes a byte slice.
+func (s *Set) ContainsPrefixOfBytes(key []byte) bool {
+	if s.trie.rewritesQueries() {
+		q, buf := query(s.trie, key)
+		defer normBuffers.Put(buf)
+		key = q
+	}
+	return s.root.Leaf() || s.root.SearchPrefixBytes(key) > 0
+}
+
+// HasKeysWithPrefix reports whether any key in the set starts with prefix.
+func (s *Set) HasKeysWithPrefix(prefix string) bool {
+	var n Node
+	if s.trie.rewritesQueries() {
+		q, buf := query(s.trie, prefix)
+		defer normBuffers.Put(buf)
+		n = s.root.SearchBytes(q)
+	} else {
+		n = s.root.Search(prefix)
+	}
+	return n.Exists() && (n.Leaf() || n.Size() > 0)
+}
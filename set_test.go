@@ -0,0 +1,30 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	set := NewSet([]string{"hat", "is", "it", "a"})
+	assert.Equal(t, 4, set.Len())
+
+	assert.True(t, set.Contains("hat"))
+	assert.False(t, set.Contains("ha"))
+	assert.False(t, set.Contains("hats"))
+
+	assert.True(t, set.ContainsPrefixOf("hats"))
+	assert.True(t, set.ContainsPrefixOf("a"))
+	assert.False(t, set.ContainsPrefixOf("ha"))
+	assert.False(t, set.ContainsPrefixOf(""))
+
+	assert.True(t, set.HasKeysWithPrefix("h"))
+	assert.True(t, set.HasKeysWithPrefix("hat"))
+	assert.True(t, set.HasKeysWithPrefix(""))
+	assert.False(t, set.HasKeysWithPrefix("hats"))
+
+	empty := NewSet(nil)
+	assert.False(t, empty.HasKeysWithPrefix(""))
+	assert.False(t, empty.Contains(""))
+}
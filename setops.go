@@ -0,0 +1,205 @@
+package sutrie
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+var (
+	errMergeReversed  = errors.New("sutrie: cannot merge tries with reversed and non-reversed keys")
+	errMergeTruncated = errors.New("sutrie: cannot merge truncated tries")
+)
+
+// Union returns a trie of the keys of any of tries.
+// Like Intersection and Difference, it merges the tries level by level without enumerating their keys,
+// in time linear in their number of nodes. The tries must all be built WithReversedKeys or none,
+// and not WithSuffixTruncation, else it returns an error, as it does with ErrTooLarge if the result would be.
+// The result is built with no other option.
+func Union(tries ...*SuccinctTrie) (*SuccinctTrie, error) {
+	return merge(tries, false, func(n []Node) bool {
+		for i := range n {
+			if n[i].leaf {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Intersection returns a trie of the keys of all of tries, see Union.
+func Intersection(tries ...*SuccinctTrie) (*SuccinctTrie, error) {
+	return merge(tries, true, func(n []Node) bool {
+		for i := range n {
+			if !n[i].leaf {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Difference returns a trie of the keys of a which are in none of others, see Union.
+func Difference(a *SuccinctTrie, others ...*SuccinctTrie) (*SuccinctTrie, error) {
+	return merge(append([]*SuccinctTrie{a}, others...), true, func(n []Node) bool {
+		if !n[0].leaf {
+			return false
+		}
+		for i := 1; i < len(n); i++ {
+			if n[i].leaf {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// merge builds the trie of the keys whose nodes in tries, missing ones being null, satisfy keep.
+// Paths of the result are paths of any of tries, and of the first one if prune is set.
+// Every node of the result is a group of nodes of tries, the groups of a level being produced from the previous one
+// in the order of the sparse encoding. If prune is set, the subtrees of the first trie holding no key are
+// found beforehand, so that they are skipped.
+func merge(tries []*SuccinctTrie, prune bool, keep func(n []Node) bool) (*SuccinctTrie, error) {
+	roots := make([]Node, len(tries))
+	for i, t := range tries {
+		roots[i] = t.Root()
+	}
+	return mergeNodes(roots, prune, keep)
+}
+
+// mergeNodes is merge of the subtrees rooted at roots, which become the root of the result.
+func mergeNodes(roots []Node, prune bool, keep func(n []Node) bool) (*SuccinctTrie, error) {
+	if len(roots) == 0 {
+		return BuildSuccinctTrie(nil), nil
+	}
+	for _, n := range roots {
+		if n.trie.reversed != roots[0].trie.reversed {
+			return nil, errMergeReversed
+		}
+		if n.trie.truncated {
+			return nil, errMergeTruncated
+		}
+	}
+
+	k := len(roots)
+	level := append([]Node(nil), roots...)
+
+	var alive *bitvec.Vector
+	if prune {
+		alive = bitvec.New(roots[0].trie.numNodes())
+		markAlive(level, make([]int32, k), alive, keep)
+	}
+
+	ret := &SuccinctTrie{reversed: roots[0].trie.reversed}
+	var labels strings.Builder
+	labels.WriteByte(0)
+	// the empty key, or the key of the root of a subtree, see Subtrie
+	if keep(level) {
+		ret.leaves.Set(0, true)
+		ret.size++
+	}
+
+	pos := 1 // position 0 is the zero bit of the root
+	next := make([]int32, k)
+	for len(level) > 0 {
+		var children []Node
+		for g := 0; g < len(level); g += k {
+			ret.bitmap.Set(pos, true)
+			pos++
+
+			group := level[g : g+k]
+			resetCursors(group, next)
+			for {
+				label, ok := minLabel(group, next)
+				if !ok {
+					break
+				}
+
+				children = appendChildren(children, group, next, label)
+				child := children[len(children)-k:]
+				if prune && !alive.Get(int(child[0].index)) {
+					children = children[:len(children)-k]
+					continue
+				}
+
+				if labels.Len() >= maxNodes {
+					return nil, ErrTooLarge
+				}
+				if keep(child) {
+					ret.leaves.Set(labels.Len(), true)
+					ret.size++
+				}
+				labels.WriteByte(label)
+				pos++
+			}
+		}
+		level = children
+	}
+
+	ret.nodes = labels.String()
+	ret.bitmap.Set(pos, true)
+	ret.bitmap.Init()
+	ret.leaves.Init()
+	ret.initLayout()
+	return ret, nil
+}
+
+// markAlive marks in alive the nodes of the first trie under group having a key to keep, and reports whether there is any.
+// next is scratch space of the size of group.
+func markAlive(group []Node, next []int32, alive *bitvec.Vector, keep func(n []Node) bool) bool {
+	resetCursors(group, next)
+
+	found := false
+	for {
+		label, ok := minLabel(group, next)
+		if !ok {
+			break
+		}
+
+		child := appendChildren(nil, group, next, label)
+		if !child[0].Exists() {
+			continue
+		}
+
+		// the children of child need their own cursors, the ones of group are in use
+		if below := markAlive(child, make([]int32, len(group)), alive, keep); below || keep(child) {
+			alive.Set(int(child[0].index), true)
+			found = true
+		}
+	}
+	return found
+}
+
+// resetCursors points next at the first child of every node of group.
+func resetCursors(group []Node, next []int32) {
+	for i, n := range group {
+		next[i] = n.firstChild
+	}
+}
+
+// appendChildren appends the children labeled label of the nodes of group to dst, advancing their cursors past them.
+func appendChildren(dst []Node, group []Node, next []int32, label byte) []Node {
+	for i, n := range group {
+		var child Node
+		if n.Exists() && next[i] < n.afterLastChild && n.trie.label(next[i]) == label {
+			child = n.trie.node(next[i])
+			next[i]++
+		}
+		dst = append(dst, child)
+	}
+	return dst
+}
+
+// minLabel returns the smallest label of the next children of group.
+func minLabel(group []Node, next []int32) (byte, bool) {
+	label, ok := byte(0), false
+	for i, n := range group {
+		if n.Exists() && next[i] < n.afterLastChild {
+			if l := n.trie.label(next[i]); !ok || l < label {
+				label, ok = l, true
+			}
+		}
+	}
+	return label, ok
+}
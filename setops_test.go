@@ -0,0 +1,88 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mustTrie returns trie, panicking on err, for the tests of the APIs returning a trie and an error.
+func mustTrie(trie *SuccinctTrie, err error) *SuccinctTrie {
+	if err != nil {
+		panic(err)
+	}
+	return trie
+}
+
+func TestSetOperations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	sets := make([][]string, 3)
+	for i := range sets {
+		for j := 0; j < 3000; j++ {
+			sets[i] = append(sets[i], fmt.Sprintf("%x", rnd.Intn(8000)))
+		}
+		sets[i] = append(sets[i], "a", "ab")
+	}
+	sets[1] = append(sets[1], "abc")
+
+	count := make(map[string][]bool)
+	tries := make([]*SuccinctTrie, len(sets))
+	for i, set := range sets {
+		for _, key := range set {
+			if count[key] == nil {
+				count[key] = make([]bool, len(sets))
+			}
+			count[key][i] = true
+		}
+		tries[i] = BuildSuccinctTrie(append([]string(nil), set...))
+	}
+
+	expect := func(keep func(in []bool) bool) []string {
+		var keys []string
+		for key, in := range count {
+			if keep(in) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	check := func(want []string, got *SuccinctTrie) {
+		assert.Equal(t, len(want), got.Size())
+		assert.Equal(t, want, got.Keys())
+		for _, key := range want {
+			assert.True(t, got.Root().Search(key).Leaf(), key)
+		}
+	}
+
+	check(expect(func(in []bool) bool { return in[0] || in[1] || in[2] }), mustTrie(Union(tries...)))
+	check(expect(func(in []bool) bool { return in[0] && in[1] && in[2] }), mustTrie(Intersection(tries...)))
+	check(expect(func(in []bool) bool { return in[0] && !in[1] && !in[2] }), mustTrie(Difference(tries[0], tries[1:]...)))
+	check(expect(func(in []bool) bool { return in[1] && !in[0] }), mustTrie(Difference(tries[1], tries[0])))
+
+	empty := BuildSuccinctTrie(nil)
+	check(nil, mustTrie(Union()))
+	check(nil, mustTrie(Intersection(tries[0], empty)))
+	check(nil, mustTrie(Difference(tries[0], tries[0])))
+	check(tries[0].Keys(), mustTrie(Union(tries[0], empty)))
+	check(tries[0].Keys(), mustTrie(Difference(tries[0], empty)))
+
+	disjoint := mustTrie(Intersection(BuildSuccinctTrie([]string{"abc", "abd"}), BuildSuccinctTrie([]string{"abe", "ab"})))
+	check(nil, disjoint)
+	assert.Equal(t, 1, len(disjoint.nodes))
+
+	reversed := BuildSuccinctTrie([]string{"a.com"}, WithReversedKeys())
+	_, err := Union(tries[0], reversed)
+	assert.Error(t, err)
+	_, err = Intersection(tries[0], BuildSuccinctTrie([]string{"a"}, WithSuffixTruncation(8)))
+	assert.Error(t, err)
+	assert.True(t, mustTrie(Union(reversed, reversed)).Reversed())
+
+	defer func(n int) { maxNodes = n }(maxNodes)
+	maxNodes = 100
+	_, err = Union(tries...)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
@@ -0,0 +1,47 @@
+package sutrie
+
+// Stats describes the shape of a trie.
+type Stats struct {
+	// Nodes is the number of nodes, the root included, Internal the number of nodes having children
+	// and Leaves the number of nodes ending a key
+	Nodes, Internal, Leaves int
+
+	// MaxDepth is the length of the longest path, AvgDepth the average depth of the leaves, that is the average key length
+	MaxDepth int
+	AvgDepth float64
+
+	// Branching[k] is the number of nodes having k children
+	Branching [257]int
+}
+
+// Stats computes the statistics of the shape of the trie in a pass over its bitmap.
+func (t *SuccinctTrie) Stats() Stats {
+	t.Warmup()
+	var s Stats
+	var i, depth, depths int
+	var levelEnd, nextEnd int32 = 1, 1
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		if int32(i) == levelEnd {
+			depth++
+			levelEnd = nextEnd
+		}
+		nextEnd = max(nextEnd, afterLastChild)
+
+		s.Nodes++
+		s.Branching[afterLastChild-firstChild]++
+		if firstChild < afterLastChild {
+			s.Internal++
+		}
+		if t.isLeaf(int32(i)) {
+			s.Leaves++
+			depths += depth
+		}
+		s.MaxDepth = depth
+		i++
+	})
+
+	if s.Leaves > 0 {
+		s.AvgDepth = float64(depths) / float64(s.Leaves)
+	}
+	return s
+}
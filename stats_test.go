@@ -0,0 +1,26 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	s := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "abc"}).Stats()
+	assert.Equal(t, 10, s.Nodes) // root, a h i, b a s t, c t
+	assert.Equal(t, 6, s.Internal)
+	assert.Equal(t, 5, s.Leaves)
+	assert.Equal(t, 3, s.MaxDepth)
+	assert.InDelta(t, 11.0/5, s.AvgDepth, 1e-9)
+	assert.Equal(t, 4, s.Branching[0])
+	assert.Equal(t, 4, s.Branching[1])
+	assert.Equal(t, 1, s.Branching[2])
+	assert.Equal(t, 1, s.Branching[3])
+
+	empty := BuildSuccinctTrie(nil).Stats()
+	assert.Equal(t, 1, empty.Nodes)
+	assert.Equal(t, 0, empty.Leaves)
+	assert.Equal(t, 0, empty.MaxDepth)
+	assert.Zero(t, empty.AvgDepth)
+}
@@ -0,0 +1,203 @@
+package sutrie
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nobekanai/sutrie/bitvec"
+)
+
+// StrideTrie is a trie whose transitions consume 4, 8 or 16 bits of the key, to trade the depth of the trie,
+// and so the number of select queries per lookup, for the fanout of its nodes. Deep tries over small alphabets
+// may be faster with 16-bit transitions, tries of binary keys with 4-bit ones, which find their children in
+// 16 labels at most. Which is faster depends on the data, see BenchmarkStrideTrie.
+type StrideTrie struct {
+	bits int
+
+	// trie holds the keys split in nibbles with 4-bit transitions, or the keys with 8-bit ones
+	trie *SuccinctTrie
+
+	// with 16-bit transitions, the bitmaps are those of a SuccinctTrie and labels[k] is a pair of bytes,
+	// the first one high, keys of odd length ending with a pair of their last byte and 0
+	bitmap bitvec.Vector
+	leaves bitvec.Vector
+	labels []uint16
+	size   int
+	depth  int
+}
+
+var _ Searcher = (*StrideTrie)(nil)
+
+// BuildStrideTrie constructs a StrideTrie of dict with transitions of bits bits, 4, 8 or 16.
+// With 16-bit transitions, keys must not contain 0 bytes.
+func BuildStrideTrie(dict []string, bits int) (*StrideTrie, error) {
+	if dict == nil {
+		return nil, ErrNilInput
+	}
+
+	t := &StrideTrie{bits: bits}
+	var err error
+	switch bits {
+	case 4:
+		nibbles := make([]string, len(dict))
+		for i, key := range dict {
+			b := make([]byte, 2*len(key))
+			for j := 0; j < len(key); j++ {
+				b[2*j], b[2*j+1] = key[j]>>4, key[j]&15
+			}
+			nibbles[i] = string(b)
+		}
+		t.trie, err = Build(nibbles)
+	case 8:
+		t.trie, err = Build(copyKeys(dict))
+	case 16:
+		err = t.buildPairs(dict)
+	default:
+		return nil, fmt.Errorf("%w: %d-bit transitions, not 4, 8 or 16", ErrInvalidOption, bits)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// buildPairs builds the trie of pairs from the trie of the keys padded to an even length,
+// the children of a node being the grandchildren of its node in the trie of bytes.
+func (t *StrideTrie) buildPairs(dict []string) error {
+	padded := make([]string, len(dict))
+	for i, key := range dict {
+		if strings.IndexByte(key, 0) >= 0 {
+			return fmt.Errorf("sutrie: key %q contains a 0 byte", key)
+		}
+		if len(key)%2 == 1 {
+			key += "\x00"
+		}
+		padded[i] = key
+	}
+	byteTrie, err := Build(padded)
+	if err != nil {
+		return err
+	}
+
+	t.bitmap = *bitvec.New(0)
+	t.leaves = *bitvec.New(0)
+	t.labels = []uint16{0}
+	t.leaves.Set(0, byteTrie.Root().leaf)
+	pos := 1 // position 0 is the zero bit of the root
+	var children, grandchildren []Node
+	for level := []Node{byteTrie.Root()}; ; t.depth++ {
+		var next []Node
+		for _, n := range level {
+			t.bitmap.Set(pos, true)
+			pos++
+			children = n.ExpandAll(children[:0])
+			for _, child := range children {
+				grandchildren = child.ExpandAll(grandchildren[:0])
+				for _, g := range grandchildren {
+					t.leaves.Set(len(t.labels), g.leaf)
+					t.labels = append(t.labels, uint16(child.Label())<<8|uint16(g.Label()))
+					next = append(next, g)
+					pos++
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		level = next
+	}
+	t.bitmap.Set(pos, true)
+	t.bitmap.Init()
+	t.leaves.Init()
+	t.size = byteTrie.size
+	return nil
+}
+
+// child returns the child of node labeled pair, or -1.
+func (t *StrideTrie) child(node int, pair uint16) int {
+	first := t.bitmap.Select1(node) - node
+	after := t.bitmap.Select1(node+1) - node - 1
+	k := first + sort.Search(after-first, func(i int) bool { return t.labels[first+i] >= pair })
+	if k < after && t.labels[k] == pair {
+		return k
+	}
+	return -1
+}
+
+// Contains reports whether key is in the trie.
+func (t *StrideTrie) Contains(key string) bool {
+	switch t.bits {
+	case 4:
+		n := t.trie.Root()
+		for i := 0; i < len(key) && n.Exists(); i++ {
+			n = n.Next(key[i] >> 4).Next(key[i] & 15)
+		}
+		return n.Leaf()
+	case 8:
+		return t.trie.Contains(key)
+	}
+
+	node := 0
+	for i := 0; i < len(key) && node >= 0; i += 2 {
+		if i+1 < len(key) {
+			node = t.child(node, uint16(key[i])<<8|uint16(key[i+1]))
+		} else {
+			node = t.child(node, uint16(key[i])<<8)
+		}
+	}
+	return node >= 0 && t.leaves.Get(node)
+}
+
+// SearchPrefix returns the length of the longest key of the trie which is a prefix of key, 0 if there is none.
+func (t *StrideTrie) SearchPrefix(key string) (lastUnmatch int) {
+	switch t.bits {
+	case 4:
+		n := t.trie.Root()
+		for i := 0; i < len(key); i++ {
+			if n = n.Next(key[i] >> 4).Next(key[i] & 15); !n.Exists() {
+				break
+			}
+			if n.leaf {
+				lastUnmatch = i + 1
+			}
+		}
+		return lastUnmatch
+	case 8:
+		return t.trie.SearchPrefix(key)
+	}
+
+	node := 0
+	for i := 0; i < len(key); i += 2 {
+		// a key of odd length ends in the middle of the pair
+		if odd := t.child(node, uint16(key[i])<<8); odd >= 0 {
+			lastUnmatch = i + 1
+		}
+		if i+1 == len(key) {
+			break
+		}
+		if node = t.child(node, uint16(key[i])<<8|uint16(key[i+1])); node < 0 {
+			break
+		}
+		if t.leaves.Get(node) {
+			lastUnmatch = i + 2
+		}
+	}
+	return lastUnmatch
+}
+
+// Size returns the number of keys in the trie.
+func (t *StrideTrie) Size() int {
+	if t.trie != nil {
+		return t.trie.Size()
+	}
+	return t.size
+}
+
+// Depth returns the number of transitions of the longest key, that is the number of steps of its lookup.
+func (t *StrideTrie) Depth() int {
+	if t.trie != nil {
+		return t.trie.Stats().MaxDepth
+	}
+	return t.depth
+}
@@ -0,0 +1,79 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrideTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var dict []string
+	for i := 0; i < 3000; i++ {
+		key := make([]byte, 1+rnd.Intn(12))
+		for j := range key {
+			key[j] = "ACGT"[rnd.Intn(4)]
+		}
+		dict = append(dict, string(key))
+	}
+	plain := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	var queries []string
+	for i := 0; i < 5000; i++ {
+		key := make([]byte, rnd.Intn(16))
+		for j := range key {
+			key[j] = "ACGTN"[rnd.Intn(5)]
+		}
+		queries = append(queries, string(key))
+	}
+	queries = append(queries, dict[:100]...)
+
+	depths := map[int]int{}
+	for _, bits := range []int{4, 8, 16} {
+		trie, err := BuildStrideTrie(dict, bits)
+		assert.NoError(t, err)
+		assert.Equal(t, plain.Size(), trie.Size())
+		for _, key := range queries {
+			assert.Equal(t, plain.Contains(key), trie.Contains(key), bits, key)
+			assert.Equal(t, plain.SearchPrefix(key), trie.SearchPrefix(key), bits, key)
+		}
+		depths[bits] = trie.Depth()
+	}
+	assert.Equal(t, map[int]int{4: 24, 8: 12, 16: 6}, depths)
+
+	_, err := BuildStrideTrie([]string{"a\x00b"}, 16)
+	assert.Error(t, err)
+	_, err = BuildStrideTrie([]string{"a"}, 2)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, err = BuildStrideTrie(nil, 8)
+	assert.ErrorIs(t, err, ErrNilInput)
+
+	empty, err := BuildStrideTrie([]string{}, 16)
+	assert.NoError(t, err)
+	assert.False(t, empty.Contains("a"))
+	assert.Equal(t, 0, empty.SearchPrefix("ab"))
+}
+
+func BenchmarkStrideTrie(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	var dict []string
+	for i := 0; i < 200000; i++ {
+		key := make([]byte, 20+rnd.Intn(20))
+		for j := range key {
+			key[j] = "ACGT"[rnd.Intn(4)]
+		}
+		dict = append(dict, string(key))
+	}
+	rnd.Shuffle(len(dict), func(i, j int) { dict[i], dict[j] = dict[j], dict[i] })
+
+	for _, bits := range []int{4, 8, 16} {
+		trie, _ := BuildStrideTrie(dict, bits)
+		b.Run(fmt.Sprint("bits-", bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				trie.Contains(dict[i%len(dict)])
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package sutrie
+
+// Subtrie returns a standalone trie of the keys under the current node, with the key of the node stripped.
+// The key of the node itself, if it is a leaf, is the empty key of the subtrie.
+// It copies the subtree level by level, in time linear in its number of nodes. Like Union, it returns an error
+// if the trie is built WithSuffixTruncation.
+func (n Node) Subtrie() (*SuccinctTrie, error) {
+	if !n.Exists() {
+		return BuildSuccinctTrie(nil), nil
+	}
+	return mergeNodes([]Node{n}, false, func(n []Node) bool { return n[0].leaf })
+}
+
+// ExtractPrefix returns a standalone trie of the keys of t starting with prefix, with prefix stripped,
+// see Node.Subtrie. Like the traversal APIs, it sees keys as stored, see WithReversedKeys.
+func (t *SuccinctTrie) ExtractPrefix(prefix string) (*SuccinctTrie, error) {
+	return t.Root().Search(prefix).Subtrie()
+}
@@ -0,0 +1,43 @@
+package sutrie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtrie(t *testing.T) {
+	dict := []string{"com.example", "com.example.www", "com.google", "org.golang", "net", "com"}
+	trie := BuildSuccinctTrie(dict)
+
+	sub := mustTrie(trie.ExtractPrefix("com."))
+	assert.Equal(t, []string{"example", "example.www", "google"}, sub.Keys())
+	assert.Equal(t, 3, sub.Size())
+	assert.True(t, sub.Root().Search("google").Leaf())
+	assert.Equal(t, 7, sub.Root().SearchPrefix("example.org"))
+
+	// the prefix itself is a key, it becomes the empty key
+	assert.Equal(t, []string{"", ".example", ".example.www", ".google"}, mustTrie(trie.ExtractPrefix("com")).Keys())
+	assert.True(t, mustTrie(trie.ExtractPrefix("com")).Contains(""))
+	assert.Equal(t, trie.Keys(), mustTrie(trie.ExtractPrefix("")).Keys())
+	assert.Equal(t, 0, mustTrie(trie.ExtractPrefix("edu")).Size())
+	assert.Equal(t, []string{""}, mustTrie(trie.ExtractPrefix("net")).Keys())
+	_, err := BuildSuccinctTrie(dict, WithSuffixTruncation(8)).ExtractPrefix("com")
+	assert.Error(t, err)
+
+	var keys []string
+	for i := 0; i < 10000; i++ {
+		keys = append(keys, randomString(1+i%7))
+	}
+	big := BuildSuccinctTrie(keys)
+	for _, prefix := range []string{keys[0][:1], keys[1][:1]} {
+		var want []string
+		for _, key := range big.Keys() {
+			if strings.HasPrefix(key, prefix) {
+				want = append(want, key[len(prefix):])
+			}
+		}
+		assert.Equal(t, want, mustTrie(big.ExtractPrefix(prefix)).Keys())
+	}
+}
@@ -2,20 +2,101 @@ package sutrie
 
 import (
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"math/bits"
 	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nobekanai/sutrie/bitvec"
 )
 
+// ErrNilInput is returned by Build when the dictionary is nil.
+var ErrNilInput = errors.New("sutrie: nil dictionary")
+
+// ErrInvalidOption is returned by Build when an option is invalid, errors about options wrap it.
+var ErrInvalidOption = errors.New("sutrie: invalid option")
+
+// ErrTooLarge is returned by Build when the trie would have more nodes than 32-bit node indexes can address.
+var ErrTooLarge = errors.New("sutrie: dictionary too large for 32-bit node indexes")
+
+// maxNodes is the maximum number of nodes of a trie, every node taking two bits of the int32-addressed bitmap.
+var maxNodes = math.MaxInt32/2 - 1
+
+// SuccinctTrie is an immutable trie of a set of keys. Its query methods are safe for concurrent use by multiple
+// goroutines, the only state built after construction being the indexes of an unmarshaled trie, which the first
+// query builds once, see Warmup. Iterators and cursors are not, each one must be used by a single goroutine.
+// The methods changing the trie in place, Unmarshal, UnmarshalBinary, ReadFrom, SetNormalizer, Instrument
+// and Builder.Reset, must not run concurrently with anything else on it.
 type SuccinctTrie struct {
-	bitmap bitset
-	leaves bitset
+	bitmap bitvec.Vector
+	leaves bitvec.Vector
 	nodes  string
 	size   int
+
+	// reversed is true if keys are stored reversed, see WithReversedKeys
+	reversed bool
+
+	// sparseLeaves replaces leaves if not nil, see WithEliasFanoLeaves
+	sparseLeaves *bitvec.EliasFano
+
+	// truncated is true if keys are truncated, suffixes packing hashBits bits of the full key per leaf,
+	// see WithSuffixTruncation
+	truncated bool
+	hashBits  int
+	suffixes  []uint64
+
+	// fold is how keys are case folded, see WithCaseFolding
+	fold foldMode
+
+	// normalize is applied to queries if not nil, see WithNormalizer
+	normalize func(key []byte) []byte
+
+	dense      bitvec.Vector
+	denseBits  []uint64
+	denseLimit int32
+
+	// alphabet is the sorted bytes of the labels, codes[b]-1 being the code of b, see WithAlphabetRemap
+	alphabet []byte
+	codes    *[256]uint8
+
+	// packed replaces nodes if not nil, the count labels being packed width bits each, see WithPackedLabels
+	packed []uint64
+	width  int
+	count  int
+
+	// runs marks the nodes whose label is dropped from nodes if not empty, the count labels then being
+	// the ones of nodes and of the runs they start, see WithLabelRuns
+	runs bitvec.Vector
+
+	// dispatch maps the bytes to the children of the nodes of the first dispatchLevels levels, see WithDispatchTable
+	dispatchLevels int
+	dispatch       []int32
+
+	// counts packs the number of occurrences minus one of every key by leaf index, see WithMultiplicities
+	counts packedInts
+
+	// values packs the value of every key by leaf index if hasValues, see WithUint8Values
+	values    packedInts
+	hasValues bool
+
+	// scores packs the score of every key by leaf index, as float32 or float64 bits, see WithScores
+	scores packedInts
+
+	// stats counts the queries if not nil, see Instrument
+	stats *QueryStats
+
+	// lazy builds the indexes of an unmarshaled trie if not nil, see Warmup
+	lazy *sync.Once
 }
 
 type Node struct {
 	trie           *SuccinctTrie
+	index          int32
 	firstChild     int32
 	afterLastChild int32
 	leaf           bool
@@ -23,77 +104,300 @@ type Node struct {
 
 // BuildSuccinctTrie constructs an immutable, succinct prefix tree/trie data structure.
 // You can traverse the tree from root node, but you cannot modify it.
-func BuildSuccinctTrie(dict []string) *SuccinctTrie {
-	sort.Strings(dict)
+// It panics if the build fails, see Build. Unlike Build, it accepts a nil dict as an empty one.
+func BuildSuccinctTrie(dict []string, opts ...Option) *SuccinctTrie {
+	if dict == nil {
+		dict = []string{}
+	}
 
-	ret := &SuccinctTrie{}
+	t, err := Build(dict, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Build is like BuildSuccinctTrie but returns an error instead of panicking:
+// ErrNilInput for a nil dict, an error wrapping ErrInvalidOption for invalid options,
+// ErrTooLarge if the trie cannot be addressed and *InvalidKeysError for keys violating the key grammar.
+func Build(dict []string, opts ...Option) (*SuccinctTrie, error) {
+	if dict == nil {
+		return nil, ErrNilInput
+	}
+
+	var o buildOptions
+	for _, opt := range opts {
+		if opt == nil {
+			return nil, fmt.Errorf("%w: nil option", ErrInvalidOption)
+		}
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	var orig []string
+	if o.values != nil || o.score != nil {
+		if o.values != nil && len(o.values) != len(dict) {
+			return nil, fmt.Errorf("%w: %d values for %d keys", ErrInvalidOption, len(o.values), len(dict))
+		}
+		if o.score != nil && o.scores != len(dict) {
+			return nil, fmt.Errorf("%w: %d scores for %d keys", ErrInvalidOption, o.scores, len(dict))
+		}
+		if o.truncated {
+			return nil, fmt.Errorf("%w: truncated keys cannot have values", ErrInvalidOption)
+		}
+		orig = copyKeys(dict)
+	}
+
+	if o.normalize != nil {
+		dict = normalizeKeys(dict, o.normalize)
+	}
+
+	dict, err := o.validateKeys(dict)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.fold != foldNone {
+		dict = foldKeys(dict, o.fold)
+	}
+	if o.reversed {
+		dict = reverseKeys(dict)
+	}
+	if o.multiset && o.truncated {
+		return nil, fmt.Errorf("%w: truncated keys cannot be counted", ErrInvalidOption)
+	}
+	if o.packLabels && o.labelRuns {
+		return nil, fmt.Errorf("%w: label runs cannot be packed", ErrInvalidOption)
+	}
+	var runs []keyCount
+	if o.onDuplicate != nil || o.multiset {
+		runs = o.dedup(dict)
+	}
+
+	var t *SuccinctTrie
+	if o.truncated {
+		t, err = buildTruncated(dict, &o)
+	} else {
+		t, err = build(dict, &o)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.reversed = o.reversed
+	t.fold = o.fold
+	t.normalize = o.normalize
+	t.encode(&o)
+	t.setCounts(runs)
+	if o.values != nil {
+		if err := t.setValues(orig, o.values); err != nil {
+			return nil, err
+		}
+	}
+	if o.score != nil {
+		if err := t.setScores(orig, &o); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
 
-	type bfsNode struct {
-		l, r  int32
-		depth int32
+// encode applies the options of o choosing how the leaves and the labels are encoded.
+func (t *SuccinctTrie) encode(o *buildOptions) {
+	if o.eliasFanoLeaves {
+		t.compressLeaves()
+	}
+	if o.remap {
+		t.setAlphabet(alphabet(t.nodes))
+		if o.packLabels {
+			t.packNodes()
+		}
+		t.initLayout()
 	}
+	if o.labelRuns {
+		t.encodeRuns()
+	}
+	if o.dispatchLevels > 0 {
+		t.dispatchLevels = o.dispatchLevels
+		t.initDispatch()
+	}
+}
 
-	zeroIdx := 1 // well this is actually one index cause that's easier
-	queue := newQueue[bfsNode](max(1, len(dict)))
-	queue.push(bfsNode{0, int32(len(dict)), 0})
-	nodes := make([]byte, 1)
+// encoding returns the options of encode the trie was built with.
+func (t *SuccinctTrie) encoding() buildOptions {
+	return buildOptions{
+		eliasFanoLeaves: t.sparseLeaves != nil,
+		remap:           t.alphabet != nil,
+		packLabels:      t.packed != nil,
+		labelRuns:       t.runs.Len() > 0,
+		dispatchLevels:  t.dispatchLevels,
+	}
+}
 
-	for queue.size() > 0 {
-		cur := queue.pop()
+// build constructs the trie level by level. The nodes of a level are groups of consecutive keys of the sorted dict
+// sharing a prefix, they are tracked by a bitset marking the first key of each group,
+// so apart from the output, whose size is computed beforehand, the build only needs a few bits per key.
+func build(dict []string, o *buildOptions) (*SuccinctTrie, error) {
+	p := o.progress
+	if len(dict) > maxNodes {
+		return nil, ErrTooLarge
+	}
 
-		ret.bitmap.setBit(zeroIdx, true)
-		zeroIdx++
+	if !o.sorted {
+		sort.Strings(dict)
+	}
+	if err := p.err(); err != nil {
+		return nil, err
+	}
 
-		// make sure has child
-		next := cur.l
-		for next < cur.r && len(dict[next]) <= int(cur.depth) {
-			next++
+	// every key adds a node per byte after the prefix it shares with the previous key
+	n := 1
+	for i := range dict {
+		if i == 0 {
+			n += len(dict[i])
+		} else {
+			n += len(dict[i]) - lcp(dict[i-1], dict[i])
 		}
+		if n > maxNodes {
+			return nil, ErrTooLarge
+		}
+	}
+	if err := p.report(0, n); err != nil {
+		return nil, err
+	}
+
+	ret := &SuccinctTrie{}
+	spare := o.spare
+	if spare != nil {
+		ret.bitmap, ret.leaves, ret.dense, ret.denseBits = spare.bitmap, spare.leaves, spare.dense, spare.denseBits
+		*spare = buildBuffers{scratch: spare.scratch}
+	} else {
+		spare = new(buildBuffers)
+	}
+	ret.bitmap.Reset(2*n + 1)
+	ret.leaves.Reset(n)
+
+	var labels strings.Builder
+	labels.Grow(n)
+	labels.WriteByte(0)
+
+	// alive marks the keys long enough to reach the current level,
+	// marks the first key of each node of the current level
+	words := (len(dict) + 63) >> 6
+	if cap(spare.scratch) < 3*words {
+		spare.scratch = make([]uint64, 3*words)
+	}
+	scratch := spare.scratch[:3*words]
+	clear(scratch)
+	alive, marks, next := scratch[:words:words], scratch[words:2*words:2*words], scratch[2*words:]
+	for i := range dict {
+		alive[i>>6] |= 1 << (i & 63)
+	}
+
+	// the empty key, first once sorted, makes the root a leaf
+	if len(dict) > 0 && dict[0] == "" {
+		ret.leaves.Set(0, true)
+		ret.size++
+	}
+
+	pos := 1 // position 0 is the zero bit of the root
+	if len(dict) == 0 {
+		ret.bitmap.Set(pos, true)
+		pos++
+	} else {
+		marks[0] = 1
+	}
 
-		for i := next; i < cur.r; {
-			r := i
-			for b := (cur.r - i) >> 1; b >= 1; b >>= 1 {
-				for r+b < cur.r && dict[i][cur.depth] == dict[r+b][cur.depth] {
-					r += b
+	for depth := 0; ; depth++ {
+		found := false
+		prev := -1 // the previous key of the current node having a child
+		for w, word := range alive {
+			if w&progressWords == progressWords {
+				if err := p.report(labels.Len(), n); err != nil {
+					return nil, err
 				}
 			}
-			r++
+			for ; word != 0; word &= word - 1 {
+				i := w<<6 + bits.TrailingZeros64(word)
+
+				if marks[w]&(word&-word) != 0 {
+					ret.bitmap.Set(pos, true)
+					pos++
+					prev = -1
+					found = true
+				}
 
-			nodes = append(nodes, dict[i][cur.depth])
+				key := dict[i]
+				if len(key) == depth {
+					alive[w] &^= word & -word
+					continue
+				}
 
-			// touch bottom, this is a leaf
-			if len(dict[i]) == int(cur.depth+1) {
-				ret.leaves.setBit(len(nodes)-1, true)
-				ret.size++
+				if prev == -1 || key[depth] != dict[prev][depth] {
+					if len(key) == depth+1 {
+						ret.leaves.Set(labels.Len(), true)
+						ret.size++
+					}
+					labels.WriteByte(key[depth])
+					next[w] |= word & -word
+					pos++
+				}
+				prev = i
 			}
+		}
 
-			queue.push(bfsNode{i, r, cur.depth + 1})
-			i = r
-			zeroIdx++
+		if !found {
+			break
 		}
+		marks, next = next, marks
+		clear(next)
 	}
 
-	ret.nodes = string(nodes)
-	ret.bitmap.setBit(zeroIdx, true)
-	ret.bitmap.init()
+	if err := p.report(n, n); err != nil {
+		return nil, err
+	}
+
+	ret.nodes = labels.String()
+	ret.bitmap.Set(pos, true)
+	ret.bitmap.Init()
+	ret.leaves.Init()
+	ret.initLayout()
+
+	return ret, nil
+}
+
+// copyKeys returns a copy of dict for Build to sort, nil if dict is nil.
+func copyKeys(dict []string) []string {
+	if dict == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(dict)), dict...)
+}
 
-	return ret
+// lcp returns the length of the longest common prefix of a and b.
+func lcp(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
 }
 
 // Root returns root node of trie
 func (t *SuccinctTrie) Root() Node {
-	firstChild := t.bitmap.selects(1)
+	t.Warmup()
+	firstChild := int32(t.bitmap.Select1(0))
 	if firstChild < 0 {
 		return Node{
-			leaf: false,
+			leaf: t.isLeaf(0),
 			trie: t,
 		}
 	} else {
-		afterLastChild := t.bitmap.selects(2) - 1
+		afterLastChild := int32(t.bitmap.Select1(1)) - 1
 		return Node{
 			firstChild:     firstChild,
 			afterLastChild: afterLastChild,
-			leaf:           false,
+			leaf:           t.isLeaf(0),
 			trie:           t,
 		}
 	}
@@ -116,7 +420,7 @@ func (n Node) Leaf() bool {
 
 // Children function returns a string of the sorted bytes corresponding to the edges of the current node’s child nodes in the trie.
 func (n Node) Children() string {
-	return n.trie.nodes[n.firstChild:n.afterLastChild]
+	return n.trie.labels(n.firstChild, n.afterLastChild)
 }
 
 func (n Node) next(node int32) Node {
@@ -124,259 +428,451 @@ func (n Node) next(node int32) Node {
 		return Node{}
 	}
 
-	firstChild := n.trie.bitmap.selects(node+1) - node
-	if firstChild < 0 {
-		return Node{
-			leaf: true,
-			trie: n.trie,
-		}
-	} else {
-		afterLastChild := n.trie.bitmap.selects(node+2) - node - 1
-		return Node{
-			firstChild:     firstChild,
-			afterLastChild: afterLastChild,
-			leaf:           n.trie.leaves.getBit(node),
-			trie:           n.trie,
-		}
+	return n.trie.nodeAt(node) // the indexes of a trie are built once it has a node
+}
+
+// node returns the node at index in level order, the root being 0.
+func (t *SuccinctTrie) node(node int32) Node {
+	t.Warmup()
+	return t.nodeAt(node)
+}
+
+// nodeAt is node for a trie whose indexes are built.
+func (t *SuccinctTrie) nodeAt(node int32) Node {
+	firstChild, afterLastChild := t.childRange(node)
+	return Node{
+		index:          node,
+		firstChild:     firstChild,
+		afterLastChild: afterLastChild,
+		leaf:           t.isLeaf(node),
+		trie:           t,
+	}
+}
+
+// childRange returns the children [first, after) of the node at index, which is empty if it has none.
+func (t *SuccinctTrie) childRange(node int32) (first, after int32) {
+	first = int32(t.bitmap.Select1(int(node))) - node
+	if first < 0 {
+		return 0, 0
+	}
+	return first, int32(t.bitmap.Select1(int(node)+1)) - node - 1
+}
+
+// ExpandAll appends all children of the current node to buf in label order and returns the extended buffer.
+// It decodes the child ranges in a single pass over the bitmap, which is much cheaper than calling Next for each child
+// when scanning whole subtrees.
+func (n Node) ExpandAll(buf []Node) []Node {
+	if n.firstChild >= n.afterLastChild {
+		return buf
+	}
+
+	t := n.trie
+	pos := int32(t.bitmap.Select1(int(n.firstChild)))
+	for k := n.firstChild; k < n.afterLastChild; k++ {
+		next := int32(t.bitmap.NextOne(int(pos) + 1))
+		buf = append(buf, Node{
+			index:          k,
+			firstChild:     pos - k,
+			afterLastChild: next - k - 1,
+			leaf:           t.isLeaf(k),
+			trie:           t,
+		})
+		pos = next
 	}
+	return buf
 }
 
 // Next returns the next node corresponding to the byte b in the trie from the current node.
 // Note that the returned node may be invalid. You can call Exists to determine its validity.
 func (n Node) Next(b byte) Node {
-	return n.next(n.trie.indexByte(n.firstChild, n.afterLastChild, b))
+	return n.next(n.child(b))
 }
 
 // Search is simply a wrapper around the Next function.
 // It iterates through each byte in the string s within the trie,
 // and returns the final node (note that the node may be a null node).
 func (n Node) Search(s string) Node {
-	for i := 0; i < len(s) && n.Exists(); i++ {
-		n = n.Next(s[i])
-	}
-	return n
+	return search(n, s)
 }
 
-func (t *SuccinctTrie) indexByte(l, r int32, b byte) int32 {
-	r--
-	for r-l >= 15 {
-		k := (l + r) >> 1
-		if t.nodes[k] == b {
-			return k
-		} else if t.nodes[k] > b {
-			r = k - 1
-		} else {
-			l = k + 1
-		}
-	}
+// SearchBytes is like Search but takes a byte slice, saving callers a conversion to string.
+func (n Node) SearchBytes(s []byte) Node {
+	return search(n, s)
+}
 
-	for i := l; i <= r; i++ {
-		if t.nodes[i] == b {
-			return i
+// search and searchPrefix descend with the node in locals and make a Node of the last one only,
+// the lookups being bound by the cost of a step.
+func search[K string | []byte](n Node, s K) Node {
+	if !n.Exists() {
+		return n
+	}
+	t := n.trie
+	k, first, after := n.index, n.firstChild, n.afterLastChild
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf && t.fold == foldUnicode {
+			var size int
+			n, size = nextFolded(t.nodeAt(k), s, i)
+			if !n.Exists() {
+				return n
+			}
+			k, first, after, i = n.index, n.firstChild, n.afterLastChild, i+size-1
+			continue
+		}
+		if first >= after {
+			return Node{}
 		}
+		if k = t.childOf(k, first, after, s[i]); k < 0 {
+			return Node{}
+		}
+		first, after = t.childRange(k)
 	}
-	return -1
+	return t.nodeAt(k)
 }
 
 // SearchPrefix searches the trie for the prefix of the key and returns the last index that does not match.
 // When the match is a full match, the return value is equal to the length of the key, and similarly,
 // when the return value is 0, it means that there is no match at all.
 // For example, suppose there is an entry "xx.yy" in the trie,
-// when searching for "xx.yy.zz" or "xx.yy" it will return 5, when searching for "xx" or "bb" it will return 0
+// when searching for "xx.yy.zz" or "xx.yy" it will return 5, when searching for "xx" or "bb" it will return 0.
+// The empty key, if in the trie, matches every key with a length of 0, which Leaf on the root tells from no match.
 func (cur Node) SearchPrefix(key string) (lastUnmatch int) {
+	return searchPrefix(cur, key)
+}
+
+// SearchPrefixBytes is like SearchPrefix but takes a byte slice, saving callers a conversion to string.
+func (cur Node) SearchPrefixBytes(key []byte) (lastUnmatch int) {
+	return searchPrefix(cur, key)
+}
+
+func searchPrefix[K string | []byte](cur Node, key K) (lastUnmatch int) {
+	if !cur.Exists() {
+		return
+	}
+	t := cur.trie
+	k, first, after := cur.index, cur.firstChild, cur.afterLastChild
 	for i := 0; i < len(key); i++ {
-		if k := cur.trie.indexByte(cur.firstChild, cur.afterLastChild, key[i]); k != -1 {
-			cur = cur.next(k)
-			if cur.leaf {
+		if key[i] >= utf8.RuneSelf && t.fold == foldUnicode {
+			next, size := nextFolded(t.nodeAt(k), key, i)
+			if !next.Exists() {
+				break
+			}
+			k, first, after, i = next.index, next.firstChild, next.afterLastChild, i+size-1
+			if next.leaf {
 				lastUnmatch = i + 1
 			}
-		} else {
+			continue
+		}
+
+		if first >= after {
+			break
+		}
+		if k = t.childOf(k, first, after, key[i]); k < 0 {
 			break
 		}
+		first, after = t.childRange(k)
+		if t.isLeaf(k) {
+			lastUnmatch = i + 1
+		}
 	}
 
 	return
 }
 
-// Size returns number of leaves in trie
-func (t *SuccinctTrie) Size() int {
-	return t.size
-}
-
-type wrapSuccinctTrie struct {
-	BitmapBits []uint64
-	LeavesBits []uint64
-	Nodes      string
-	Size       int
+// Searcher is the query interface shared by SuccinctTrie and DoubleArrayTrie,
+// so that both can be used and compared with the same code.
+type Searcher interface {
+	// Contains reports whether key is in the dictionary.
+	Contains(key string) bool
+	// SearchPrefix returns the length of the longest key of the dictionary which is a prefix of key, 0 if there is none.
+	SearchPrefix(key string) int
+	// Size returns the number of keys in the dictionary.
+	Size() int
 }
 
-func (v *SuccinctTrie) Marshal(writer io.Writer) error {
-	w := wrapSuccinctTrie{v.bitmap.bits, v.leaves.bits, v.nodes, v.size}
+var _ Searcher = (*SuccinctTrie)(nil)
 
-	enc := gob.NewEncoder(writer)
-	return enc.Encode(w)
+// Contains reports whether key is in the trie, it is short for t.Root().Search(key).Leaf() with key normalized
+// and reversed as the keys of the build, see WithNormalizer and WithReversedKeys.
+func (t *SuccinctTrie) Contains(key string) bool {
+	if t.rewritesQueries() {
+		q, buf := query(t, key)
+		defer normBuffers.Put(buf)
+		return contains(t, q)
+	}
+	return contains(t, key)
 }
 
-func (v *SuccinctTrie) Unmarshal(reader io.Reader) error {
-	w := wrapSuccinctTrie{}
-
-	dec := gob.NewDecoder(reader)
-	if err := dec.Decode(&w); err != nil {
-		return err
+// SearchPrefix is short for t.Root().SearchPrefix(key) with key normalized and reversed like by Contains.
+func (t *SuccinctTrie) SearchPrefix(key string) int {
+	if t.rewritesQueries() {
+		q, buf := query(t, key)
+		defer normBuffers.Put(buf)
+		return longestPrefix(t, q)
 	}
+	return longestPrefix(t, key)
+}
 
-	v.bitmap.bits = w.BitmapBits
-	v.leaves.bits = w.LeavesBits
-	v.bitmap.ranks = nil
-	v.bitmap.sl = nil
-	v.leaves.ranks = nil
-	v.leaves.sl = nil
-	v.nodes = w.Nodes
-	v.size = w.Size
+// Parent returns the parent of the current node, or a null node if the current node is the root.
+func (n Node) Parent() Node {
+	if n.index == 0 {
+		return Node{}
+	}
 
-	v.bitmap.init()
-	return nil
+	// the zero bit of a node is in the block of ones of its parent
+	pos := n.trie.bitmap.Select0(int(n.index))
+	return n.trie.node(int32(n.trie.bitmap.Rank1(pos)) - 1)
 }
 
-type bitset struct {
-	bits  []uint64
-	ranks []int32
-	sl    []int32
-	mr    int32
+// Label returns the byte on the edge from the parent to the current node, it is 0 for the root.
+func (n Node) Label() byte {
+	if n.index == 0 {
+		return 0
+	}
+	return n.trie.label(n.index)
 }
 
-func (b *bitset) setBit(pos int, value bool) {
-	for pos>>6 >= len(b.bits) {
-		b.bits = append(b.bits, 0)
+// Key reconstructs the key of the current node by walking up to the root.
+func (n Node) Key() string {
+	var key []byte
+	for ; n.Exists() && n.index != 0; n = n.Parent() {
+		key = append(key, n.Label())
 	}
-	if value {
-		b.bits[pos>>6] |= uint64(1) << (pos & 63)
-	} else {
-		b.bits[pos>>6] &^= uint64(1) << (pos & 63)
+	for i, j := 0, len(key)-1; i < j; i, j = i+1, j-1 {
+		key[i], key[j] = key[j], key[i]
 	}
+	return string(key)
+}
+
+// SearchPrefixDelim is like SearchPrefix but only counts matches ending at a delimiter boundary,
+// that is where the key ends, is followed by delim, or where the matched entry itself ends with delim.
+// For example, with an entry "xx.yy" in the trie and delim '.', searching for "xx.yy.zz" or "xx.yy" returns 5,
+// while searching for "xx.yyzz" returns 0.
+func (cur Node) SearchPrefixDelim(key string, delim byte) (lastUnmatch int) {
+	return searchPrefixDelim(cur, key, delim)
+}
 
-	b.ranks = nil
+// SearchPrefixDelimBytes is like SearchPrefixDelim but takes a byte slice, saving callers a conversion to string.
+func (cur Node) SearchPrefixDelimBytes(key []byte, delim byte) (lastUnmatch int) {
+	return searchPrefixDelim(cur, key, delim)
 }
 
-func (b *bitset) getBit(pos int32) bool {
-	if pos>>6 >= int32(len(b.bits)) {
-		return false
+func searchPrefixDelim[K string | []byte](cur Node, key K, delim byte) (lastUnmatch int) {
+	for i := 0; i < len(key); i++ {
+		if key[i] >= utf8.RuneSelf && cur.trie.fold == foldUnicode {
+			next, size := nextFolded(cur, key, i)
+			if !next.Exists() {
+				break
+			}
+			cur, i = next, i+size-1
+		} else {
+			k := cur.child(key[i])
+			if k == -1 {
+				break
+			}
+			cur = cur.next(k)
+		}
+
+		if cur.leaf && (i+1 == len(key) || key[i+1] == delim || key[i] == delim) {
+			lastUnmatch = i + 1
+		}
 	}
 
-	return b.bits[pos>>6]&(uint64(1)<<(pos&63)) > 0
+	return
 }
 
-func (b *bitset) init() {
-	for i := len(b.bits) - 1; i >= 0 && bits.OnesCount64(b.bits[i]) == 0; i-- {
-		b.bits = b.bits[:i]
+// LeafIndex returns the rank of the current node among all leaves, in [0, Size()) of the trie,
+// or -1 if the current node is not a leaf.
+// Leaves are ranked in level order, which is stable for a given dictionary, so the index
+// can address a parallel slice of values: together with KeyAt, the trie acts as a minimal perfect map.
+func (n Node) LeafIndex() int {
+	if !n.leaf {
+		return -1
 	}
+	return n.trie.leavesBefore(n.index)
+}
 
-	b.ranks = make([]int32, len(b.bits)+1)
-	b.sl = make([]int32, len(b.bits)/2+2)
-	var t int32 = 1
-	for i := 0; i < len(b.bits); i++ {
-		n := bits.OnesCount64(b.bits[i])
-		b.ranks[i+1] = b.ranks[i] + int32(n)
-		if b.ranks[i+1]>>6 >= t {
-			b.sl[t] = int32(i)
-			t++
-		}
+// KeyAt returns the key of the leaf whose LeafIndex is i, it panics if i is out of range.
+func (t *SuccinctTrie) KeyAt(i int) string {
+	if i < 0 || i >= t.size {
+		panic("sutrie: leaf index out of range")
 	}
-	b.sl[t] = int32(len(b.bits)) - 1
-	b.mr = b.ranks[len(b.ranks)-1]
+	return t.node(t.selectLeaf(i)).Key()
 }
 
-func (b *bitset) selects(nth int32) int32 {
-	if b.mr < nth {
+// ID returns the identifier of the current node, its rank in level order, the root being 0.
+// IDs are dense in [0, Stats().Nodes) and stable for a given trie, marshaling included, so they can index
+// external arrays of per-node metadata. It is -1 for a null node.
+func (n Node) ID() int32 {
+	if !n.Exists() {
 		return -1
 	}
+	return n.index
+}
 
-	l, r := b.sl[nth>>6], b.sl[nth>>6+1]
-	for ; l+15 < r && b.ranks[l+16] < int32(nth); l += 16 {
-	}
-	for ; l < r && b.ranks[l+1] < int32(nth); l++ {
+// NodeByID returns the node whose ID is id, or a null node if there is none.
+func (t *SuccinctTrie) NodeByID(id int32) Node {
+	if id < 0 || int(id) >= t.numNodes() {
+		return Node{}
 	}
+	return t.node(id)
+}
 
-	return l<<6 + int32(nthSet(b.bits[l], uint8(nth-b.ranks[l]-1)))
+// Size returns number of leaves in trie
+func (t *SuccinctTrie) Size() int {
+	return t.size
 }
 
-const pop8tab = "" +
-	"\x00\x01\x01\x02\x01\x02\x02\x03\x01\x02\x02\x03\x02\x03\x03\x04" +
-	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
-	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
-	"\x01\x02\x02\x03\x02\x03\x03\x04\x02\x03\x03\x04\x03\x04\x04\x05" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
-	"\x02\x03\x03\x04\x03\x04\x04\x05\x03\x04\x04\x05\x04\x05\x05\x06" +
-	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
-	"\x03\x04\x04\x05\x04\x05\x05\x06\x04\x05\x05\x06\x05\x06\x06\x07" +
-	"\x04\x05\x05\x06\x05\x06\x06\x07\x05\x06\x06\x07\x06\x07\x07\x08"
+type wrapSuccinctTrie struct {
+	BitmapBits []uint64
+	LeavesBits []uint64
+	Nodes      string
+	Size       int
+	Reversed   bool
+
+	// EliasFanoLeaves replaces LeavesBits if not empty, see WithEliasFanoLeaves
+	EliasFanoLeaves []byte
+
+	Truncated bool
+	HashBits  int
+	Suffixes  []uint64
+
+	Fold uint8
 
-const precomp = "\x00\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x06\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\a\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x06\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x05\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00\x04\x00\x01\x00\x02\x00\x01\x00\x03\x00\x01\x00\x02\x00\x01\x00" +
-	"\x00\x00\x00\x01\x00\x02\x02\x01\x00\x03\x03\x01\x03\x02\x02\x01\x00\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\x06\x06\x01\x06\x02\x02\x01\x06\x03\x03\x01\x03\x02\x02\x01\x06\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x06\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x00\a\a\x01\a\x02\x02\x01\a\x03\x03\x01\x03\x02\x02\x01\a\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\a\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\a\x06\x06\x01\x06\x02\x02\x01\x06\x03\x03\x01\x03\x02\x02\x01\x06\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01\x06\x05\x05\x01\x05\x02\x02\x01\x05\x03\x03\x01\x03\x02\x02\x01\x05\x04\x04\x01\x04\x02\x02\x01\x04\x03\x03\x01\x03\x02\x02\x01" +
-	"\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x03\x00\x03\x03\x02\x00\x00\x00\x04\x00\x04\x04\x02\x00\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\x05\x00\x05\x05\x02\x00\x05\x05\x03\x05\x03\x03\x02\x00\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\x06\x00\x06\x06\x02\x00\x06\x06\x03\x06\x03\x03\x02\x00\x06\x06\x04\x06\x04\x04\x02\x06\x04\x04\x03\x04\x03\x03\x02\x00\x06\x06\x05\x06\x05\x05\x02\x06\x05\x05\x03\x05\x03\x03\x02\x06\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\x00\x00\a\x00\a\a\x02\x00\a\a\x03\a\x03\x03\x02\x00\a\a\x04\a\x04\x04\x02\a\x04\x04\x03\x04\x03\x03\x02\x00\a\a\x05\a\x05\x05\x02\a\x05\x05\x03\x05\x03\x03\x02\a\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02\x00\a\a\x06\a\x06\x06\x02\a\x06\x06\x03\x06\x03\x03\x02\a\x06\x06\x04\x06\x04\x04\x02\x06\x04\x04\x03\x04\x03\x03\x02\a\x06\x06\x05\x06\x05\x05\x02\x06\x05\x05\x03\x05\x03\x03\x02\x06\x05\x05\x04\x05\x04\x04\x02\x05\x04\x04\x03\x04\x03\x03\x02" +
-	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x03\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x04\x00\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x05\x00\x05\x05\x03\x00\x00\x00\x05\x00\x05\x05\x04\x00\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x03\x00\x00\x00\x06\x00\x06\x06\x04\x00\x06\x06\x04\x06\x04\x04\x03\x00\x00\x00\x06\x00\x06\x06\x05\x00\x06\x06\x05\x06\x05\x05\x03\x00\x06\x06\x05\x06\x05\x05\x04\x06\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x03\x00\x00\x00\a\x00\a\a\x04\x00\a\a\x04\a\x04\x04\x03\x00\x00\x00\a\x00\a\a\x05\x00\a\a\x05\a\x05\x05\x03\x00\a\a\x05\a\x05\x05\x04\a\x05\x05\x04\x05\x04\x04\x03\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x03\x00\a\a\x06\a\x06\x06\x04\a\x06\x06\x04\x06\x04\x04\x03\x00\a\a\x06\a\x06\x06\x05\a\x06\x06\x05\x06\x05\x05\x03\a\x06\x06\x05\x06\x05\x05\x04\x06\x05\x05\x04\x05\x04\x04\x03" +
-	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x05\x00\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x04\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x05\x00\x00\x00\x06\x00\x06\x06\x05\x00\x06\x06\x05\x06\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x04\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x05\x00\x00\x00\a\x00\a\a\x05\x00\a\a\x05\a\x05\x05\x04\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x04\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x05\x00\a\a\x06\a\x06\x06\x05\a\x06\x06\x05\x06\x05\x05\x04" +
-	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x06\x00\x06\x06\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x05\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06\x00\x00\x00\a\x00\a\a\x06\x00\a\a\x06\a\x06\x06\x05" +
-	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\x00\x00\x00\x00\a\x00\x00\x00\a\x00\a\a\x06" +
-	"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\a"
+	DispatchLevels uint8
 
-func nthSet(v uint64, n uint8) uint8 {
-	shift := uint8(0)
-	p := pop8tab[v>>24&0xff] + pop8tab[v>>16&0xff] + pop8tab[v>>8&0xff] + pop8tab[v&0xff]
-	if p <= n {
-		v >>= 32
-		shift |= 32
-		n -= p
+	// PackedNodes replaces Nodes if Alphabet is not empty, see WithAlphabetRemap
+	Alphabet    []byte
+	PackedNodes []byte
+	PackLabels  bool
+
+	// Counts and CountsWidth are the packed occurrence counts, see WithMultiplicities
+	Counts      []uint64
+	CountsWidth uint8
+
+	// Values and ValuesWidth are the packed values if HasValues, see WithUint8Values
+	Values      []uint64
+	ValuesWidth uint8
+	HasValues   bool
+
+	// Scores and ScoreBits are the packed scores, see WithScores
+	Scores    []uint64
+	ScoreBits uint8
+
+	// LabelRuns marks the nodes whose label is dropped from Nodes, see WithLabelRuns
+	LabelRuns []uint64
+}
+
+func (v *SuccinctTrie) Marshal(writer io.Writer) error {
+	v.Warmup()
+	w := wrapSuccinctTrie{
+		BitmapBits:     v.bitmap.Words(),
+		LeavesBits:     v.leaves.Words(),
+		Nodes:          v.nodes,
+		Size:           v.size,
+		Reversed:       v.reversed,
+		Truncated:      v.truncated,
+		HashBits:       v.hashBits,
+		Suffixes:       v.suffixes,
+		Fold:           uint8(v.fold),
+		DispatchLevels: uint8(v.dispatchLevels),
+		PackLabels:     v.packed != nil,
+		Counts:         v.counts.words,
+		CountsWidth:    uint8(v.counts.width),
+		Values:         v.values.words,
+		ValuesWidth:    uint8(v.values.width),
+		HasValues:      v.hasValues,
+		Scores:         v.scores.words,
+		ScoreBits:      uint8(v.scores.width),
 	}
-	p = pop8tab[(v>>8)&0xff] + pop8tab[v&0xff]
-	if p <= n {
-		v >>= 16
-		shift |= 16
-		n -= p
+	if v.sparseLeaves != nil {
+		w.EliasFanoLeaves, _ = v.sparseLeaves.MarshalBinary()
 	}
-	p = pop8tab[v&0xff]
-	if p <= n {
-		v >>= 8
-		shift |= 8
-		n -= p
+	if v.alphabet != nil {
+		w.Nodes, w.Alphabet, w.PackedNodes = "", v.alphabet, v.serializedLabels()
+	}
+	if v.runs.Len() > 0 {
+		w.LabelRuns = v.runs.Words()
 	}
-	return precomp[uint64(n)<<8|v&0xff] + shift
-}
 
-type queue[T any] struct {
-	data  []T
-	l, sz uint32
+	enc := gob.NewEncoder(writer)
+	return enc.Encode(w)
 }
 
-func newQueue[T any](size int) queue[T] {
-	return queue[T]{
-		data: make([]T, size),
+// Unmarshal reads a trie written by Marshal, MarshalCompressed or MarshalSections from reader into v.
+func (v *SuccinctTrie) Unmarshal(reader io.Reader) error {
+	w := wrapSuccinctTrie{}
+
+	format, r, done, err := openTrie(reader)
+	if err != nil {
+		return err
+	}
+	if format == formatSections {
+		err = readSections(r, &w)
+	} else {
+		err = gob.NewDecoder(r).Decode(&w)
+	}
+	if err != nil {
+		return err
+	}
+	if err := done(); err != nil {
+		return err
 	}
-}
 
-func (q *queue[T]) push(elm T) {
-	q.data[int(q.l+q.sz)%len(q.data)] = elm
-	q.sz++
-}
+	if len(w.Alphabet) > 0 {
+		if w.Nodes, err = unpackLabels(w.PackedNodes, w.Alphabet); err != nil {
+			return err
+		}
+	}
 
-func (q *queue[T]) size() int {
-	return int(q.sz)
-}
+	var sparseLeaves *bitvec.EliasFano
+	if len(w.EliasFanoLeaves) > 0 {
+		sparseLeaves = new(bitvec.EliasFano)
+		if err := sparseLeaves.UnmarshalBinary(w.EliasFanoLeaves); err != nil {
+			return err
+		}
+	}
+	if w.CountsWidth > 64 || len(w.Counts)*64 < w.Size*int(w.CountsWidth) {
+		return errInvalidCounts
+	}
+	if w.ValuesWidth > 8 || len(w.Values)*64 < w.Size*int(w.ValuesWidth) {
+		return errInvalidValues
+	}
+	scores := packedInts{uint(w.ScoreBits), w.Scores}
+	if err := checkScores(scores, w.Size); err != nil {
+		return err
+	}
+	if w.PackLabels && len(w.LabelRuns) > 0 {
+		return errInvalidLabels
+	}
 
-func (q *queue[T]) pop() T {
-	if q.sz == 0 {
-		panic("pop: no element")
+	// decoded into t, so that v is left as is on errors, its normalizer and stats being kept
+	t := SuccinctTrie{
+		bitmap:         *bitvec.WrapWords(w.BitmapBits, len(w.BitmapBits)<<6),
+		leaves:         *bitvec.WrapWords(w.LeavesBits, len(w.LeavesBits)<<6),
+		sparseLeaves:   sparseLeaves,
+		nodes:          w.Nodes,
+		size:           w.Size,
+		reversed:       w.Reversed,
+		truncated:      w.Truncated,
+		hashBits:       w.HashBits,
+		suffixes:       w.Suffixes,
+		fold:           foldMode(w.Fold),
+		normalize:      v.normalize,
+		dispatchLevels: int(w.DispatchLevels),
+		counts:         packedInts{uint(w.CountsWidth), w.Counts},
+		values:         packedInts{uint(w.ValuesWidth), w.Values},
+		hasValues:      w.HasValues,
+		scores:         scores,
+		stats:          v.stats,
+	}
+	t.setAlphabet(w.Alphabet)
+	t.setRuns(w.LabelRuns)
+	if w.PackLabels {
+		t.packNodes()
 	}
 
-	ret := q.data[q.l]
-	q.l = (q.l + 1) % uint32(len(q.data))
-	q.sz--
-	return ret
+	// the indexes are built on first use, see Warmup
+	t.lazy = new(sync.Once)
+	*v = t
+	return nil
 }
@@ -3,6 +3,7 @@ package sutrie
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/gob"
 	"fmt"
 	mrand "math/rand"
 	"os"
@@ -13,63 +14,20 @@ import (
 
 // TODO: find a better test approach
 
-func TestBitset(t *testing.T) {
-	bs := bitset{}
-
-	bs.setBit(4, true)
-	bs.setBit(567, true)
-
-	assert.True(t, bs.getBit(4))
-	assert.True(t, bs.getBit(567))
-	assert.False(t, bs.getBit(8))
-	assert.False(t, bs.getBit(568))
-
-	bs.setBit(567, false)
-	assert.False(t, bs.getBit(567))
-	assert.True(t, bs.getBit(4))
-
-	bs.setBit(5, true)
-	bs.setBit(128, true)
-	bs.setBit(127, true)
-
-	bs.init()
-
-	// 4,5,127,128
-	assert.Equal(t, int32(4), bs.selects(1))
-	assert.Equal(t, int32(5), bs.selects(2))
-	assert.Equal(t, int32(127), bs.selects(3))
-	assert.Equal(t, int32(128), bs.selects(4))
-	assert.Equal(t, int32(-1), bs.selects(5))
-}
-
-func TestNthSet(t *testing.T) {
-	var n uint64 = 0b1010101011
-
-	assert.Equal(t, uint8(0), nthSet(n, 0))
-	assert.Equal(t, uint8(1), nthSet(n, 1))
-	assert.Equal(t, uint8(5), nthSet(n, 3))
-
-	n = 1<<64 - 1
-
-	for i := 0; i < 64; i++ {
-		assert.Equal(t, uint8(i), nthSet(n, uint8(i)))
-	}
-}
-
 func TestBuildSuccinctTrie(t *testing.T) {
 	dict := []string{"hat", "is", "it", "a"}
 	trie := BuildSuccinctTrie(dict)
 
 	assert.Equal(t, string([]byte{0, 'a', 'h', 'i', 'a', 's', 't', 't'}), trie.nodes)
-	assert.Equal(t, "11110100101100010", fmt.Sprintf("%08b", trie.bitmap.bits[0]))
+	assert.Equal(t, "11110100101100010", fmt.Sprintf("%08b", trie.bitmap.Words()[0]))
 
-	assert.True(t, trie.leaves.getBit(1))
-	assert.False(t, trie.leaves.getBit(2))
-	assert.False(t, trie.leaves.getBit(3))
-	assert.False(t, trie.leaves.getBit(4))
-	assert.True(t, trie.leaves.getBit(5))
-	assert.True(t, trie.leaves.getBit(6))
-	assert.True(t, trie.leaves.getBit(7))
+	assert.True(t, trie.leaves.Get(1))
+	assert.False(t, trie.leaves.Get(2))
+	assert.False(t, trie.leaves.Get(3))
+	assert.False(t, trie.leaves.Get(4))
+	assert.True(t, trie.leaves.Get(5))
+	assert.True(t, trie.leaves.Get(6))
+	assert.True(t, trie.leaves.Get(7))
 
 	assert.Equal(t, 4, trie.size)
 
@@ -136,12 +94,47 @@ func TestRandomSearchOnSuccinctTrie(t *testing.T) {
 }
 
 func TestEmptyStringBehaviorSuccinctTrie(t *testing.T) {
-	trie := BuildSuccinctTrie([]string{"", "", "abc"}).Root()
+	trie := BuildSuccinctTrie([]string{"", "", "abc"})
+	root := trie.Root()
+
+	assert.True(t, root.Search("abc").Leaf())
+	assert.True(t, root.Search("").Leaf())
+	assert.True(t, root.Leaf())
+	assert.Equal(t, 1, root.Size())
+	assert.Equal(t, 2, trie.Size())
+	assert.Equal(t, []string{"", "abc"}, trie.Keys())
+	assert.Equal(t, 0, root.LeafIndex())
+	assert.Equal(t, "", trie.KeyAt(0))
+	assert.Equal(t, "abc", trie.KeyAt(1))
+	assert.Equal(t, 2, trie.Stats().Leaves)
+
+	// the empty key is a prefix of every key, of length 0 like no match at all
+	assert.Equal(t, 0, root.SearchPrefix("xyz"))
+	assert.Equal(t, 3, root.SearchPrefix("abcd"))
+	assert.True(t, trie.Contains(""))
+	assert.Equal(t, []int{0, 3}, root.PrefixMatches("abcd"))
+	assert.Equal(t, []int{0}, root.PrefixMatches(""))
+	n, ok := trie.ShortestPrefix("xyz")
+	assert.True(t, ok)
+	assert.Equal(t, root.PrefixMatches("xyz")[0], n)
+
+	for _, opts := range [][]Option{{WithEliasFanoLeaves()}, {WithSuffixTruncation(8)}, {WithPackedLabels()}} {
+		other := BuildSuccinctTrie([]string{"", "abc"}, opts...)
+		assert.True(t, other.Contains(""))
+		assert.Equal(t, 2, other.Size())
+	}
 
-	assert.True(t, trie.Search("abc").Leaf())
-	assert.False(t, trie.Search("").Leaf())
-	assert.False(t, trie.Leaf())
-	assert.Equal(t, 1, trie.Size())
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	var loaded SuccinctTrie
+	assert.NoError(t, loaded.Unmarshal(&buf))
+	assert.True(t, loaded.Root().Leaf())
+	assert.True(t, trie.Equal(&loaded))
+
+	assert.Equal(t, []string{"", "abc", "x"}, mustTrie(Union(trie, BuildSuccinctTrie([]string{"x"}))).Keys())
+	assert.Equal(t, []string{"abc"}, mustTrie(Difference(trie, BuildSuccinctTrie([]string{""}))).Keys())
+	assert.False(t, BuildSuccinctTrie([]string{"abc"}).Root().Leaf())
+	assert.True(t, BuildDoubleArrayTrie([]string{"", "abc"}).Contains(""))
 }
 
 func TestMarshalBinary(t *testing.T) {
@@ -173,6 +166,22 @@ func TestMarshalBinary(t *testing.T) {
 
 	lastUnmatch = root.SearchPrefix("ti")
 	assert.Equal(t, 0, lastUnmatch)
+
+	// a trie failing its checks leaves the trie unmarshaled into as it was
+	for name, corrupt := range map[string]func(w *wrapSuccinctTrie){
+		"counts": func(w *wrapSuccinctTrie) { w.CountsWidth = 70 },
+		"values": func(w *wrapSuccinctTrie) { w.ValuesWidth = 8 },
+		"scores": func(w *wrapSuccinctTrie) { w.ScoreBits = 7 },
+		"labels": func(w *wrapSuccinctTrie) { w.PackLabels, w.LabelRuns = true, []uint64{2} },
+	} {
+		other := BuildSuccinctTrie([]string{"x", "yz"})
+		w := wrapSuccinctTrie{BitmapBits: other.bitmap.Words(), LeavesBits: other.leaves.Words(), Nodes: other.nodes, Size: 2}
+		corrupt(&w)
+		buf.Reset()
+		assert.NoError(t, gob.NewEncoder(&buf).Encode(w))
+		assert.Error(t, decTrie.Unmarshal(&buf), name)
+		assert.True(t, trie.Equal(&decTrie), name)
+	}
 }
 
 func loadLocalDomains() (ret []string) {
@@ -287,3 +296,184 @@ func BenchmarkRandomSearchOnSuccinctTrie(b *testing.B) {
 		}
 	})
 }
+
+func TestLeafIndex(t *testing.T) {
+	dict := []string{"hat", "is", "it", "a", "中文", "abc", "abd", "ab"}
+	trie := BuildSuccinctTrie(dict)
+	root := trie.Root()
+
+	seen := make([]bool, trie.Size())
+	for _, key := range dict {
+		i := root.Search(key).LeafIndex()
+		assert.False(t, seen[i])
+		seen[i] = true
+		assert.Equal(t, key, trie.KeyAt(i))
+	}
+
+	assert.Equal(t, -1, root.LeafIndex())
+	assert.Equal(t, -1, root.Search("h").LeafIndex())
+	assert.Panics(t, func() { trie.KeyAt(len(dict)) })
+}
+
+func TestSearchPrefixDelim(t *testing.T) {
+	root := BuildSuccinctTrie([]string{"xx.yy", "xx", "a/", "a/b/c"}).Root()
+
+	for _, c := range []struct {
+		key   string
+		delim byte
+		want  int
+	}{
+		{"xx.yy.zz", '.', 5},
+		{"xx.yy", '.', 5},
+		{"xx.yyzz", '.', 2},
+		{"xxyy", '.', 0},
+		{"xx", '.', 2},
+		{"a/b", '/', 2},
+		{"a/b/c/d", '/', 5},
+		{"a/b/cd", '/', 2},
+		{"b", '/', 0},
+	} {
+		assert.Equal(t, c.want, root.SearchPrefixDelim(c.key, c.delim), c.key)
+	}
+}
+
+func TestExpandAll(t *testing.T) {
+	const l = 10000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(1 + mrand.Intn(3))
+	}
+	trie := BuildSuccinctTrie(dict)
+
+	var buf []Node
+	count := 0
+	_ = trie.Walk(func(key string, node Node) error {
+		buf = node.ExpandAll(buf[:0])
+		assert.Equal(t, node.Size(), len(buf))
+		for i, child := range buf {
+			assert.Equal(t, node.Next(node.Children()[i]), child)
+		}
+		count++
+		return nil
+	})
+	assert.Greater(t, count, 256)
+}
+
+func BenchmarkExpandAll(b *testing.B) {
+	const l = 100000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(10)
+	}
+	root := BuildSuccinctTrie(dict).Root()
+
+	b.Run("ExpandAll", func(b *testing.B) {
+		var buf []Node
+		for i := 0; i < b.N; i++ {
+			buf = root.ExpandAll(buf[:0])
+		}
+	})
+
+	b.Run("Next", func(b *testing.B) {
+		var buf []Node
+		for i := 0; i < b.N; i++ {
+			buf = buf[:0]
+			for _, c := range []byte(root.Children()) {
+				buf = append(buf, root.Next(c))
+			}
+		}
+	})
+}
+
+func TestSearchBytes(t *testing.T) {
+	root := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "xx.yy"}).Root()
+
+	for _, key := range []string{"hat", "hatt", "ha", "is", "iss", "ti", "", "xx.yy.zz", "xx.yyzz"} {
+		assert.Equal(t, root.Search(key), root.SearchBytes([]byte(key)))
+		assert.Equal(t, root.SearchPrefix(key), root.SearchPrefixBytes([]byte(key)))
+		assert.Equal(t, root.SearchPrefixDelim(key, '.'), root.SearchPrefixDelimBytes([]byte(key), '.'))
+	}
+
+	key := []byte("hatt")
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		root.SearchPrefixBytes(key)
+		root.SearchBytes(key)
+	}))
+}
+
+func TestBuildTooLarge(t *testing.T) {
+	defer func(n int) { maxNodes = n }(maxNodes)
+	maxNodes = 8
+
+	_, err := Build([]string{"hat", "is", "it", "a"})
+	assert.NoError(t, err)
+
+	_, err = Build([]string{"hat", "is", "it", "a", "b"})
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Build(make([]string, 9))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := Build(nil)
+	assert.ErrorIs(t, err, ErrNilInput)
+	assert.Equal(t, 0, BuildSuccinctTrie(nil).Size())
+
+	_, err = Build([]string{"a"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Build([]string{"a"}, WithKeyPattern(nil))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = Build([]string{"a"}, WithKeyValidator(nil))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	assert.Panics(t, func() { BuildSuccinctTrie([]string{"a"}, nil) })
+}
+
+func TestNodeByID(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a", "中文"})
+
+	ids := make(map[int32]string)
+	trie.Walk(func(key string, node Node) error {
+		ids[node.ID()] = key
+		return nil
+	})
+	assert.Len(t, ids, trie.Stats().Nodes)
+	for id, key := range ids {
+		n := trie.NodeByID(id)
+		assert.Equal(t, id, n.ID())
+		assert.Equal(t, key, n.Key())
+		assert.Equal(t, trie.Root().Search(key), n)
+	}
+	assert.Equal(t, int32(0), trie.Root().ID())
+
+	// stable across marshaling
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	var loaded SuccinctTrie
+	assert.NoError(t, loaded.Unmarshal(&buf))
+	for id, key := range ids {
+		assert.Equal(t, key, loaded.NodeByID(id).Key())
+	}
+
+	assert.False(t, trie.NodeByID(-1).Exists())
+	assert.False(t, trie.NodeByID(int32(len(ids))).Exists())
+	assert.Equal(t, int32(-1), trie.Root().Next('x').ID())
+	assert.True(t, BuildSuccinctTrie(nil).NodeByID(0).Exists())
+}
+
+func BenchmarkBuildRandom(b *testing.B) {
+	const l = 1000000
+	dict := make([]string, l)
+	for i := range dict {
+		dict[i] = randomString(10 + mrand.Intn(11))
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		BuildSuccinctTrie(dict)
+	}
+}
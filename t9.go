@@ -0,0 +1,78 @@
+package sutrie
+
+import "sort"
+
+// t9Letters are the letters of the keys 2 to 9 of a phone keypad.
+var t9Letters = [...]string{"abc", "def", "ghi", "jkl", "mno", "pqrs", "tuv", "wxyz"}
+
+// T9 returns the keys under the current node typed by digits on a phone keypad, in lexicographic order unless
+// changed by opts, for predictive text input and IVR menus. Every digit from '2' to '9' matches the letters of its key
+// in either case, '0' matches a space and every other byte matches itself, so the keys are as long as digits.
+// The returned keys are relative to the current node. Only the children matching the next digit are descended.
+func (n Node) T9(digits string, opts ...IterOption) (keys []string) {
+	n.T9Seq(digits, opts...)(func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
+
+// T9Seq is like T9 but yields the keys one at a time, see KeysSeq.
+func (n Node) T9Seq(digits string, opts ...IterOption) func(yield func(key string) bool) {
+	return func(yield func(key string) bool) {
+		if !n.Exists() {
+			return
+		}
+
+		s := t9Search{digits: digits, order: newIterOrder(opts)}
+		s.walk(n, func(key []byte) bool {
+			return yield(string(key))
+		})
+	}
+}
+
+type t9Search struct {
+	digits string
+	order  iterOrder
+	key    []byte
+}
+
+// letters returns the bytes matching digit in iteration order, in lower case only if the trie folds case.
+func (s *t9Search) letters(t *SuccinctTrie, digit byte) []byte {
+	var lits []byte
+	switch {
+	case digit >= '2' && digit <= '9':
+		lits = append(lits, t9Letters[digit-'2']...)
+		if t.fold == foldNone {
+			for _, c := range t9Letters[digit-'2'] {
+				lits = append(lits, byte(c)-'a'+'A')
+			}
+		}
+	case digit == '0':
+		lits = append(lits, ' ')
+	default:
+		lits = append(lits, digit)
+	}
+	sort.Slice(lits, func(i, j int) bool { return s.order.lessByte(lits[i], lits[j]) })
+	return lits
+}
+
+// walk calls emit with the keys typed from n until it returns false, in which case walk does too.
+func (s *t9Search) walk(n Node, emit func([]byte) bool) bool {
+	i := len(s.key)
+	if i == len(s.digits) {
+		return !n.Leaf() || emit(s.key)
+	}
+
+	for _, b := range s.letters(n.trie, s.digits[i]) {
+		if k := n.child(b); k != -1 {
+			s.key = append(s.key, n.trie.label(k))
+			ok := s.walk(n.next(k), emit)
+			s.key = s.key[:i]
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
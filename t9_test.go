@@ -0,0 +1,26 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestT9(t *testing.T) {
+	dict := []string{"good", "home", "gone", "hood", "hoof", "Hood", "inoe", "go", "good day", "go-1", "hello"}
+	root := BuildSuccinctTrie(dict).Root()
+
+	assert.Equal(t, []string{"Hood", "gone", "good", "home", "hood", "hoof", "inoe"}, root.T9("4663"))
+	assert.Equal(t, []string{"inoe", "hoof", "hood", "home", "good", "gone", "Hood"}, root.T9("4663", Reverse()))
+	assert.Equal(t, []string{"gone", "good", "Hood", "home", "hood"}, root.T9("4663", Collation(FoldCase))[:5])
+	assert.Equal(t, []string{"go"}, root.T9("46"))
+	assert.Equal(t, []string{"good day"}, root.T9("46630329"))
+	assert.Equal(t, []string{"go-1"}, root.T9("46-1"))
+	assert.Empty(t, root.T9("466"))
+	assert.Empty(t, root.T9("1"))
+	assert.Equal(t, []string{"me", "od", "of"}, root.Search("ho").T9("63"))
+	assert.Nil(t, root.Search("x").T9("2"))
+
+	folded := BuildSuccinctTrie(dict, WithCaseFolding()).Root()
+	assert.Equal(t, []string{"gone", "good", "home", "hood", "hoof", "inoe"}, folded.T9("4663"))
+}
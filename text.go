@@ -0,0 +1,122 @@
+package sutrie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// textChunk is the size of the chunks of keys read by BuildFromKeyReader which are built into tries then merged.
+var textChunk = 4 << 20
+
+// WriteKeys writes the keys of the trie to w, one per line, streaming them in the order of the trie,
+// which is lexicographic except for tries built WithReversedKeys, whose keys are ordered by their reversal.
+// Keys are written as stored, that is folded or normalized, and it fails on a key containing a newline.
+// The empty key is written as an empty line, which BuildFromKeyReader skips.
+func (t *SuccinctTrie) WriteKeys(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	err := t.Walk(func(key string, node Node) error {
+		if !node.leaf {
+			return nil
+		}
+		if strings.IndexByte(key, '\n') >= 0 {
+			return fmt.Errorf("sutrie: key %q contains a newline", key)
+		}
+		if t.reversed {
+			key = reverse(key)
+		}
+		bw.WriteString(key)
+		return bw.WriteByte('\n') // the errors of bw are sticky
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// BuildFromKeyReader builds a trie of the newline-delimited keys read from r, as written by WriteKeys.
+// Carriage returns ending lines are trimmed and blank lines skipped, the input needs not be sorted.
+// Keys are read in chunks, each built into a trie with opts, and the tries are merged as with Union,
+// so that beyond the trie only a chunk of keys is held in memory. Invalid keys are reported per chunk,
+// and WithSuffixTruncation and WithMultiplicities are not supported.
+func BuildFromKeyReader(r io.Reader, opts ...Option) (*SuccinctTrie, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		if opt == nil {
+			return nil, fmt.Errorf("%w: nil option", ErrInvalidOption)
+		}
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	if o.truncated {
+		return nil, fmt.Errorf("%w: BuildFromKeyReader does not support suffix truncation", ErrInvalidOption)
+	}
+	if o.multiset {
+		return nil, fmt.Errorf("%w: BuildFromKeyReader does not support multiplicities", ErrInvalidOption)
+	}
+
+	// tries holds tries of decreasing sizes, each one being merged with the next when it is not twice as large,
+	// so that every key is merged a logarithmic number of times
+	var tries []*SuccinctTrie
+	chunk := []string{}
+	size := 0
+	flush := func() error {
+		c, err := Build(chunk, opts...)
+		if err != nil {
+			return err
+		}
+		chunk, size = chunk[:0], 0
+
+		tries = append(tries, c)
+		for n := len(tries); n >= 2 && tries[n-2].numNodes() <= 2*tries[n-1].numNodes(); n-- {
+			merged, err := Union(tries[n-2:]...)
+			if err != nil {
+				return err
+			}
+			tries = append(tries[:n-2], merged)
+		}
+		return nil
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Text()
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+		if line == "" {
+			continue
+		}
+
+		chunk = append(chunk, line)
+		if size += len(line); size >= textChunk {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(chunk) > 0 || len(tries) == 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	if len(tries) == 1 {
+		return tries[0], nil
+	}
+
+	t, err := Union(tries...)
+	if err != nil {
+		return nil, err
+	}
+	t.fold, t.normalize = o.fold, o.normalize
+	if o.eliasFanoLeaves {
+		t.compressLeaves()
+	}
+	return t, nil
+}
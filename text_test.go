@@ -0,0 +1,65 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteKeys(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"it", "hat", "is", "a", "中文"})
+	var buf bytes.Buffer
+	assert.NoError(t, trie.WriteKeys(&buf))
+	assert.Equal(t, "a\nhat\nis\nit\n中文\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, BuildSuccinctTrie([]string{"a.com", "b.net", "c.com"}, WithReversedKeys()).WriteKeys(&buf))
+	assert.Equal(t, "a.com\nc.com\nb.net\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, BuildSuccinctTrie(nil).WriteKeys(&buf))
+	assert.Empty(t, buf.String())
+
+	assert.Error(t, BuildSuccinctTrie([]string{"a\nb"}).WriteKeys(&buf))
+}
+
+func TestBuildFromKeyReader(t *testing.T) {
+	trie, err := BuildFromKeyReader(strings.NewReader("it\r\nhat\n\nis\na\nit\n中文"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "hat", "is", "it", "中文"}, trie.Keys())
+
+	trie, err = BuildFromKeyReader(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, trie.Size())
+
+	_, err = BuildFromKeyReader(strings.NewReader("a"), WithSuffixTruncation(8))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	// small chunks, so that the tries of many chunks are merged
+	defer func(n int) { textChunk = n }(textChunk)
+	textChunk = 100
+
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, fmt.Sprintf("Key%d.example.com", i*7))
+	}
+	for _, opts := range [][]Option{
+		nil,
+		{WithReversedKeys(), WithEliasFanoLeaves()},
+		{WithCaseFolding()},
+	} {
+		want := BuildSuccinctTrie(append([]string(nil), dict...), opts...)
+
+		var buf bytes.Buffer
+		assert.NoError(t, want.WriteKeys(&buf))
+		trie, err := BuildFromKeyReader(&buf, opts...)
+		assert.NoError(t, err)
+		assert.True(t, want.Equal(trie))
+		assert.Equal(t, want.Reversed(), trie.Reversed())
+		assert.Equal(t, want.FoldsCase(), trie.FoldsCase())
+		assert.Equal(t, want.FoldsCase(), trie.lookup("key70.example.com").Leaf())
+	}
+}
@@ -0,0 +1,62 @@
+package sutrie
+
+import "unicode/utf8"
+
+// Token is a segment of the input of a Tokenizer, from byte offset Start to End.
+type Token struct {
+	Start, End int
+
+	// Known is true if the token is a key of the trie
+	Known bool
+}
+
+// Tokenizer segments text into keys of a trie by greedy longest match, as done for CJK word segmentation
+// and keyword extraction. Text matching no key falls back to one token per rune, or per byte for invalid UTF-8.
+type Tokenizer struct {
+	root Node
+
+	// MergeUnknown merges consecutive runes matching no key into a single token.
+	MergeUnknown bool
+}
+
+// NewTokenizer returns a tokenizer of the keys of t, as stored. The normalizer of t is not applied,
+// so that tokens are offsets in the input.
+func NewTokenizer(t *SuccinctTrie) *Tokenizer {
+	return &Tokenizer{root: t.Root()}
+}
+
+// Tokenize returns the tokens of s, which cover s.
+func (tk *Tokenizer) Tokenize(s string) []Token {
+	return tk.AppendTokens(nil, s)
+}
+
+// AppendTokens appends the tokens of s to dst and returns the extended slice.
+func (tk *Tokenizer) AppendTokens(dst []Token, s string) []Token {
+	for i := 0; i < len(s); {
+		if n := tk.root.SearchPrefix(s[i:]); n > 0 {
+			dst = append(dst, Token{i, i + n, true})
+			i += n
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if last := len(dst) - 1; tk.MergeUnknown && last >= 0 && !dst[last].Known && dst[last].End == i {
+			dst[last].End += size
+		} else {
+			dst = append(dst, Token{i, i + size, false})
+		}
+		i += size
+	}
+	return dst
+}
+
+// Words returns the substrings of s of the tokens matching a key.
+func (tk *Tokenizer) Words(s string) []string {
+	var words []string
+	for _, token := range tk.Tokenize(s) {
+		if token.Known {
+			words = append(words, s[token.Start:token.End])
+		}
+	}
+	return words
+}
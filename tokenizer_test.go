@@ -0,0 +1,27 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer(t *testing.T) {
+	tk := NewTokenizer(BuildSuccinctTrie([]string{"中国", "中国人", "人民", "民", "go", "golang"}))
+
+	text := "中国人民爱golang\xff"
+	var got []string
+	for _, token := range tk.Tokenize(text) {
+		got = append(got, text[token.Start:token.End])
+	}
+	assert.Equal(t, []string{"中国人", "民", "爱", "golang", "\xff"}, got)
+	assert.Equal(t, []string{"中国人", "民", "golang"}, tk.Words(text))
+
+	tokens := tk.Tokenize("xy中国")
+	assert.Equal(t, []Token{{0, 1, false}, {1, 2, false}, {2, 8, true}}, tokens)
+
+	tk.MergeUnknown = true
+	assert.Equal(t, []Token{{0, 2, false}, {2, 8, true}}, tk.Tokenize("xy中国"))
+	assert.Equal(t, []Token{{0, 1, false}}, tk.AppendTokens(nil, "x"))
+	assert.Empty(t, tk.Tokenize(""))
+}
@@ -0,0 +1,50 @@
+package sutrie
+
+import "unicode/utf8"
+
+// ShortestUniquePrefix returns the shortest prefix of key which is a prefix of no other key of the trie,
+// for example to expand abbreviated commands, and whether key is in the trie.
+// A key which is a prefix of other keys is returned whole, as only an exact match selects it.
+// The prefix is never empty and may end within a UTF-8 sequence, unless built WithUnicodeCaseFolding.
+// With WithReversedKeys, it is the shortest unique suffix, and with WithNormalizer, a prefix of the normalized key.
+func (t *SuccinctTrie) ShortestUniquePrefix(key string) (string, bool) {
+	if t.normalize != nil {
+		key = string(t.normalize([]byte(key)))
+	}
+	stored := key
+	if t.reversed {
+		stored = reverse(key)
+	}
+
+	// the prefix must go past the last node branching or ending another key, the keys below a node
+	// with a single child and no key of its own being the ones below the child
+	n := t.Root()
+	unique := 0
+	for i := 0; i < len(stored) && n.Exists(); {
+		branching := i == 0 || n.leaf || n.Size() > 1
+
+		size := 1
+		if stored[i] >= utf8.RuneSelf && t.fold == foldUnicode {
+			n, size = nextFolded(n, stored, i)
+		} else {
+			n = n.Next(stored[i])
+		}
+		i += size
+
+		if branching {
+			unique = i
+		}
+	}
+	if !n.leaf {
+		return "", false
+	}
+
+	length := len(key)
+	if n.Size() == 0 {
+		length = unique
+	}
+	if t.reversed {
+		return key[len(key)-length:], true
+	}
+	return key[:length], true
+}
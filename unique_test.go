@@ -0,0 +1,52 @@
+package sutrie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortestUniquePrefix(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"commit", "checkout", "cherry-pick", "clone", "co", "status", "中文", "中国"})
+
+	for key, want := range map[string]string{
+		"commit":      "com",
+		"checkout":    "chec",
+		"cherry-pick": "cher",
+		"clone":       "cl",
+		"co":          "co",
+		"status":      "s",
+		"中文":          "中\xe6",
+		"中国":          "中\xe5",
+	} {
+		prefix, ok := trie.ShortestUniquePrefix(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want, prefix, key)
+	}
+
+	for _, key := range []string{"", "c", "che", "commits", "push"} {
+		prefix, ok := trie.ShortestUniquePrefix(key)
+		assert.False(t, ok, key)
+		assert.Equal(t, "", prefix, key)
+	}
+
+	single := BuildSuccinctTrie([]string{"help"})
+	prefix, _ := single.ShortestUniquePrefix("help")
+	assert.Equal(t, "h", prefix)
+
+	reversed := BuildSuccinctTrie([]string{"a.example.com", "b.example.com", "example.org"}, WithReversedKeys())
+	prefix, ok := reversed.ShortestUniquePrefix("a.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "a.example.com", prefix)
+	prefix, _ = reversed.ShortestUniquePrefix("example.org")
+	assert.Equal(t, "g", prefix)
+
+	folded := BuildSuccinctTrie([]string{"Straße", "Strand", "ÄPFEL"}, WithUnicodeCaseFolding())
+	prefix, ok = folded.ShortestUniquePrefix("STRASSE")
+	assert.False(t, ok)
+	prefix, ok = folded.ShortestUniquePrefix("STRAßE")
+	assert.True(t, ok)
+	assert.Equal(t, "STRAß", prefix)
+	prefix, _ = folded.ShortestUniquePrefix("äpfel")
+	assert.Equal(t, "ä", prefix)
+}
@@ -0,0 +1,108 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrInvalidTrie is returned by Validate when a trie breaks an invariant, the errors it returns wrap it.
+var ErrInvalidTrie = errors.New("sutrie: invalid trie")
+
+// Validate checks the structure of the trie in a pass over its bitmaps: that the bitmap is the level-order unary
+// encoding of a tree of as many nodes as there are labels, the children of every node being sorted by label,
+// that the leaves are nodes and as many as the keys, and that every node without children is a leaf.
+// Unmarshal only checks that the sections of a trie are consistent, so servers loading untrusted tries
+// and fuzzers can call Validate to catch corruption before queries panic or answer wrongly.
+// It builds the indexes of an unmarshaled trie once it is found valid, and leaves those of an invalid one unbuilt,
+// which must then not be queried.
+func (t *SuccinctTrie) Validate() (err error) {
+	if t.lazy == nil {
+		return t.validate()
+	}
+	// validated under the Once building the indexes, which rewrites the bitmaps
+	first := false
+	t.lazy.Do(func() {
+		first = true
+		if err = t.validate(); err == nil {
+			t.buildIndexes()
+		}
+	})
+	if first {
+		return err
+	}
+	return t.validate()
+}
+
+func (t *SuccinctTrie) validate() error {
+	n := t.numNodes()
+	if n == 0 {
+		return fmt.Errorf("%w: no root", ErrInvalidTrie)
+	}
+	if t.bitmap.Len() < 2*n+1 {
+		return fmt.Errorf("%w: bitmap of %d bits for %d nodes", ErrInvalidTrie, t.bitmap.Len(), n)
+	}
+	if t.bitmap.Get(0) {
+		return fmt.Errorf("%w: bitmap does not start with the root", ErrInvalidTrie)
+	}
+	if t.runs.Len() > 0 && (t.runs.Len() < n || t.runs.Get(0) || t.runs.Rank1(n) != n-len(t.nodes)) {
+		return fmt.Errorf("%w: label runs do not match the %d nodes", ErrInvalidTrie, n)
+	}
+
+	// the ith one starts the children of node i-1, every zero is the next node, a child of the last node started
+	ones, zeros, children := 0, 1, 0
+	for i := 1; i < 2*n+1; i++ {
+		if t.bitmap.Get(i) {
+			if ones > 1 && children == 0 && !t.isLeaf(int32(ones-1)) {
+				return fmt.Errorf("%w: node %d has no children and is not a leaf", ErrInvalidTrie, ones-1)
+			}
+			ones, children = ones+1, 0
+			continue
+		}
+		if ones == 0 || ones-1 >= zeros {
+			return fmt.Errorf("%w: node %d is not after its parent", ErrInvalidTrie, zeros)
+		}
+		if zeros >= n {
+			return fmt.Errorf("%w: bitmap has more nodes than the %d labels", ErrInvalidTrie, n)
+		}
+		if children == 0 && t.runs.Get(zeros) {
+			return fmt.Errorf("%w: run of node %d starts at a dropped label", ErrInvalidTrie, ones-1)
+		}
+		if children > 0 && t.label(int32(zeros)) <= t.label(int32(zeros-1)) {
+			return fmt.Errorf("%w: children of node %d not sorted by label", ErrInvalidTrie, ones-1)
+		}
+		zeros, children = zeros+1, children+1
+	}
+	if zeros != n {
+		return fmt.Errorf("%w: bitmap has %d nodes, labels %d", ErrInvalidTrie, zeros, n)
+	}
+	for i := 2*n + 1; i < t.bitmap.Len(); i++ {
+		if t.bitmap.Get(i) {
+			return fmt.Errorf("%w: bitmap has bits past its end", ErrInvalidTrie)
+		}
+	}
+
+	leaves, total := 0, 0
+	for k := 0; k < n; k++ {
+		if t.isLeaf(int32(k)) {
+			leaves++
+		}
+	}
+	if t.sparseLeaves != nil {
+		total = t.sparseLeaves.Ones()
+	} else {
+		for i := 0; i < (t.leaves.Len()+63)>>6; i++ {
+			total += bits.OnesCount64(t.leaves.Word(i))
+		}
+	}
+	if total != leaves {
+		return fmt.Errorf("%w: %d leaves past the %d nodes", ErrInvalidTrie, total-leaves, n)
+	}
+	if leaves != t.size {
+		return fmt.Errorf("%w: %d leaves for %d keys", ErrInvalidTrie, leaves, t.size)
+	}
+	if t.truncated && len(t.suffixes)*64 < t.size*t.hashBits {
+		return fmt.Errorf("%w: suffixes shorter than the keys", ErrInvalidTrie)
+	}
+	return nil
+}
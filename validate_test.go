@@ -0,0 +1,61 @@
+package sutrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	dict := append(domainKeys(2000), "", "a", "ab", "B")
+	for name, opts := range map[string][]Option{
+		"plain":      nil,
+		"reversed":   {WithReversedKeys()},
+		"eliasFano":  {WithEliasFanoLeaves()},
+		"packed":     {WithAlphabetRemap(), WithPackedLabels()},
+		"dispatch":   {WithDispatchTable(2)},
+		"truncated":  {WithSuffixTruncation(8)},
+		"multiset":   {WithMultiplicities()},
+		"caseFolded": {WithCaseFolding()},
+		"labelRuns":  {WithLabelRuns()},
+	} {
+		trie, err := Build(append([]string(nil), dict...), opts...)
+		assert.NoError(t, err, name)
+		assert.NoError(t, trie.Validate(), name)
+
+		var buf bytes.Buffer
+		assert.NoError(t, trie.Marshal(&buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		assert.NoError(t, loaded.Validate(), name)
+		assert.NotZero(t, loaded.bitmap.IndexBytes(), name)
+	}
+	assert.NoError(t, BuildSuccinctTrie([]string{}).Validate())
+	assert.NoError(t, BuildSuccinctTrie([]string{""}).Validate())
+	assert.Error(t, new(SuccinctTrie).Validate())
+
+	var buf bytes.Buffer
+	assert.NoError(t, BuildSuccinctTrie([]string{"ab", "ac", "b"}).Marshal(&buf))
+	data := buf.Bytes()
+	// nodes 0 root, 1 a, 2 b, 3 ab, 4 ac: bitmap 0 100 100 1 1 1 1
+	for name, corrupt := range map[string]func(c *SuccinctTrie){
+		"root":       func(c *SuccinctTrie) { c.bitmap.Set(0, true) },
+		"parent":     func(c *SuccinctTrie) { c.bitmap.Set(1, false) },
+		"extra node": func(c *SuccinctTrie) { c.bitmap.Set(10, false) },
+		"past end":   func(c *SuccinctTrie) { c.bitmap.Set(12, true) },
+		"labels":     func(c *SuccinctTrie) { c.nodes = "\x00ba" + c.nodes[3:] },
+		"no labels":  func(c *SuccinctTrie) { c.nodes = c.nodes[:4] },
+		"dead end":   func(c *SuccinctTrie) { c.leaves.Set(4, false) },
+		"leaves":     func(c *SuccinctTrie) { c.leaves.Set(1, true) },
+		"past nodes": func(c *SuccinctTrie) { c.leaves.Set(7, true) },
+		"size":       func(c *SuccinctTrie) { c.size++ },
+	} {
+		var c SuccinctTrie
+		assert.NoError(t, c.Unmarshal(bytes.NewReader(data)))
+		assert.NoError(t, c.Validate())
+		corrupt(&c)
+		err := c.Validate()
+		assert.ErrorIs(t, err, ErrInvalidTrie, name)
+	}
+}
@@ -0,0 +1,77 @@
+package sutrie
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errInvalidValues = errors.New("sutrie: invalid values")
+
+// WithUint8Values maps dict[i] to vals[i], a small integer like a rule category or an action code, returned by
+// Node.Value. The values are packed in the bits of the largest one by leaf index, 2 bits per key for 4 actions.
+// Build fails if vals is not as long as dict, if a key is given twice with different values, or with
+// WithSuffixTruncation, which loses the keys the values belong to. The values of dropped keys are dropped.
+func WithUint8Values(vals []uint8) Option {
+	return func(o *buildOptions) error {
+		if vals == nil {
+			vals = []uint8{} // no keys then
+		}
+		o.values = vals
+		return nil
+	}
+}
+
+// lookup returns the node of key as stored by Build, that is normalized, folded and reversed.
+func (t *SuccinctTrie) lookup(key string) Node {
+	if t.rewritesQueries() {
+		q, buf := query(t, key)
+		defer normBuffers.Put(buf)
+		return search(t.Root(), q)
+	}
+	return t.Root().Search(key)
+}
+
+// setValues stores vals[i] as the value of the leaf of dict[i].
+func (t *SuccinctTrie) setValues(dict []string, vals []uint8) error {
+	values, err := t.leafValues(dict, func(i int) uint64 { return uint64(vals[i]) })
+	if err != nil {
+		return err
+	}
+	t.values, t.hasValues = newPackedInts(values), true
+	return nil
+}
+
+// leafValues returns the values of the leaves by leaf index, value(i) being that of dict[i].
+// It fails if a key is given twice with different values.
+func (t *SuccinctTrie) leafValues(dict []string, value func(i int) uint64) ([]uint64, error) {
+	values := make([]uint64, t.size)
+	set := make([]bool, t.size)
+	for i, key := range dict {
+		leaf := t.lookup(key).LeafIndex()
+		if leaf < 0 {
+			continue // dropped by a validator
+		}
+		v := value(i)
+		if set[leaf] && values[leaf] != v {
+			return nil, fmt.Errorf("sutrie: key %q is given twice with different values", key)
+		}
+		values[leaf], set[leaf] = v, true
+	}
+	return values, nil
+}
+
+// Value returns the value of the key of the current node, see WithUint8Values.
+// ok is false if the node is not a leaf or the trie has no values.
+func (n Node) Value() (value uint8, ok bool) {
+	if !n.leaf || !n.trie.hasValues {
+		return 0, false
+	}
+	return uint8(n.trie.values.get(n.trie.leavesBefore(n.index))), true
+}
+
+// Lookup returns the value of key and whether key is in the trie with a value, see WithUint8Values.
+// Like Contains, it is short for t.Root().Search(key).Value() with key normalized and reversed as the keys of the build,
+// and does not allocate.
+func (t *SuccinctTrie) Lookup(key string) (value uint8, ok bool) {
+	return t.lookup(key).Value()
+}
@@ -0,0 +1,139 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	mrand "math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUint8Values(t *testing.T) {
+	dict := []string{"ads.example.com", "cdn.example.com", "example.com", "tracker.net", "example.com"}
+	vals := []uint8{2, 1, 0, 3, 0}
+	trie, err := Build(append([]string(nil), dict...), WithUint8Values(vals), WithReversedKeys())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, trie.Size())
+	assert.Equal(t, 8, trie.MemStats().Values)
+
+	check := func(trie *SuccinctTrie) {
+		for i, key := range dict {
+			v, ok := trie.lookup(key).Value()
+			assert.True(t, ok, key)
+			assert.Equal(t, vals[i], v, key)
+			// the queries reverse keys like lookup
+			v, ok = trie.Lookup(key)
+			assert.True(t, ok, key)
+			assert.Equal(t, vals[i], v, key)
+			assert.True(t, trie.Contains(key), key)
+			assert.Equal(t, 1, trie.Count(key), key)
+			assert.Equal(t, len(key), trie.SearchPrefix("www."+key), key)
+		}
+		_, ok := trie.Lookup(reverse(dict[0]))
+		assert.False(t, ok)
+		assert.False(t, trie.Contains(reverse(dict[0])))
+		assert.True(t, trie.HasPrefix("example.com"))
+		_, ok = trie.lookup("example.org").Value()
+		assert.False(t, ok)
+		_, ok = trie.lookup("example.com").Parent().Value()
+		assert.False(t, ok)
+	}
+	check(trie)
+	check(trie.Clone())
+	for _, marshal := range []func(*SuccinctTrie, *bytes.Buffer) error{
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.Marshal(buf) },
+		func(t *SuccinctTrie, buf *bytes.Buffer) error { return t.MarshalSections(buf) },
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, marshal(trie, &buf))
+		var loaded SuccinctTrie
+		assert.NoError(t, loaded.Unmarshal(&buf))
+		check(&loaded)
+	}
+
+	reversedFolded := BuildSuccinctTrie([]string{"été.fr"}, WithReversedKeys(), WithUnicodeCaseFolding())
+	assert.True(t, reversedFolded.Contains("ÉTÉ.FR"))
+	assert.Equal(t, len("ÉTÉ.FR"), reversedFolded.SearchPrefix("ÉTÉ.FR"))
+
+	// zero values still tell keys with values apart
+	zeros := BuildSuccinctTrie([]string{"a", "b"}, WithUint8Values([]uint8{0, 0}))
+	assert.Equal(t, 0, zeros.MemStats().Values)
+	v, ok := zeros.Root().Search("b").Value()
+	assert.True(t, ok)
+	assert.Equal(t, uint8(0), v)
+	_, ok = BuildSuccinctTrie([]string{"a"}).Root().Search("a").Value()
+	assert.False(t, ok)
+
+	// keys are matched to their values as stored
+	folded := BuildSuccinctTrie([]string{"Hat", "it"}, WithUint8Values([]uint8{7, 9}), WithCaseFolding(),
+		WithNormalizer(func(key []byte) []byte { return bytes.TrimSuffix(key, []byte(".")) }))
+	v, _ = folded.Root().Search("hat").Value()
+	assert.Equal(t, uint8(7), v)
+	dropped := BuildSuccinctTrie([]string{"a", "B", "c"}, WithUint8Values([]uint8{1, 2, 3}),
+		WithKeyPattern(regexp.MustCompile("^[a-z]+$")), DropInvalidKeys(nil))
+	v, _ = dropped.Root().Search("c").Value()
+	assert.Equal(t, uint8(3), v)
+
+	_, err = Build([]string{"a", "a"}, WithUint8Values([]uint8{1, 2}))
+	assert.Error(t, err)
+	_, err = Build([]string{"a", "b"}, WithUint8Values([]uint8{1}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, err = Build([]string{"a"}, WithUint8Values([]uint8{1}), WithSuffixTruncation(8))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, err = Build([]string{}, WithUint8Values(nil))
+	assert.NoError(t, err)
+}
+
+func TestLookup(t *testing.T) {
+	dict := []string{"ads.example.com", "cdn.example.com", "tracker.net"}
+	trimDot := func(key []byte) []byte { return bytes.TrimSuffix(key, []byte(".")) }
+	for _, opts := range [][]Option{nil, {WithNormalizer(trimDot)}} {
+		trie := BuildSuccinctTrie(append([]string(nil), dict...), append(opts, WithUint8Values([]uint8{2, 1, 3}))...)
+		v, ok := trie.Lookup("cdn.example.com")
+		assert.True(t, ok)
+		assert.Equal(t, uint8(1), v)
+		_, ok = trie.Lookup("example.com")
+		assert.False(t, ok)
+
+		key := "tracker.net"
+		if !raceEnabled {
+			assert.Zero(t, testing.AllocsPerRun(100, func() {
+				trie.Contains(key)
+				trie.Lookup(key)
+			}))
+		}
+	}
+
+	trie := BuildSuccinctTrie([]string{"a"}, WithUint8Values([]uint8{5}), WithNormalizer(bytes.ToLower))
+	v, ok := trie.Lookup("A")
+	assert.True(t, ok)
+	assert.Equal(t, uint8(5), v)
+	v, ok = BuildSuccinctTrie([]string{"a"}).Lookup("a")
+	assert.False(t, ok)
+	assert.Zero(t, v)
+}
+
+func BenchmarkLookup(b *testing.B) {
+	const l = 1000000
+	dict := make([]string, l)
+	vals := make([]uint8, l)
+	m := make(map[string]uint8, l)
+	for i := range dict {
+		dict[i], vals[i] = randomString(10+mrand.Intn(11)), uint8(i&3)
+		m[dict[i]] = vals[i]
+	}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithUint8Values(vals))
+
+	b.Run(fmt.Sprint("sutrie-", trie.SizeInBytes()>>20, "MB"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			trie.Lookup(dict[i%l])
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = m[dict[i%l]]
+		}
+	})
+}
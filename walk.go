@@ -0,0 +1,57 @@
+package sutrie
+
+import "errors"
+
+// SkipSubtree is used as a return value from WalkFunc to indicate that
+// the children of the node in the call are to be skipped.
+var SkipSubtree = errors.New("skip this subtree")
+
+// SkipAll is used as a return value from WalkFunc to indicate that
+// all remaining nodes are to be skipped.
+var SkipAll = errors.New("skip everything and stop the walk")
+
+// WalkFunc is the type of the function called by Walk to visit each node.
+// The key argument is the path from the node Walk started at to the visited node.
+// If the function returns SkipSubtree, Walk skips the children of the node,
+// if it returns SkipAll, Walk stops and returns nil, any other non-nil error stops Walk and is returned by it.
+type WalkFunc func(key string, node Node) error
+
+// Walk walks the subtree rooted at the current node in lexicographic order, calling fn for each node,
+// including the current node itself. A node is always visited before its descendants,
+// opts only change the order of siblings.
+func (n Node) Walk(fn WalkFunc, opts ...IterOption) error {
+	if !n.Exists() {
+		return nil
+	}
+
+	o := newIterOrder(opts)
+	var key []byte
+	err := walk(n, &key, fn, &o)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+// Walk walks the whole trie from root, see Node.Walk.
+func (t *SuccinctTrie) Walk(fn WalkFunc, opts ...IterOption) error {
+	return t.Root().Walk(fn, opts...)
+}
+
+func walk(n Node, key *[]byte, fn WalkFunc, o *iterOrder) error {
+	if err := fn(string(*key), n); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+		return err
+	}
+
+	var err error
+	o.children(n, func(k int32) bool {
+		*key = append(*key, n.trie.label(k))
+		err = walk(n.next(k), key, fn, o)
+		*key = (*key)[:len(*key)-1]
+		return err == nil
+	})
+	return err
+}
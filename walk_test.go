@@ -0,0 +1,56 @@
+package sutrie
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	trie := BuildSuccinctTrie([]string{"hat", "is", "it", "a"})
+
+	var keys, leaves []string
+	err := trie.Walk(func(key string, node Node) error {
+		keys = append(keys, key)
+		if node.Leaf() {
+			leaves = append(leaves, key)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a", "h", "ha", "hat", "i", "is", "it"}, keys)
+	assert.Equal(t, []string{"a", "hat", "is", "it"}, leaves)
+
+	keys = nil
+	err = trie.Walk(func(key string, node Node) error {
+		keys = append(keys, key)
+		if key == "h" {
+			return SkipSubtree
+		}
+		if key == "is" {
+			return SkipAll
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a", "h", "i", "is"}, keys)
+
+	stop := errors.New("stop")
+	err = trie.Walk(func(key string, node Node) error {
+		if strings.HasPrefix(key, "i") {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+
+	keys = nil
+	err = trie.Root().Search("i").Walk(func(key string, node Node) error {
+		keys = append(keys, key)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "s", "t"}, keys)
+}
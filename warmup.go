@@ -0,0 +1,18 @@
+package sutrie
+
+// Warmup builds the rank and select indexes of a trie read by Unmarshal, which are otherwise built by
+// the first query needing them, so that latency-sensitive servers do not make it pay for it.
+// Every method reading the bitmaps builds them first, as they are interleaved with the bitmaps in place,
+// Size and the flags of the trie excepted. It is safe for concurrent use, and does nothing if the indexes are built.
+// Once it returns, the trie holds no state left to finalize and queries only read it.
+func (t *SuccinctTrie) Warmup() {
+	if t.lazy != nil {
+		t.lazy.Do(t.buildIndexes)
+	}
+}
+
+func (t *SuccinctTrie) buildIndexes() {
+	t.bitmap.Init()
+	t.leaves.Init()
+	t.initLayout()
+}
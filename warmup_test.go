@@ -0,0 +1,137 @@
+package sutrie
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyIndexes(t *testing.T) {
+	var dict []string
+	for i := 0; i < 5000; i++ {
+		dict = append(dict, fmt.Sprintf("%x", i*31))
+	}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...))
+
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	data := buf.Bytes()
+
+	load := func() *SuccinctTrie {
+		loaded := new(SuccinctTrie)
+		assert.NoError(t, loaded.Unmarshal(bytes.NewReader(data)))
+		return loaded
+	}
+
+	// the size does not build the indexes
+	loaded := load()
+	assert.Equal(t, trie.Size(), loaded.Size())
+	assert.Zero(t, loaded.bitmap.IndexBytes())
+
+	// the first query does, as does reading the bitmaps
+	assert.True(t, loaded.Contains(dict[0]))
+	assert.NotZero(t, loaded.bitmap.IndexBytes())
+	assert.Equal(t, trie.MemStats(), loaded.MemStats())
+	assert.True(t, trie.Equal(loaded))
+	loaded = load()
+	assert.Equal(t, trie.Stats(), loaded.Stats())
+	assert.Equal(t, trie.MemStats(), loaded.MemStats())
+
+	loaded = load()
+	loaded.Warmup()
+	assert.Equal(t, trie.MemStats(), loaded.MemStats())
+	loaded.Warmup()
+	assert.Equal(t, dict[1], loaded.KeyAt(loaded.Root().Search(dict[1]).LeafIndex()))
+
+	loaded = load()
+	assert.Equal(t, trie.Keys(), loaded.Clone().Keys())
+
+	// concurrent first queries, run with -race
+	loaded = load()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < len(dict); i += 8 {
+				assert.True(t, loaded.Contains(dict[i]))
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestConcurrentQueries runs the queries on a warmed up trie from many goroutines, run with -race.
+func TestConcurrentQueries(t *testing.T) {
+	dict := domainKeys(5000)
+	vals := make([]uint8, len(dict))
+	for i := range vals {
+		vals[i] = uint8(len(dict[i]))
+	}
+	trie := BuildSuccinctTrie(append([]string(nil), dict...), WithUint8Values(vals))
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	loaded := new(SuccinctTrie)
+	assert.NoError(t, loaded.Unmarshal(&buf))
+	loaded.Warmup()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < len(dict); i += 8 {
+				key := dict[i]
+				assert.True(t, loaded.Contains(key))
+				assert.Equal(t, len(key), loaded.SearchPrefix(key+"/x"))
+				assert.True(t, loaded.HasPrefix(key[:len(key)/2]))
+				_, ok := loaded.ShortestPrefix(key)
+				assert.True(t, ok)
+				assert.Equal(t, key, loaded.KeyAt(loaded.Root().Search(key).LeafIndex()))
+				v, ok := loaded.Lookup(key)
+				assert.True(t, ok)
+				assert.Equal(t, vals[i], v)
+				floor, _ := loaded.Floor(key)
+				assert.Equal(t, key, floor)
+			}
+			it := loaded.Iterator()
+			n := 0
+			for _, ok := it.Next(); ok; _, ok = it.Next() {
+				n++
+			}
+			assert.Equal(t, loaded.Size(), n)
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestConcurrentStats reads the bitmaps of an unmarshaled trie while the first queries build its indexes,
+// run with -race.
+func TestConcurrentStats(t *testing.T) {
+	trie := BuildSuccinctTrie(domainKeys(5000))
+	var buf bytes.Buffer
+	assert.NoError(t, trie.Marshal(&buf))
+	data := buf.Bytes()
+
+	for _, read := range []func(loaded *SuccinctTrie){
+		func(loaded *SuccinctTrie) { assert.Equal(t, trie.Stats(), loaded.Stats()) },
+		func(loaded *SuccinctTrie) { assert.Equal(t, trie.MemStats(), loaded.MemStats()) },
+		func(loaded *SuccinctTrie) { assert.Equal(t, trie.Hash(), loaded.Hash()) },
+		func(loaded *SuccinctTrie) { assert.True(t, trie.Equal(loaded)) },
+		func(loaded *SuccinctTrie) { assert.NoError(t, loaded.Validate()) },
+		func(loaded *SuccinctTrie) { assert.NoError(t, loaded.MarshalSections(io.Discard)) },
+	} {
+		loaded := new(SuccinctTrie)
+		assert.NoError(t, loaded.Unmarshal(bytes.NewReader(data)))
+		done := make(chan bool)
+		go func() {
+			done <- loaded.Contains("example.com")
+		}()
+		read(loaded)
+		assert.Equal(t, trie.Contains("example.com"), <-done)
+	}
+}
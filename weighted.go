@@ -0,0 +1,166 @@
+package sutrie
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+)
+
+// WeightedKey is a key with its weight.
+type WeightedKey struct {
+	Key    string
+	Weight float64
+}
+
+// WeightedTrie is a trie whose keys have a weight, which finds the keys of highest weight under a prefix
+// without enumerating them all. Every node stores the largest weight under it, as the rank of the weight
+// among the distinct weights, packed in as few bits as they need.
+type WeightedTrie struct {
+	trie    *SuccinctTrie
+	weights []float64
+
+	// best packs the rank in distinct of the largest weight of the subtree of every node, in level order
+	best     packedInts
+	distinct []float64
+}
+
+// BuildWeighted constructs a WeightedTrie of dict, weights[i] being the weight of dict[i], with opts like Build.
+// A key given more than once gets its largest weight.
+func BuildWeighted(dict []string, weights []float64, opts ...Option) (*WeightedTrie, error) {
+	if len(dict) != len(weights) {
+		return nil, errors.New("sutrie: number of weights does not match number of keys")
+	}
+
+	t, err := Build(copyKeys(dict), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	leafWeights := make([]float64, t.size)
+	set := make([]bool, t.size)
+	for i, key := range dict {
+		leaf := t.lookup(key).LeafIndex()
+		if leaf < 0 {
+			continue // dropped by a validator
+		}
+		if !set[leaf] || weights[i] > leafWeights[leaf] {
+			leafWeights[leaf], set[leaf] = weights[i], true
+		}
+	}
+	return NewWeighted(t, leafWeights), nil
+}
+
+// NewWeighted returns a WeightedTrie of the keys of t, weights[i] being the weight of the key whose LeafIndex is i.
+// It panics if len(weights) is not the size of the trie.
+func NewWeighted(t *SuccinctTrie, weights []float64) *WeightedTrie {
+	if len(weights) != t.Size() {
+		panic("sutrie: number of weights does not match number of keys")
+	}
+
+	distinct := append([]float64(nil), weights...)
+	sort.Float64s(distinct)
+	j := 0
+	for i := range distinct {
+		if i == 0 || distinct[i] != distinct[j-1] {
+			distinct[j] = distinct[i]
+			j++
+		}
+	}
+	distinct = distinct[:j:j]
+
+	// the child ranges in level order, so that the subtrees are summed up bottom-up
+	var ranges [][2]int32
+	t.forEachNode(func(firstChild, afterLastChild int32) {
+		ranges = append(ranges, [2]int32{firstChild, afterLastChild})
+	})
+
+	best := make([]uint64, len(ranges))
+	for i := len(ranges) - 1; i >= 0; i-- {
+		var b uint64
+		if t.isLeaf(int32(i)) {
+			b = uint64(sort.SearchFloat64s(distinct, weights[t.leavesBefore(int32(i))])) + 1
+		}
+		for k := ranges[i][0]; k < ranges[i][1]; k++ {
+			b = max(b, best[k])
+		}
+		best[i] = b
+	}
+
+	// ranks are stored plus one, 0 is an empty subtree
+	return &WeightedTrie{trie: t, weights: weights, best: newPackedInts(best), distinct: distinct}
+}
+
+// Trie returns the underlying trie.
+func (w *WeightedTrie) Trie() *SuccinctTrie {
+	return w.trie
+}
+
+// Weight returns the weight of the key whose LeafIndex is leaf.
+func (w *WeightedTrie) Weight(leaf int) float64 {
+	return w.weights[leaf]
+}
+
+// topEntry is a node to expand, or the key of a leaf to yield if self is set, with its key and best weight.
+type topEntry struct {
+	node Node
+	key  string
+	best uint64
+	self bool
+}
+
+type topHeap []topEntry
+
+func (h topHeap) Len() int { return len(h) }
+
+// Less orders by descending weight then in lexicographic order, a node being before its subtree,
+// which yields the keys of the same weight in lexicographic order.
+func (h topHeap) Less(i, j int) bool {
+	if h[i].best != h[j].best {
+		return h[i].best > h[j].best
+	}
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].self
+}
+func (h topHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *topHeap) Push(x any)   { *h = append(*h, x.(topEntry)) }
+func (h *topHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopK returns the k keys of highest weight starting with prefix, by descending weight then in lexicographic order.
+// The subtrees are expanded best first, so only the nodes on the way to the returned keys and their siblings
+// are visited. Keys are as stored, see WithReversedKeys.
+func (w *WeightedTrie) TopK(prefix string, k int) []WeightedKey {
+	n := w.trie.Root().Search(prefix)
+	if !n.Exists() || k <= 0 {
+		return nil
+	}
+
+	var top []WeightedKey
+	h := topHeap{{node: n, key: n.Key(), best: w.best.get(int(n.index))}}
+	for h.Len() > 0 && len(top) < k {
+		e := heap.Pop(&h).(topEntry)
+		if e.best == 0 {
+			break
+		}
+		if e.self {
+			top = append(top, WeightedKey{e.key, w.distinct[e.best-1]})
+			continue
+		}
+
+		if e.node.leaf {
+			rank := uint64(sort.SearchFloat64s(w.distinct, w.weights[e.node.LeafIndex()])) + 1
+			heap.Push(&h, topEntry{node: e.node, key: e.key, best: rank, self: true})
+		}
+		for c := e.node.firstChild; c < e.node.afterLastChild; c++ {
+			key := e.key + w.trie.labels(c, c+1)
+			heap.Push(&h, topEntry{node: e.node.next(c), key: key, best: w.best.get(int(c))})
+		}
+	}
+	return top
+}
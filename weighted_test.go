@@ -0,0 +1,68 @@
+package sutrie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopK(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var dict []string
+	var weights []float64
+	for i := 0; i < 20000; i++ {
+		dict = append(dict, fmt.Sprintf("q%x", rnd.Intn(1<<16)))
+		weights = append(weights, float64(rnd.Intn(1000)))
+	}
+
+	w, err := BuildWeighted(dict, weights)
+	assert.NoError(t, err)
+
+	best := make(map[string]float64)
+	for i, key := range dict {
+		if wt, ok := best[key]; !ok || weights[i] > wt {
+			best[key] = weights[i]
+		}
+	}
+
+	for _, prefix := range []string{"", "q", "qa", "q1f", "qffff", "x"} {
+		var want []WeightedKey
+		for key, wt := range best {
+			if strings.HasPrefix(key, prefix) {
+				want = append(want, WeightedKey{key, wt})
+			}
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].Weight > want[j].Weight })
+
+		for _, k := range []int{1, 10, 100} {
+			got := w.TopK(prefix, k)
+			assert.Len(t, got, min(k, len(want)), prefix)
+			for i := range got {
+				assert.Equal(t, want[i].Weight, got[i].Weight, prefix)
+				assert.Equal(t, best[got[i].Key], got[i].Weight, prefix)
+				assert.True(t, strings.HasPrefix(got[i].Key, prefix))
+			}
+		}
+	}
+
+	small, err := BuildWeighted([]string{"a", "ab", "abc", "b"}, []float64{1, 3, 2, -1})
+	assert.NoError(t, err)
+	assert.Equal(t, []WeightedKey{{"ab", 3}, {"abc", 2}, {"a", 1}, {"b", -1}}, small.TopK("", 10))
+	assert.Equal(t, []WeightedKey{{"ab", 3}, {"abc", 2}}, small.TopK("ab", 10))
+	assert.Empty(t, small.TopK("a", 0))
+	assert.Equal(t, 3.0, small.Weight(small.Trie().Root().Search("ab").LeafIndex()))
+
+	ties, _ := BuildWeighted([]string{"b", "ab", "a", "\xff", "c"}, []float64{1, 1, 1, 1, 0})
+	assert.Equal(t, []WeightedKey{{"a", 1}, {"ab", 1}, {"b", 1}, {"\xff", 1}, {"c", 0}}, ties.TopK("", 5))
+
+	empty, err := BuildWeighted([]string{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, empty.TopK("", 3))
+
+	_, err = BuildWeighted([]string{"a"}, nil)
+	assert.Error(t, err)
+}